@@ -1,60 +1,235 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"github.com/gtforge/global_services_common_go/gett-mq"
 	"github.com/gtforge/global_services_common_go/gett-mq/consumer"
 	"github.com/gtforge/global_services_common_go/gett-mq/publisher"
 	"github.com/gtforge/global_services_common_go/gett-workers"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
 	"github.com/gtforge/go-skeleton-draft/structure/pkg/events"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/flags"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/lifecycle"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ratelimit"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/webhooks"
 	"github.com/gtforge/go-skeleton-draft/structure/pkg/workers"
+	"net"
 	"os"
+	"time"
 
 	"github.com/gtforge/global_services_common_go/gett-config"
 	"github.com/gtforge/global_services_common_go/gett-ops"
 	"github.com/gtforge/global_services_common_go/gett-storages"
-	"github.com/gtforge/go-skeleton-draft/core"
 	"github.com/gtforge/gorm"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	storagePostgres = "postgres"
+	storageMemory   = "memory"
+
+	// localWorkerInterval - how often the worker runs with the memory storage, the jobs manager and its cron need redis
+	localWorkerInterval = time.Minute
+)
+
+var errHTTPStopped = errors.New("the http server stopped")
+
+var storage = flag.String("storage", storagePostgres,
+	"postgres runs on postgres, redis and rabbitmq, memory keeps everything in the process and consumes no events")
+
+// Deps - the connections of the service and the storages built on them, the connections are nil with the memory storage
 type Deps struct {
 	DB       *gorm.DB
 	Redis    *gettStorages.GtRedisClient
 	RabbitMQ *gettMQ.AMQPConnection
+
+	Persons     person.Backends
+	Webhooks    webhooks.Repository
+	Idempotency idempotency.Store
+	RateLimit   ratelimit.Bucket
+	Flags       flags.Store
 }
 
 // initServices - initialize required infra packages (global_services_common_go)
-func initServices(config gettConfig.AppConfig) Deps {
-	gettStorages.InitDb(config.Db, config.AppEnv)
-	gettStorages.InitRedis(config.Redis, config.AppEnv)
+func initServices(appConfig gettConfig.AppConfig) Deps {
+	gettStorages.InitDb(appConfig.Db, appConfig.AppEnv)
+	gettStorages.InitRedis(appConfig.Redis, appConfig.AppEnv)
 	consumer.InitMqConumer()
-	return Deps{
+	deps := Deps{
 		DB:       gettStorages.DB,
 		Redis:    gettStorages.RedisClient,
 		RabbitMQ: publisher.InitMqPublisher(),
+		Webhooks: webhooks.NewRepo(gettStorages.DB),
+	}
+	deps.Idempotency = idempotency.NewRedisStore(deps.Redis)
+	deps.RateLimit = ratelimit.NewRedisBucket(deps.Redis)
+	deps.Flags = flags.NewRedisStore(deps.Redis)
+
+	return deps
+}
+
+// initMemoryServices - the storages of a local run without any infra, nothing outlives the process
+func initMemoryServices() Deps {
+	return Deps{
+		Webhooks:    webhooks.NewMemoryRepository(),
+		Idempotency: idempotency.NewMemoryStore(),
+		RateLimit:   ratelimit.NewMemoryBucket(),
+		Flags:       flags.NewMemoryStore(),
 	}
 }
 
+// memory - whether the service runs on the memory storage
+func (d Deps) memory() bool {
+	return d.DB == nil
+}
+
 func main() {
+	flag.Parse()
+
 	// Initializing router and logger to be used by App instance
 	logger := createLogger()
-	config := gettConfig.GetConfig()
+	appConfig := gettConfig.GetConfig()
+
+	// Loading and validating the person settings before anything reads them
+	config.InitFromConfig()
+	applyLogLevel(logger, config.Current())
+	config.OnReload(func(c config.Config) { applyLogLevel(logger, c) })
 
 	// Initializing required infra dependencies
-	deps := initServices(config)
-	releaseAllJobs()
-	pingers := healthCheckPingers(deps.DB.DB())
-	router := createRouter()
-	app := skeleton.NewApp(config, router, logger, pingers)
-	gettWorkers.InitJobsManager([]gettWorkers.Worker{
-		workers.GetWorker(),
-	}, map[string]string{"poll_interval": "1"})
-	events.InitConsumer(deps.DB)
-
-	httpTermination := make(chan struct{})
+	tracing.InitFromConfig()
+	var deps Deps
+	switch *storage {
+	case storagePostgres:
+		deps = initServices(appConfig)
+		releaseAllJobs()
+	case storageMemory:
+		logger.Warn("running on the memory storage, nothing is kept once the service stops")
+		deps = initMemoryServices()
+	default:
+		logger.Fatalf("unknown storage %q, use %s or %s", *storage, storagePostgres, storageMemory)
+	}
+	flags.Instance = flags.NewFlags(deps.Flags)
+	dispatcher := webhooks.NewDispatcher(deps.Webhooks, webhooks.OptionsFromConfig())
+	if deps.memory() {
+		deps.Persons = person.NewMemoryBackends(dispatcher)
+	} else {
+		deps.Persons = person.NewBackends(deps.DB, deps.Redis, dispatcher)
+	}
+	service := person.NewPersonService(deps.Persons)
+	app := skeleton.NewApp(appConfig, createRouter(config.Current(), deps, service), logger, livenessPingers())
+	grpcServer, grpcHealth, grpcAddr := createGRPCServer(config.Current(), deps, service)
+
+	stopWorker := make(chan struct{})
+	workerStopped := make(chan struct{})
+	worker := workers.GetWorker(service, deps.Persons.Cache)
+	if deps.memory() {
+		go func() {
+			workers.RunLocally(worker, localWorkerInterval, stopWorker)
+			close(workerStopped)
+		}()
+	} else {
+		gettWorkers.InitJobsManager([]gettWorkers.Worker{worker}, map[string]string{"poll_interval": "1"})
+		events.InitConsumer(service)
+	}
+
+	stopReload := make(chan struct{})
+	go func() {
+		if err := config.NewReloaderFromConfig(appConfig).Run(stopReload); err != nil {
+			logger.Error("unable to watch the settings, changes are applied on restart ", err)
+		}
+	}()
+
+	stopWebhooks := make(chan struct{})
+	webhooksStopped := make(chan struct{})
+	go func() {
+		dispatcher.Run(stopWebhooks)
+		close(webhooksStopped)
+	}()
+
+	// the http server stopping is a failure until the signal, afterwards nothing reads it
+	failed := make(chan error, 2)
+	httpTermination := make(chan struct{}, 1)
+	httpStopped := make(chan struct{})
 	go app.Run(httpTermination)
-	<-httpTermination
+	go func() {
+		<-httpTermination
+		close(httpStopped)
+		failed <- errHTTPStopped
+	}()
+	go func() {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			failed <- err
+			return
+		}
+		logger.Printf("grpc server is listening on %s", grpcAddr)
+		if err := grpcServer.Serve(listener); err != nil {
+			failed <- err
+		}
+	}()
+
+	manager := shutdownManager(httpStopped, stopGRPCServer(grpcServer, grpcHealth), deps, stopWorker, stopReload,
+		stopWebhooks, workerStopped, webhooksStopped)
+	manager.Wait(failed)
+	if err := manager.Shutdown(); err != nil {
+		logger.Error("the service did not shut down cleanly ", err)
+		os.Exit(1)
+	}
+}
+
+// shutdownManager - stop taking messages and jobs and drain the ones in process, then the http requests and the grpc
+// calls, then close the connections they used. the skeleton app shuts the http server down on the signal itself and
+// without a deadline, the http stage gives it a timeout of its own. the memory storage has no connections, its local
+// worker is stopped instead of the jobs manager
+func shutdownManager(httpStopped <-chan struct{}, stopGRPC func(ctx context.Context) error, deps Deps, stopWorker,
+	stopReload, stopWebhooks chan struct{}, workerStopped, webhooksStopped <-chan struct{}) *lifecycle.Manager {
+	options := lifecycle.OptionsFromConfig()
+	manager := lifecycle.NewManager()
+
+	stopWorkers := workers.Stop
+	if deps.memory() {
+		stopWorkers = func(ctx context.Context) error {
+			close(stopWorker)
+			return lifecycle.WaitFor(ctx, func() { <-workerStopped })
+		}
+	}
+	manager.Add("drain", options.DrainTimeout, lifecycle.All(
+		events.Stop,
+		stopWorkers,
+		func(ctx context.Context) error {
+			close(stopReload)
+			return nil
+		},
+		func(ctx context.Context) error {
+			close(stopWebhooks)
+			return lifecycle.WaitFor(ctx, func() { <-webhooksStopped })
+		},
+	))
+	manager.Add("http", options.HTTPTimeout, func(ctx context.Context) error {
+		return lifecycle.WaitFor(ctx, func() { <-httpStopped })
+	})
+	manager.Add("grpc", options.HTTPTimeout, stopGRPC)
+	if deps.memory() {
+		return manager
+	}
+
+	manager.Add("db", options.CloseTimeout, lifecycle.Close(deps.DB.Close))
+	manager.Add("redis", options.CloseTimeout, lifecycle.Close(deps.Redis.Close))
+	manager.Add("amqp", options.CloseTimeout, lifecycle.Close(func() error {
+		if deps.RabbitMQ == nil {
+			return nil
+		}
+		return deps.RabbitMQ.Close()
+	}))
+
+	return manager
 }
 
 func createLogger() *logrus.Logger {
@@ -72,6 +247,21 @@ func createLogger() *logrus.Logger {
 	return logger
 }
 
+// applyLogLevel - person.log_level sets the level of the service and the packages logs, empty restores the level of
+// the environment
+func applyLogLevel(logger *logrus.Logger, c config.Config) {
+	if c.Person.LogLevel == "" {
+		logger.SetLevel(getLogLevel())
+		logrus.SetLevel(logrus.InfoLevel)
+		return
+	}
+
+	// validated when the settings were loaded
+	level, _ := logrus.ParseLevel(c.Person.LogLevel)
+	logger.SetLevel(level)
+	logrus.SetLevel(level)
+}
+
 func getLogLevel() logrus.Level {
 	env := gettConfig.GetConfig().Env
 
@@ -1,48 +1,81 @@
 package main
 
 import (
-	"context"
-	"database/sql"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/deadline"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/events"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/flags"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/health"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
 	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ratelimit"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/statuswriter"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/webhooks"
 	"net/http"
 
 	"github.com/gorilla/mux"
-	"github.com/gtforge/global_services_common_go/gett-storages"
 	"github.com/gtforge/go-healthcheck"
 	"github.com/gtforge/go-skeleton-draft/core"
 )
 
-func createHealthCheckHandler(pingers []healthcheck.Pinger) http.Handler {
-	hc := healthcheck.NewHealthCheck(pingers...)
-	return healthcheck.MakeHealthcheckHandler(hc)
+// livenessPingers - the process is alive as long as it serves, the dependencies are left to the readiness check so
+// their outage doesn't restart every replica
+func livenessPingers() []healthcheck.Pinger {
+	return []healthcheck.Pinger{}
 }
 
-func healthCheckPingers(db *sql.DB) []healthcheck.Pinger {
+// readinessPingers - postgres, redis and the migrations gate the traffic, rabbitmq, the orders service and the
+// ratings cache are reported without failing it. the memory storage has only the last two
+func readinessPingers(deps Deps) []healthcheck.Pinger {
+	options := health.OptionsFromConfig()
+	if deps.memory() {
+		return []healthcheck.Pinger{
+			health.Degraded(person.OrdersPinger(options.OrdersTimeout)),
+			health.Degraded(person.RatingsCachePinger(deps.Persons.Cache, options.RatingsCacheMaxAge)),
+		}
+	}
+
 	return []healthcheck.Pinger{
-		// Example of the built-in db pinger
-		healthcheck.MakeDbPinger(db, "main"),
-
-		// Example of a custom pinger
-		func(ctx context.Context) (map[string]interface{}, error) {
-			values := map[string]interface{}{}
-			values["custom"] = map[string]interface{}{
-				"active_rides": 5050,
-			}
-			return values, nil
-		},
+		healthcheck.MakeDbPinger(deps.DB.DB(), "main"),
+		health.RedisPinger(deps.Redis),
+		health.MigrationsPinger(deps.DB.DB(), options.MigrationsDir, options.MigrationsTable),
+		health.Degraded(events.Pinger(deps.RabbitMQ)),
+		health.Degraded(person.OrdersPinger(options.OrdersTimeout)),
+		health.Degraded(person.RatingsCachePinger(deps.Persons.Cache, options.RatingsCacheMaxAge)),
 	}
 }
 
-func createRouter() *mux.Router {
+func createRouter(settings config.Config, deps Deps, service person.Service) *mux.Router {
 	router := mux.NewRouter()
+	// the skeleton app serves the router through a status writer that can't flush, the rating stream needs to
+	router.Use(statuswriter.Middleware)
+	router.Use(tracing.Middleware)
+	router.Use(metrics.Middleware)
+	router.Use(auth.NewAuthenticatorFromConfig(settings).Middleware)
+
+	// the rate limits and the request timeouts follow the reloaded settings
+	limiter := ratelimit.NewLimiterFromConfig(settings, deps.RateLimit)
+	config.OnReload(limiter.Reload)
+	router.Use(limiter.Middleware)
+	deadlines := deadline.NewDeadlinesFromConfig(settings)
+	config.OnReload(deadlines.Reload)
+	router.Use(deadlines.Middleware)
 
-	router.Handle("/alive", createHealthCheckHandler(healthCheckPingers(gettStorages.DB.DB()))).Methods(http.MethodGet)
+	router.Handle("/alive", health.Handler(livenessPingers()...)).Methods(http.MethodGet)
+	router.Handle("/ready", health.Handler(readinessPingers(deps)...)).Methods(http.MethodGet)
+	router.Handle("/metrics", metrics.Instance.Handler()).Methods(http.MethodGet)
 	router.PathPrefix("/debug/pprof/").Handler(skeleton.BasicAuthMiddleware(http.DefaultServeMux))
 
 	s := router.PathPrefix("/api/v1").Subrouter()
 
-	ridesHandler := person.NewHandler(person.NewPersonService(person.NewRepo(gettStorages.DB)))
+	ridesHandler := person.NewHandler(service, deps.Persons.Stream, idempotency.NewKeeperFromConfig(deps.Idempotency))
 	ridesHandler.RegisterRoutes(s)
+	webhooks.NewHandler(deps.Webhooks, webhooks.NewTargets(settings.Person.Webhooks.AllowedHosts)).RegisterRoutes(s)
+	flags.NewHandler(flags.Instance).RegisterRoutes(s)
+	s.HandleFunc("/admin/config", auth.Require(auth.RoleAdmin, config.Handler)).Methods(http.MethodGet)
 
 	return router
 }
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackend(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "backend test")
+}
+
+// skeletonWriter - the status writer of the skeleton app, it records the status and hides the http.Flusher
+type skeletonWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *skeletonWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+var _ = ginkgo.Describe("router", func() {
+
+	var (
+		deps   Deps
+		server *httptest.Server
+	)
+
+	ginkgo.BeforeEach(func() {
+		c := config.Defaults()
+		c.Auth.Simple = false
+		c.Person.RatingsStream.MaxDuration = 5 * time.Second
+		config.Set(c)
+
+		deps = initMemoryServices()
+		deps.Persons = person.NewMemoryBackends(nil)
+
+		// the skeleton app middlewares need the platform initialized, the router is served through a status writer
+		// that can't flush like theirs
+		router := createRouter(config.Current(), deps, person.NewPersonService(deps.Persons))
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			router.ServeHTTP(&skeletonWriter{ResponseWriter: w}, req)
+		}))
+	})
+
+	ginkgo.AfterEach(func() {
+		server.Close()
+		config.Set(config.Defaults())
+	})
+
+	ginkgo.Context("validate that the rating stream is flushed through the middlewares", func() {
+
+		ginkgo.It("do", func() {
+			resp, err := http.Get(server.URL + "/api/v1/ratings/stream")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			defer resp.Body.Close()
+			gomega.Expect(resp.Header.Get("Content-Type")).To(gomega.Equal("text/event-stream"))
+
+			lines := make(chan string, 10)
+			go func() {
+				defer ginkgo.GinkgoRecover()
+				scanner := bufio.NewScanner(resp.Body)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+				close(lines)
+			}()
+
+			// a writer that can't flush holds everything until the stream ends after 5 seconds
+			gomega.Eventually(lines, time.Second).Should(gomega.Receive(gomega.HavePrefix("retry: ")))
+
+			deps.Persons.Stream.Publish(person.RatingChange{PersonID: 1, Market: "IL", Rating: 4.5})
+			gomega.Eventually(lines, time.Second).Should(gomega.Receive(gomega.Equal("id: 1")))
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	personv1 "github.com/gtforge/go-skeleton-draft/structure/api/proto/person/v1"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/deadline"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/lifecycle"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"os"
+)
+
+const grpcServiceName = "person.v1.PersonService"
+
+// createGRPCServer - the person api over gRPC with the health and reflection services, on GRPC_PORT (9090 by
+// default). the calls are authenticated, rate limited and bounded like the http requests. the health service reports
+// serving until the server is shut down
+func createGRPCServer(settings config.Config, deps Deps, service person.Service) (*grpc.Server, *health.Server, string) {
+	port := os.Getenv("GRPC_PORT")
+	if len(port) == 0 {
+		port = "9090"
+	}
+
+	limiter := ratelimit.NewLimiterFromConfig(settings, deps.RateLimit)
+	config.OnReload(limiter.Reload)
+	deadlines := deadline.NewDeadlinesFromConfig(settings)
+	config.OnReload(deadlines.Reload)
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(chainUnaryInterceptors(
+		auth.NewAuthenticatorFromConfig(settings).UnaryInterceptor,
+		limiter.UnaryInterceptor,
+		deadlines.UnaryInterceptor,
+	)))
+	personv1.RegisterPersonServiceServer(server, person.NewGRPCServer(service, idempotency.NewKeeperFromConfig(deps.Idempotency)))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(grpcServiceName, grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return server, healthServer, ":" + port
+}
+
+// stopGRPCServer - report not serving and let the calls in process complete, the remaining ones are cut at the deadline
+func stopGRPCServer(server *grpc.Server, healthServer *health.Server) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		healthServer.Shutdown()
+		err := lifecycle.WaitFor(ctx, server.GracefulStop)
+		if err != nil {
+			server.Stop()
+		}
+		return err
+	}
+}
+
+// chainUnaryInterceptors - run the interceptors in order around the call, the first one wraps the others
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], handler
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
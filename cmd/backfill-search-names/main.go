@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"github.com/gtforge/global_services_common_go/gett-config"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
+	"github.com/sirupsen/logrus"
+)
+
+var batchSize = flag.Int("batch-size", 500, "the persons read and filled per query")
+
+// main - a one-off job run after the add_search_names_to_persons migration, it fills the search names of the
+// existing persons the way the service saves them
+func main() {
+	flag.Parse()
+
+	appConfig := gettConfig.GetConfig()
+	gettStorages.InitDb(appConfig.Db, appConfig.AppEnv)
+
+	filled, err := person.BackfillSearchNames(gettStorages.DB, *batchSize)
+	if err != nil {
+		logrus.WithField("filled", filled).Fatal("search names backfill failed ", err)
+	}
+	logrus.WithField("filled", filled).Info("search names are backfilled")
+}
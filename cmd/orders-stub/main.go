@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ordersstub"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"os"
+)
+
+var (
+	addr          = flag.String("addr", ":8081", "the address to serve on, person.orders_url defaults to localhost:8081")
+	seed          = flag.Int64("seed", 1, "the same seed serves the same generated orders")
+	maxOrders     = flag.Int("max-orders", 20, "the generated persons have 0 to max-orders orders")
+	data          = flag.String("data", "", "a json file of orders by person id, served instead of the generated ones")
+	latency       = flag.Duration("latency", 0, "how long every response is held, e.g. 300ms")
+	errorRate     = flag.Float64("error-rate", 0, "the share of the requests answered with a 500, from 0 to 1")
+	malformedRate = flag.Float64("malformed-rate", 0, "the share of the requests answered with a body that isn't json, from 0 to 1")
+)
+
+// main - a local stand-in for the orders service, so the ratings can be computed without it
+func main() {
+	flag.Parse()
+
+	stub, err := ordersstub.NewStub(ordersstub.Options{
+		Seed:          *seed,
+		MaxOrders:     *maxOrders,
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		MalformedRate: *malformedRate,
+	})
+	if err != nil {
+		logrus.Fatal("invalid orders stub options ", err)
+	}
+
+	if *data != "" {
+		file, err := os.Open(*data)
+		if err != nil {
+			logrus.Fatal("unable to open the orders dataset ", err)
+		}
+		err = stub.Load(file)
+		file.Close()
+		if err != nil {
+			logrus.Fatal("unable to load the orders dataset ", err)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"addr":           *addr,
+		"seed":           *seed,
+		"latency":        latency.String(),
+		"error_rate":     *errorRate,
+		"malformed_rate": *malformedRate,
+	}).Info("orders stub is listening")
+	if err := http.ListenAndServe(*addr, stub.Handler()); err != nil {
+		logrus.Fatal("orders stub stopped ", err)
+	}
+}
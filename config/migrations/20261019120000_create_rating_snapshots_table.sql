@@ -0,0 +1,18 @@
+-- +swan Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS rating_snapshots
+(
+  id bigserial primary key,
+  person_id bigint not null references persons (id) on delete cascade,
+  rating double precision not null,
+  order_count bigint not null,
+  kind text not null,
+  created_at timestamp with time zone not null
+);
+
+CREATE INDEX IF NOT EXISTS rating_snapshots_person_id_created_at_idx ON rating_snapshots (person_id, created_at);
+
+-- +swan Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS rating_snapshots;
@@ -0,0 +1,10 @@
+-- +swan Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS market text not null default 'IL';
+CREATE INDEX IF NOT EXISTS persons_market_idx ON persons (market);
+
+-- +swan Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS persons_market_idx;
+ALTER TABLE persons DROP COLUMN IF EXISTS market;
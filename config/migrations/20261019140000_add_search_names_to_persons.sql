@@ -0,0 +1,20 @@
+-- +swan Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS normalized_name text not null default '';
+ALTER TABLE persons ADD COLUMN IF NOT EXISTS latin_name text not null default '';
+
+-- the service rewrites both columns on every save. lower() doesn't strip the accents nor transliterates, so the
+-- existing rows are filled by the backfill-search-names job with the normalization of the service
+
+CREATE INDEX IF NOT EXISTS persons_normalized_name_trgm_idx ON persons USING gin (normalized_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS persons_latin_name_trgm_idx ON persons USING gin (latin_name gin_trgm_ops);
+
+-- +swan Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX IF EXISTS persons_latin_name_trgm_idx;
+DROP INDEX IF EXISTS persons_normalized_name_trgm_idx;
+ALTER TABLE persons DROP COLUMN IF EXISTS latin_name;
+ALTER TABLE persons DROP COLUMN IF EXISTS normalized_name;
@@ -0,0 +1,40 @@
+-- +swan Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+  id serial primary key,
+  url text not null,
+  secret text not null,
+  event_types text not null,
+  enabled boolean not null default true,
+  consecutive_failures integer not null default 0,
+  disabled_at timestamp with time zone,
+  created_at timestamp with time zone not null default now(),
+  updated_at timestamp with time zone not null default now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+  id bigserial primary key,
+  subscription_id integer not null references webhook_subscriptions (id) on delete cascade,
+  event_id text not null,
+  event_type text not null,
+  payload text not null,
+  status text not null,
+  attempts integer not null default 0,
+  response_status integer not null default 0,
+  last_error text not null default '',
+  next_attempt_at timestamp with time zone not null,
+  delivered_at timestamp with time zone,
+  created_at timestamp with time zone not null default now(),
+  updated_at timestamp with time zone not null default now()
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at);
+
+-- +swan Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
@@ -5,17 +5,17 @@ go 1.12
 require (
 	github.com/ansel1/merry v1.5.1
 	github.com/bitly/go-simplejson v0.5.0 // indirect
-	github.com/fsnotify/fsnotify v1.4.9 // indirect
+	github.com/fsnotify/fsnotify v1.4.9
 	github.com/garyburd/redigo v1.6.2 // indirect
 	github.com/gofrs/uuid v3.3.0+incompatible // indirect
 	github.com/golang/mock v1.3.1
-	github.com/golang/protobuf v1.3.2 // indirect
+	github.com/golang/protobuf v1.3.2
 	github.com/google/uuid v1.1.1 // indirect
 	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75 // indirect
 	github.com/gorilla/mux v1.7.4
 	github.com/gtforge/gett-api v6.0.162+incompatible
 	github.com/gtforge/global_services_common_go v1.16.2
-	github.com/gtforge/gls v0.0.0-20200427101431-262ceba8ae29 // indirect
+	github.com/gtforge/gls v0.0.0-20200427101431-262ceba8ae29
 	github.com/gtforge/go-healthcheck v0.1.3
 	github.com/gtforge/go-skeleton-draft/core v0.0.3
 	github.com/gtforge/go-transport v1.1.0 // indirect
@@ -28,6 +28,7 @@ require (
 	github.com/onsi/ginkgo v1.10.1
 	github.com/onsi/gomega v1.7.0
 	github.com/opentracing-contrib/go-amqp v0.0.0-20171102191528-e26701f95620 // indirect
+	github.com/opentracing/opentracing-go v1.1.0
 	github.com/pelletier/go-toml v1.7.0 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/sirupsen/logrus v1.5.0
@@ -42,6 +43,7 @@ require (
 	github.com/unrolled/render v1.0.3
 	golang.org/x/sys v0.0.0-20200427175716-29b57079015a // indirect
 	golang.org/x/text v0.3.2 // indirect
+	google.golang.org/grpc v1.27.1
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/ini.v1 v1.55.0 // indirect
 	gopkg.in/redis.v5 v5.2.9 // indirect
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"strings"
+	"time"
+)
+
+// grpcExemptServices - the services called without credentials, like the exempt paths of the http api
+var grpcExemptServices = []string{"/grpc.health.v1.Health/"}
+
+// UnaryInterceptor - authenticate the gRPC calls by the bearer JWT of the authorization metadata and put the caller
+// on the context. the HMAC signature covers the http body, so HMAC clients call the http api
+func (a *Authenticator) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	if !a.enabled {
+		return handler(WithIdentity(ctx, anonymousAdmin), req)
+	}
+	for _, exempt := range grpcExemptServices {
+		if strings.HasPrefix(info.FullMethod, exempt) {
+			return handler(ctx, req)
+		}
+	}
+
+	identity, err := a.authenticateGRPC(ctx)
+	if err != nil {
+		logrus.WithField("method", info.FullMethod).Warn("unauthenticated call: ", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = WithIdentity(ctx, identity)
+	Logger(ctx).WithField("method", info.FullMethod).Info("authenticated call")
+	return handler(ctx, req)
+}
+
+func (a *Authenticator) authenticateGRPC(ctx context.Context) (Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		if !strings.HasPrefix(header, "Bearer ") {
+			continue
+		}
+		claims, err := ParseJWT(strings.TrimPrefix(header, "Bearer "), a.secret, time.Now())
+		if err != nil {
+			return Identity{}, err
+		}
+		return Identity{Subject: claims.Subject, Method: MethodJWT, Roles: claims.Roles}, nil
+	}
+
+	return Identity{}, ErrMissingCredentials
+}
+
+// RequireGRPC - the PermissionDenied status when the caller of the call doesn't hold the role, nil otherwise
+func RequireGRPC(ctx context.Context, role string) error {
+	identity, ok := FromContext(ctx)
+	if !ok || !identity.HasRole(role) {
+		Logger(ctx).WithField("required_role", role).Warn("forbidden call")
+		return status.Error(codes.PermissionDenied, ErrForbidden.Error())
+	}
+
+	return nil
+}
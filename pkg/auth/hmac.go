@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ClientHeader    = "X-Auth-Client"
+	TimestampHeader = "X-Auth-Timestamp"
+	SignatureHeader = "X-Auth-Signature"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrStaleSignature   = errors.New("request timestamp is outside the allowed window")
+	ErrBodyTooLarge     = errors.New("request body is too large to be verified")
+)
+
+// StringToSign - method, path with query, client, timestamp and the hex sha256 of the body, separated by new lines.
+// the client is signed so a caller can't claim the roles of another client
+func StringToSign(method, requestURI, client, timestamp string, body []byte) string {
+	digest := sha256.Sum256(body)
+	return method + "\n" + requestURI + "\n" + client + "\n" + timestamp + "\n" + hex.EncodeToString(digest[:])
+}
+
+// SignRequest - the hex encoded signature a client sends in the X-Auth-Signature header, signed with its secret
+func SignRequest(secret []byte, method, requestURI, client, timestamp string, body []byte) string {
+	return hex.EncodeToString(sign(secret, StringToSign(method, requestURI, client, timestamp, body)))
+}
+
+// verifyHMAC - check the signature headers of the request with the secret of its client, the body is read up to
+// maxBody bytes and restored for the next handlers
+func verifyHMAC(w http.ResponseWriter, req *http.Request, secretOf func(client string) []byte, maxSkew time.Duration, maxBody int64, now time.Time) (string, error) {
+	client := req.Header.Get(ClientHeader)
+	timestamp := req.Header.Get(TimestampHeader)
+	signature, err := hex.DecodeString(req.Header.Get(SignatureHeader))
+	if client == "" || timestamp == "" || err != nil {
+		return "", ErrInvalidSignature
+	}
+	secret := secretOf(client)
+	if len(secret) == 0 {
+		return "", ErrInvalidSignature
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignature
+	}
+	skew := now.Sub(time.Unix(seconds, 0))
+	if skew > maxSkew || skew < -maxSkew {
+		return "", ErrStaleSignature
+	}
+
+	var body []byte
+	if req.Body != nil {
+		body, err = ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxBody))
+		if err != nil {
+			return "", ErrBodyTooLarge
+		}
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	expected := sign(secret, StringToSign(req.Method, req.URL.RequestURI(), client, timestamp, body))
+	if !hmac.Equal(signature, expected) {
+		return "", ErrInvalidSignature
+	}
+
+	return client, nil
+}
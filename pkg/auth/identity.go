@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	MethodJWT  = "jwt"
+	MethodHMAC = "hmac"
+	MethodNone = "none"
+)
+
+type contextKey string
+
+const identityKey contextKey = "auth_identity"
+
+// Identity - the authenticated caller of a request
+type Identity struct {
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"`
+	Roles   []string `json:"roles"`
+}
+
+// anonymousAdmin - the caller of every request while authentication is disabled
+var anonymousAdmin = Identity{Subject: "anonymous", Method: MethodNone, Roles: []string{RoleAdmin}}
+
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey, identity)
+}
+
+// FromContext - the caller identity put on the context by the middleware
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey).(Identity)
+	return identity, ok
+}
+
+// Logger - a log entry carrying the caller of the request, for auditing
+func Logger(ctx context.Context) *logrus.Entry {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return logrus.WithField("caller", "anonymous")
+	}
+
+	return logrus.WithFields(logrus.Fields{"caller": identity.Subject, "auth_method": identity.Method})
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var ErrMissingCredentials = errors.New("missing credentials")
+
+const defaultMaxBodyBytes = 1 << 20
+
+type Authenticator struct {
+	enabled       bool
+	secret        []byte
+	exemptPaths   []string
+	maxSkew       time.Duration
+	maxBodyBytes  int64
+	clientRoles   map[string][]string
+	clientSecrets map[string]string
+	render        *render.Render
+}
+
+func NewAuthenticator(enabled bool, secret string, exemptPaths []string, maxSkew time.Duration) *Authenticator {
+	return &Authenticator{
+		enabled:       enabled,
+		secret:        []byte(secret),
+		exemptPaths:   exemptPaths,
+		maxSkew:       maxSkew,
+		maxBodyBytes:  defaultMaxBodyBytes,
+		clientRoles:   map[string][]string{},
+		clientSecrets: map[string]string{},
+		render:        render.New(),
+	}
+}
+
+// WithClientSecrets - the secrets of the HMAC clients that don't sign with the shared secret
+func (a *Authenticator) WithClientSecrets(clientSecrets map[string]string) *Authenticator {
+	a.clientSecrets = clientSecrets
+	return a
+}
+
+// WithMaxBodyBytes - the largest body of a signed request, larger ones are rejected before the signature is checked
+func (a *Authenticator) WithMaxBodyBytes(maxBodyBytes int64) *Authenticator {
+	a.maxBodyBytes = maxBodyBytes
+	return a
+}
+
+// WithClientRoles - the roles of the HMAC clients, clients without an entry are readers
+func (a *Authenticator) WithClientRoles(clientRoles map[string][]string) *Authenticator {
+	a.clientRoles = clientRoles
+	return a
+}
+
+// NewAuthenticatorFromConfig - global.auth.simple enables the middleware, global.auth.shared_secret_key signs the
+// tokens and the requests of the HMAC clients, person.auth.client_secrets overrides it for the clients listed and
+// person.auth.exempt_paths lists the path prefixes served without authentication
+func NewAuthenticatorFromConfig(settings config.Config) *Authenticator {
+	auth := settings.Person.Auth
+
+	return NewAuthenticator(settings.Auth.Simple, settings.Auth.SharedSecretKey, auth.ExemptPaths, auth.MaxSkew).
+		WithClientRoles(auth.ClientRoles).
+		WithClientSecrets(auth.ClientSecrets).
+		WithMaxBodyBytes(auth.MaxBodyBytes)
+}
+
+// Middleware - authenticate by bearer JWT or by HMAC signature headers and put the caller on the request context
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !a.enabled {
+			next.ServeHTTP(w, req.WithContext(WithIdentity(req.Context(), anonymousAdmin)))
+			return
+		}
+		if a.isExempt(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		identity, err := a.authenticate(w, req)
+		if err == ErrBodyTooLarge {
+			a.render.JSON(w, http.StatusRequestEntityTooLarge, skeleton.NewAPIError(http.StatusText(http.StatusRequestEntityTooLarge), err))
+			return
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{"path": req.URL.Path, "remote_addr": req.RemoteAddr}).Warn("unauthenticated request: ", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="person"`)
+			a.render.JSON(w, http.StatusUnauthorized, skeleton.NewAPIError(http.StatusText(http.StatusUnauthorized), err))
+			return
+		}
+
+		ctx := WithIdentity(req.Context(), identity)
+		Logger(ctx).WithFields(logrus.Fields{"method": req.Method, "path": req.URL.Path}).Info("authenticated request")
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (a *Authenticator) authenticate(w http.ResponseWriter, req *http.Request) (Identity, error) {
+	if header := req.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		claims, err := ParseJWT(strings.TrimPrefix(header, "Bearer "), a.secret, time.Now())
+		if err != nil {
+			return Identity{}, err
+		}
+		return Identity{Subject: claims.Subject, Method: MethodJWT, Roles: claims.Roles}, nil
+	}
+
+	if req.Header.Get(SignatureHeader) != "" {
+		client, err := verifyHMAC(w, req, a.clientSecret, a.maxSkew, a.maxBodyBytes, time.Now())
+		if err != nil {
+			return Identity{}, err
+		}
+		roles, ok := a.clientRoles[client]
+		if !ok {
+			roles = []string{RoleReader}
+		}
+		return Identity{Subject: client, Method: MethodHMAC, Roles: roles}, nil
+	}
+
+	return Identity{}, ErrMissingCredentials
+}
+
+// clientSecret - the secret of the client, the shared secret when it has none of its own
+func (a *Authenticator) clientSecret(client string) []byte {
+	if secret := a.clientSecrets[client]; secret != "" {
+		return []byte(secret)
+	}
+
+	return a.secret
+}
+
+func (a *Authenticator) isExempt(path string) bool {
+	for _, exempt := range a.exemptPaths {
+		if path == exempt || (strings.HasSuffix(exempt, "/") && strings.HasPrefix(path, exempt)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"bytes"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAuth(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "auth middleware test")
+}
+
+var _ = ginkgo.Describe("auth middleware", func() {
+
+	var (
+		secret        = "secret"
+		authenticator *Authenticator
+		req           *http.Request
+		recorder      *httptest.ResponseRecorder
+		identity      Identity
+		authenticated bool
+	)
+
+	ginkgo.BeforeEach(func() {
+		authenticator = NewAuthenticator(true, secret, []string{"/alive", "/debug/pprof/"}, time.Minute).
+			WithClientRoles(map[string][]string{"backoffice": {RoleAdmin}}).
+			WithClientSecrets(map[string]string{"backoffice": "backoffice-secret", "reports": "reports-secret"}).
+			WithMaxBodyBytes(64)
+		recorder = httptest.NewRecorder()
+		authenticated = false
+	})
+
+	//before -> just before -> it
+	ginkgo.JustBeforeEach(func() {
+		authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, authenticated = FromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})).ServeHTTP(recorder, req)
+	})
+
+	ginkgo.Context("validate that requests without credentials are rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+	})
+
+	ginkgo.Context("validate that exempt paths are served without credentials", func() {
+
+		ginkgo.BeforeEach(func() {
+			req = httptest.NewRequest(http.MethodGet, "/debug/pprof/heap", nil)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(authenticated).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that a valid bearer token authenticates the caller", func() {
+
+		ginkgo.BeforeEach(func() {
+			token, _ := SignJWT(Claims{Subject: "orders", Roles: []string{RoleEditor}, ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte(secret))
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(identity).To(gomega.Equal(Identity{Subject: "orders", Method: MethodJWT, Roles: []string{RoleEditor}}))
+		})
+	})
+
+	ginkgo.Context("validate that an expired bearer token is rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			token, _ := SignJWT(Claims{Subject: "orders", ExpiresAt: time.Now().Add(-time.Hour).Unix()}, []byte(secret))
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+	})
+
+	ginkgo.Context("validate that a token signed with another secret is rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			token, _ := SignJWT(Claims{Subject: "orders"}, []byte("other"))
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+	})
+
+	ginkgo.Context("validate that a signed request authenticates the client", func() {
+
+		ginkgo.BeforeEach(func() {
+			body := []byte(`{"name":"dadi"}`)
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req = httptest.NewRequest(http.MethodPost, "/api/v1/person?x=1", bytes.NewReader(body))
+			req.Header.Set(ClientHeader, "reports")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte("reports-secret"), http.MethodPost, "/api/v1/person?x=1", "reports", timestamp, body))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(identity).To(gomega.Equal(Identity{Subject: "reports", Method: MethodHMAC, Roles: []string{RoleReader}}))
+		})
+	})
+
+	ginkgo.Context("validate that the shared secret doesn't sign for a client with a secret of its own", func() {
+
+		ginkgo.BeforeEach(func() {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set(ClientHeader, "backoffice")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte(secret), http.MethodGet, "/api/v1/persons", "backoffice", timestamp, nil))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(authenticated).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that a signed request claiming another client is rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set(ClientHeader, "backoffice")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte("reports-secret"), http.MethodGet, "/api/v1/persons", "reports", timestamp, nil))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+			gomega.Expect(authenticated).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that a client without a secret of its own signs with the shared secret", func() {
+
+		ginkgo.BeforeEach(func() {
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set(ClientHeader, "unknown")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte(secret), http.MethodGet, "/api/v1/persons", "unknown", timestamp, nil))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(identity).To(gomega.Equal(Identity{Subject: "unknown", Method: MethodHMAC, Roles: []string{RoleReader}}))
+		})
+	})
+
+	ginkgo.Context("validate that a signed request with a body over the limit is rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			body := bytes.Repeat([]byte("a"), 65)
+			timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+			req = httptest.NewRequest(http.MethodPost, "/api/v1/person", bytes.NewReader(body))
+			req.Header.Set(ClientHeader, "backoffice")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte("backoffice-secret"), http.MethodPost, "/api/v1/person", "backoffice", timestamp, body))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+			gomega.Expect(authenticated).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that a stale signed request is rejected", func() {
+
+		ginkgo.BeforeEach(func() {
+			timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+			req = httptest.NewRequest(http.MethodGet, "/api/v1/persons", nil)
+			req.Header.Set(ClientHeader, "backoffice")
+			req.Header.Set(TimestampHeader, timestamp)
+			req.Header.Set(SignatureHeader, SignRequest([]byte("backoffice-secret"), http.MethodGet, "/api/v1/persons", "backoffice", timestamp, nil))
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusUnauthorized))
+		})
+	})
+})
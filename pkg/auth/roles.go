@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"net/http"
+)
+
+const (
+	RoleReader = "reader"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var ErrForbidden = errors.New("the caller is not allowed to perform this action")
+
+// roleLevels - every role is granted the permissions of the roles below it
+var roleLevels = map[string]int{
+	RoleReader: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+var forbiddenRender = render.New()
+
+// HasRole - whether one of the identity roles grants the required one
+func (i Identity) HasRole(required string) bool {
+	for _, role := range i.Roles {
+		if roleLevels[role] >= roleLevels[required] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Require - serve the handler only to callers holding the role, others get a 403
+func Require(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		identity, ok := FromContext(req.Context())
+		if !ok || !identity.HasRole(role) {
+			Logger(req.Context()).WithFields(logrus.Fields{"path": req.URL.Path, "required_role": role}).Warn("forbidden request")
+			forbiddenRender.JSON(w, http.StatusForbidden, skeleton.NewAPIError(http.StatusText(http.StatusForbidden), ErrForbidden))
+			return
+		}
+
+		next(w, req)
+	}
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = ginkgo.Describe("role authorization", func() {
+
+	var (
+		req      *http.Request
+		recorder *httptest.ResponseRecorder
+	)
+
+	ginkgo.BeforeEach(func() {
+		req = httptest.NewRequest(http.MethodDelete, "/api/v1/delete_person/1", nil)
+		recorder = httptest.NewRecorder()
+	})
+
+	serve := func() {
+		Require(RoleEditor, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})(recorder, req)
+	}
+
+	ginkgo.Context("validate that a caller without the role is forbidden", func() {
+
+		ginkgo.BeforeEach(func() {
+			req = req.WithContext(WithIdentity(req.Context(), Identity{Subject: "backoffice", Roles: []string{RoleReader}}))
+		})
+
+		ginkgo.It("do", func() {
+			serve()
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusForbidden))
+		})
+	})
+
+	ginkgo.Context("validate that a higher role grants the required one", func() {
+
+		ginkgo.BeforeEach(func() {
+			req = req.WithContext(WithIdentity(req.Context(), Identity{Subject: "backoffice", Roles: []string{RoleAdmin}}))
+		})
+
+		ginkgo.It("do", func() {
+			serve()
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+		})
+	})
+
+	ginkgo.Context("validate that a request without identity is forbidden", func() {
+
+		ginkgo.It("do", func() {
+			serve()
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusForbidden))
+		})
+	})
+})
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"github.com/gtforge/global_services_common_go/gett-config"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Config - the typed settings of the service, the person section of base.yml and the global keys it relies on.
+// Every key can be overridden by an environment variable, CONFIG_SETTINGS_ followed by the upper cased key with the
+// dots and slashes replaced by underscores, e.g. CONFIG_SETTINGS_PERSON_WORKER_CONCURRENCY=50
+type Config struct {
+	Auth   GlobalAuth `config:"global.auth"`
+	Person Person     `config:"person"`
+	// Markets - the markets configured under global.env
+	Markets []string `config:"-"`
+}
+
+type GlobalAuth struct {
+	Simple          bool   `config:"simple"`
+	SharedSecretKey string `config:"shared_secret_key" secret:"true"`
+}
+
+type Person struct {
+	// Market - the market of persons created without one and of requests without one
+	Market string `config:"market"`
+	// OrdersURL - the orders service of the markets without global.env.<market>.endpoints.orders.hostname
+	OrdersURL string `config:"orders_url"`
+	// LogLevel - the level of the service logs, empty keeps the level of the environment
+	LogLevel       string         `config:"log_level"`
+	Store          Store          `config:"store"`
+	Worker         Worker         `config:"worker"`
+	Events         Events         `config:"events"`
+	Auth           Auth           `config:"auth"`
+	Idempotency    Idempotency    `config:"idempotency"`
+	Webhooks       Webhooks       `config:"webhooks"`
+	RatingsStream  RatingsStream  `config:"ratings_stream"`
+	Health         Health         `config:"health"`
+	Shutdown       Shutdown       `config:"shutdown"`
+	Tracing        Tracing        `config:"tracing"`
+	RateLimit      RateLimit      `config:"rate_limit"`
+	RequestTimeout RequestTimeout `config:"request_timeout"`
+	Rating         Rating         `config:"rating"`
+	FeatureFlags   FeatureFlags   `config:"feature_flags"`
+}
+
+type Store struct {
+	// PersonsTTL - how long the persons and the persons lists are kept in redis
+	PersonsTTL time.Duration `config:"persons_ttl_seconds"`
+}
+
+type Worker struct {
+	Cron        string `config:"cron"`
+	Concurrency int    `config:"concurrency"`
+}
+
+type Events struct {
+	Queue      string `config:"queue"`
+	RoutingKey string `config:"routing_key"`
+}
+
+type Auth struct {
+	ExemptPaths   []string            `config:"exempt_paths"`
+	MaxSkew       time.Duration       `config:"max_skew_seconds"`
+	ClientRoles   map[string][]string `config:"client_roles"`
+	ClientSecrets map[string]string   `config:"client_secrets" secret:"true"`
+	MaxBodyBytes  int64               `config:"max_body_bytes"`
+}
+
+type Idempotency struct {
+	TTL  time.Duration `config:"ttl_seconds"`
+	Lock time.Duration `config:"lock_seconds"`
+}
+
+type Webhooks struct {
+	PollInterval time.Duration `config:"poll_interval_seconds"`
+	Timeout      time.Duration `config:"timeout_seconds"`
+	BatchSize    int           `config:"batch_size"`
+	MaxAttempts  int64         `config:"max_attempts"`
+	BaseBackoff  time.Duration `config:"base_backoff_seconds"`
+	MaxBackoff   time.Duration `config:"max_backoff_seconds"`
+	DisableAfter int64         `config:"disable_after_failures"`
+	// AllowedHosts - the subscriber hosts that may resolve to private or loopback addresses
+	AllowedHosts []string `config:"allowed_hosts"`
+}
+
+type RatingsStream struct {
+	BufferSize  int64         `config:"buffer_size"`
+	TTL         time.Duration `config:"ttl_seconds"`
+	MaxDuration time.Duration `config:"max_duration_seconds"`
+}
+
+type Health struct {
+	OrdersTimeout      time.Duration `config:"orders_timeout_seconds"`
+	RatingsCacheMaxAge time.Duration `config:"ratings_cache_max_age_seconds"`
+	MigrationsDir      string        `config:"migrations_dir"`
+	MigrationsTable    string        `config:"migrations_table"`
+}
+
+type Shutdown struct {
+	DrainTimeout time.Duration `config:"drain_timeout_seconds"`
+	HTTPTimeout  time.Duration `config:"http_timeout_seconds"`
+	CloseTimeout time.Duration `config:"close_timeout_seconds"`
+}
+
+type Tracing struct {
+	Exporter string `config:"exporter"`
+}
+
+type RateLimit struct {
+	Enabled         bool             `config:"enabled"`
+	Capacity        int64            `config:"capacity"`
+	RefillPerSecond float64          `config:"refill_per_second"`
+	DefaultCost     int64            `config:"default_cost"`
+	Costs           map[string]int64 `config:"costs"`
+}
+
+type RequestTimeout struct {
+	Default time.Duration            `config:"default_seconds"`
+	Routes  map[string]time.Duration `config:"routes"`
+}
+
+type Rating struct {
+	Strategy         string        `config:"strategy"`
+	CacheTTL         time.Duration `config:"cache_ttl_seconds"`
+	BatchConcurrency int           `config:"batch_concurrency"`
+	Bayesian         Bayesian      `config:"bayesian"`
+	TimeDecay        TimeDecay     `config:"time_decay"`
+}
+
+type Bayesian struct {
+	Prior    float64 `config:"prior"`
+	MinCount float64 `config:"min_count"`
+}
+
+type TimeDecay struct {
+	HalfLifeDays float64 `config:"half_life_days"`
+}
+
+type FeatureFlags struct {
+	// Refresh - how long each replica keeps the redis overrides before reading them again
+	Refresh time.Duration   `config:"refresh_seconds"`
+	Flags   map[string]Flag `config:"flags"`
+}
+
+// Flag - a behavior rolled out by market, caller and percentage
+type Flag struct {
+	Enabled bool `config:"enabled" json:"enabled"`
+	// Markets - the markets the flag is on in, every market when empty
+	Markets []string `config:"markets" json:"markets"`
+	// Callers - the callers the flag is on for whatever the percentage
+	Callers []string `config:"callers" json:"callers"`
+	// Percentage - the share of the other callers the flag is on for, 0 to 100
+	Percentage float64 `config:"percentage" json:"percentage"`
+}
+
+// Normalized - the markets upper cased, the way the requests name them
+func (f Flag) Normalized() Flag {
+	markets := make([]string, 0, len(f.Markets))
+	for _, market := range f.Markets {
+		markets = append(markets, strings.ToUpper(market))
+	}
+	f.Markets = markets
+
+	return f
+}
+
+// Defaults - the settings of the keys missing from the configuration
+func Defaults() Config {
+	return Config{
+		Markets: []string{"IL"},
+		Person: Person{
+			Market:    "IL",
+			OrdersURL: "http://localhost:8081",
+			Store:     Store{PersonsTTL: 25 * time.Hour},
+			Worker:    Worker{Cron: "*/1 * * * *", Concurrency: 200},
+			Events:    Events{Queue: "orders.update_rating_queue", RoutingKey: "orders.update_rating"},
+			Auth: Auth{
+				ExemptPaths:   []string{"/alive", "/ready", "/metrics", "/debug/pprof/"},
+				MaxSkew:       5 * time.Minute,
+				ClientRoles:   map[string][]string{},
+				ClientSecrets: map[string]string{},
+				MaxBodyBytes:  1 << 20,
+			},
+			Idempotency: Idempotency{TTL: 24 * time.Hour, Lock: time.Minute},
+			Webhooks: Webhooks{
+				PollInterval: 2 * time.Second,
+				Timeout:      5 * time.Second,
+				BatchSize:    50,
+				MaxAttempts:  8,
+				BaseBackoff:  10 * time.Second,
+				MaxBackoff:   time.Hour,
+				DisableAfter: 20,
+				AllowedHosts: []string{},
+			},
+			RatingsStream: RatingsStream{BufferSize: 1000, TTL: time.Hour, MaxDuration: 10 * time.Second},
+			Health: Health{
+				OrdersTimeout:      time.Second,
+				RatingsCacheMaxAge: 5 * time.Minute,
+				MigrationsDir:      "config/migrations",
+				MigrationsTable:    "goose_db_version",
+			},
+			Shutdown:       Shutdown{DrainTimeout: 10 * time.Second, HTTPTimeout: 10 * time.Second, CloseTimeout: 2 * time.Second},
+			Tracing:        Tracing{Exporter: ExporterNone},
+			RateLimit:      RateLimit{Capacity: 100, RefillPerSecond: 10, DefaultCost: 1, Costs: map[string]int64{}},
+			RequestTimeout: RequestTimeout{Default: 10 * time.Second, Routes: map[string]time.Duration{}},
+			Rating: Rating{
+				Strategy:         "mean",
+				CacheTTL:         time.Minute,
+				BatchConcurrency: 10,
+				Bayesian:         Bayesian{Prior: 4, MinCount: 10},
+				TimeDecay:        TimeDecay{HalfLifeDays: 90},
+			},
+			FeatureFlags: FeatureFlags{Refresh: 5 * time.Second, Flags: map[string]Flag{}},
+		},
+	}
+}
+
+var current atomic.Value
+
+// Current - the loaded settings, the defaults until InitFromConfig ran
+func Current() Config {
+	if c, ok := current.Load().(Config); ok {
+		return c
+	}
+
+	return Defaults()
+}
+
+// Set - replace the current settings, used by InitFromConfig and the tests
+func Set(c Config) {
+	current.Store(c)
+}
+
+// InitFromConfig - load and validate the settings read by gettConfig, an invalid configuration stops the service
+func InitFromConfig() {
+	c, err := Load(gettConfig.Settings.GlobalSettings)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	Set(c)
+}
@@ -0,0 +1,13 @@
+package config
+
+import (
+	"github.com/unrolled/render"
+	"net/http"
+)
+
+var handlerRender = render.New()
+
+// Handler - the current settings with the secrets redacted, for the admins
+func Handler(w http.ResponseWriter, req *http.Request) {
+	handlerRender.JSON(w, http.StatusOK, Current().Redacted())
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"github.com/gtforge/global_services_common_go/gett-config"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load - the defaults overridden by the keys set in the settings or the environment, validated
+func Load(settings *gettConfig.Config) (Config, error) {
+	c := Defaults()
+	if settings == nil {
+		return c, c.Validate()
+	}
+
+	load(settings, "", reflect.ValueOf(&c).Elem())
+	if markets := settings.GetStringMap("global.env"); len(markets) > 0 {
+		c.Markets = make([]string, 0, len(markets))
+		for market := range markets {
+			c.Markets = append(c.Markets, strings.ToUpper(market))
+		}
+		sort.Strings(c.Markets)
+	}
+	c.Person.Market = strings.ToUpper(c.Person.Market)
+	for name, flag := range c.Person.FeatureFlags.Flags {
+		c.Person.FeatureFlags.Flags[name] = flag.Normalized()
+	}
+
+	return c, c.Validate()
+}
+
+// load - set the fields whose key is set, the key of a field is the key of its struct followed by its config tag
+func load(settings *gettConfig.Config, prefix string, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		tag := value.Type().Field(i).Tag.Get("config")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := prefix + tag
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			load(settings, key+".", field)
+			continue
+		}
+		if !settings.IsSet(key) {
+			continue
+		}
+
+		if field.Kind() == reflect.Map {
+			// a configured map replaces the default one, so entries can be removed
+			entries := reflect.MakeMap(field.Type())
+			for entry := range settings.GetStringMap(key) {
+				element := reflect.New(field.Type().Elem()).Elem()
+				if element.Kind() == reflect.Struct {
+					load(settings, key+"."+entry+".", element)
+				} else {
+					setValue(settings, key+"."+entry, element)
+				}
+				entries.SetMapIndex(reflect.ValueOf(entry), element)
+			}
+			field.Set(entries)
+			continue
+		}
+
+		setValue(settings, key, field)
+	}
+}
+
+func setValue(settings *gettConfig.Config, key string, field reflect.Value) {
+	if field.Type() == durationType {
+		field.SetInt(int64(settings.GetFloat64(key) * float64(time.Second)))
+		return
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(settings.GetString(key))
+	case reflect.Bool:
+		field.SetBool(settings.GetBool(key))
+	case reflect.Int, reflect.Int64:
+		field.SetInt(settings.GetInt64(key))
+	case reflect.Float64:
+		field.SetFloat(settings.GetFloat64(key))
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(settings.GetStringSlice(key)))
+	}
+}
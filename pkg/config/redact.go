@@ -0,0 +1,62 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted - the settings by their configuration keys, secrets and the passwords of urls are replaced
+func (c Config) Redacted() map[string]interface{} {
+	result := redact(reflect.ValueOf(c))
+	result["markets"] = c.Markets
+	return result
+}
+
+func redact(value reflect.Value) map[string]interface{} {
+	result := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("config")
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		switch {
+		case field.Tag.Get("secret") == "true":
+			result[tag] = redacted
+		case value.Field(i).Kind() == reflect.Struct:
+			result[tag] = redact(value.Field(i))
+		case field.Type == durationType:
+			result[tag] = value.Field(i).Interface().(time.Duration).Seconds()
+		case field.Type.Kind() == reflect.String:
+			result[tag] = redactURL(value.Field(i).String())
+		case field.Type.Kind() == reflect.Map && field.Type.Elem() == durationType:
+			seconds := map[string]float64{}
+			for _, key := range value.Field(i).MapKeys() {
+				seconds[key.String()] = value.Field(i).MapIndex(key).Interface().(time.Duration).Seconds()
+			}
+			result[tag] = seconds
+		default:
+			result[tag] = value.Field(i).Interface()
+		}
+	}
+
+	return result
+}
+
+// redactURL - hide the password of a url, other values are left as is
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	if _, ok := u.User.Password(); !ok {
+		return value
+	}
+
+	u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	return u.String()
+}
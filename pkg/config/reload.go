@@ -0,0 +1,301 @@
+package config
+
+import (
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/gtforge/global_services_common_go/gett-config"
+	"github.com/sirupsen/logrus"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	settingsDir     = "settings"
+	environmentsDir = "environments"
+
+	// configMapData - the symlink kubernetes swaps when a mounted config map changes, the files themselves don't change
+	configMapData = "..data"
+
+	defaultDebounce = 500 * time.Millisecond
+)
+
+// restartKeys - the settings read once on startup, their changes are applied by the next restart
+var restartKeys = []string{
+	"global.auth.", "markets", "person.auth.", "person.events.", "person.worker.", "person.idempotency.",
+	"person.webhooks.", "person.health.", "person.shutdown.", "person.tracing.",
+}
+
+var (
+	reloadMu sync.Mutex
+	appliers []func(Config)
+)
+
+// OnReload - hand the reloaded settings to a running component that copied them on startup
+func OnReload(apply func(Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	appliers = append(appliers, apply)
+}
+
+// Apply - make c the current settings and hand it to the components, returns the changes, nothing is done without any.
+// the settings read once on startup keep their current values until the restart, so Current tells what is applied
+func Apply(c Config) []string {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	current := Current()
+	changes := Diff(current, c)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	applied := c
+	keepRestartKeys("", reflect.ValueOf(current), reflect.ValueOf(&applied).Elem())
+	if len(Diff(current, applied)) == 0 {
+		return changes
+	}
+
+	Set(applied)
+	for _, apply := range appliers {
+		apply(applied)
+	}
+
+	return changes
+}
+
+// keepRestartKeys - copy the settings applied on restart from the current settings into the reloaded ones
+func keepRestartKeys(prefix string, current, reloaded reflect.Value) {
+	for i := 0; i < current.NumField(); i++ {
+		field := current.Type().Field(i)
+		key := prefix + field.Tag.Get("config")
+		if field.Tag.Get("config") == "-" {
+			key = prefix + strings.ToLower(field.Name)
+		}
+
+		switch {
+		case restartRequired(key) || restartRequired(key+"."):
+			reloaded.Field(i).Set(current.Field(i))
+		case current.Field(i).Kind() == reflect.Struct:
+			keepRestartKeys(key+".", current.Field(i), reloaded.Field(i))
+		}
+	}
+}
+
+// Diff - the changed keys with their old and new values, secrets are reported without their values
+func Diff(from, to Config) []string {
+	before, after := map[string]setting{}, map[string]setting{}
+	flatten("", reflect.ValueOf(from), before)
+	flatten("", reflect.ValueOf(to), after)
+
+	keys := make([]string, 0, len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	changes := []string{}
+	for _, key := range keys {
+		old, ok := before[key]
+		if !ok {
+			old.value = "<none>"
+		}
+		value, ok := after[key]
+		if !ok {
+			value.value = "<none>"
+		}
+		if reflect.DeepEqual(old.value, value.value) {
+			continue
+		}
+
+		change := fmt.Sprintf("%s: %v -> %v", key, old.value, value.value)
+		if old.secret || value.secret {
+			change = key + ": changed"
+		}
+		if restartRequired(key) {
+			change += " (applied on restart)"
+		}
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+type setting struct {
+	value  interface{}
+	secret bool
+}
+
+// flatten - the settings by their full key, the entries of the maps get a key of their own
+func flatten(prefix string, value reflect.Value, out map[string]setting) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		key := prefix + field.Tag.Get("config")
+		if field.Tag.Get("config") == "-" {
+			key = prefix + strings.ToLower(field.Name)
+		}
+		secret := field.Tag.Get("secret") == "true"
+
+		switch {
+		case value.Field(i).Kind() == reflect.Struct:
+			flatten(key+".", value.Field(i), out)
+		case value.Field(i).Kind() == reflect.Map:
+			for _, entry := range value.Field(i).MapKeys() {
+				element := value.Field(i).MapIndex(entry)
+				if element.Kind() == reflect.Struct {
+					flatten(key+"."+entry.String()+".", element, out)
+					continue
+				}
+				out[key+"."+entry.String()] = setting{value: plain(element), secret: secret}
+			}
+		default:
+			out[key] = setting{value: plain(value.Field(i)), secret: secret}
+		}
+	}
+}
+
+// plain - durations as seconds, the unit of the configuration keys
+func plain(value reflect.Value) interface{} {
+	if value.Type() == durationType {
+		return value.Interface().(time.Duration).Seconds()
+	}
+
+	return value.Interface()
+}
+
+func restartRequired(key string) bool {
+	for _, prefix := range restartKeys {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reloader - watches the settings files and applies their valid changes to the running service
+type Reloader struct {
+	// dir - the config directory, holding settings/base.yml and environments/<env>.yml
+	dir    string
+	appEnv string
+	// debounce - editors and config map updates write in several steps, the reload waits for them to settle
+	debounce time.Duration
+}
+
+func NewReloader(dir, appEnv string, debounce time.Duration) *Reloader {
+	return &Reloader{
+		dir:      dir,
+		appEnv:   appEnv,
+		debounce: debounce,
+	}
+}
+
+// NewReloaderFromConfig - the config directory gettConfig read the settings from on startup
+func NewReloaderFromConfig(appConfig gettConfig.AppConfig) *Reloader {
+	dir := os.Getenv("APP_CONF_PATH")
+	if dir == "" {
+		dir = "config"
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			dir = "conf"
+		}
+	}
+
+	return NewReloader(dir, appConfig.AppEnv, defaultDebounce)
+}
+
+// Read - base.yml merged with the environment file, the way gettConfig reads them on startup
+func (r *Reloader) Read() (*gettConfig.Config, error) {
+	settings := gettConfig.NewConfigWithEnvPrefix(filepath.Join(r.dir, settingsDir), "base", "SETTINGS")
+	if err := settings.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	environment := filepath.Join(r.dir, environmentsDir, r.appEnv+".yml")
+	if _, err := os.Stat(environment); err == nil {
+		settings.SetConfigFile(environment)
+		if err := settings.MergeInConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	return settings, nil
+}
+
+// Reload - read and validate the settings and apply them, a rejected reload leaves the current settings active
+func (r *Reloader) Reload() error {
+	settings, err := r.Read()
+	if err != nil {
+		logrus.Error("configuration reload rejected, the active configuration is kept: ", err)
+		return err
+	}
+	c, err := Load(settings)
+	if err != nil {
+		logrus.Error("configuration reload rejected, the active configuration is kept: ", err)
+		return err
+	}
+
+	changes := Apply(c)
+	if len(changes) > 0 {
+		// a warning, so the audit line is kept at the production log level
+		logrus.WithField("changes", changes).Warn("configuration reloaded: ", strings.Join(changes, "; "))
+	}
+
+	return nil
+}
+
+// Run - reload once the settings files changed, until stop is closed
+func (r *Reloader) Run(stop <-chan struct{}) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	// the directories are watched rather than the files, editors and config maps replace the files
+	for _, dir := range []string{filepath.Join(r.dir, settingsDir), filepath.Join(r.dir, environmentsDir)} {
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			return err
+		}
+	}
+
+	var settled <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if r.watched(event.Name) {
+				settled = time.After(r.debounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.Error("configuration watcher failed ", err)
+		case <-settled:
+			settled = nil
+			r.Reload()
+		}
+	}
+}
+
+func (r *Reloader) watched(name string) bool {
+	base := filepath.Base(name)
+	return base == "base.yml" || base == r.appEnv+".yml" || base == configMapData
+}
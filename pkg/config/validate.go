@@ -0,0 +1,172 @@
+package config
+
+import (
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	ExporterNone = "none"
+	ExporterLog  = "log"
+
+	// serverWriteTimeout - the write timeout of the http server, the responses must end before it
+	serverWriteTimeout = 15 * time.Second
+)
+
+var (
+	ratingStrategies = []string{"mean", "bayesian", "time_decay"}
+	identifier       = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+)
+
+// ValidationError - every invalid key of the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, key, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, key+" "+fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) positive(key string, value float64) {
+	v.check(value > 0, key, "must be positive, got %v", value)
+}
+
+func (v *validator) positiveDuration(key string, value time.Duration) {
+	v.check(value > 0, key, "must be positive, got %v", value.Seconds())
+}
+
+func (v *validator) required(key, value string) {
+	v.check(value != "", key, "is required")
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, key, "must be one of %v, got %q", strings.Join(allowed, " | "), value)
+}
+
+func (v *validator) flag(key string, flag Flag, markets []string) {
+	v.check(flag.Percentage >= 0 && flag.Percentage <= 100, key+".percentage", "must be between 0 and 100, got %v", flag.Percentage)
+	for _, market := range flag.Markets {
+		v.oneOf(key+".markets", market, markets...)
+	}
+}
+
+// ValidateFlag - the rules of a flag set at runtime, checked the way the configured flags are
+func (c Config) ValidateFlag(name string, flag Flag) error {
+	v := &validator{}
+	v.flag(name, flag.Normalized(), c.Markets)
+	if len(v.problems) > 0 {
+		return ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+// Validate - a ValidationError listing the invalid keys, nil when the configuration is valid
+func (c Config) Validate() error {
+	v := &validator{}
+	p := c.Person
+
+	if c.Auth.Simple {
+		v.required("global.auth.shared_secret_key", c.Auth.SharedSecretKey)
+	}
+
+	v.oneOf("person.market", p.Market, c.Markets...)
+	orders, err := url.Parse(p.OrdersURL)
+	v.check(err == nil && (orders.Scheme == "http" || orders.Scheme == "https") && orders.Host != "",
+		"person.orders_url", "must be an http(s) url, got %q", redactURL(p.OrdersURL))
+
+	if p.LogLevel != "" {
+		_, err := logrus.ParseLevel(p.LogLevel)
+		v.check(err == nil, "person.log_level", "must be a log level (debug, info, warn, error...), got %q", p.LogLevel)
+	}
+
+	v.positiveDuration("person.store.persons_ttl_seconds", p.Store.PersonsTTL)
+	fields := len(strings.Fields(p.Worker.Cron))
+	v.check(fields == 5 || fields == 6, "person.worker.cron", "must be a cron line of 5 or 6 fields, got %q", p.Worker.Cron)
+	v.positive("person.worker.concurrency", float64(p.Worker.Concurrency))
+	v.required("person.events.queue", p.Events.Queue)
+	v.required("person.events.routing_key", p.Events.RoutingKey)
+
+	v.positiveDuration("person.auth.max_skew_seconds", p.Auth.MaxSkew)
+	v.positive("person.auth.max_body_bytes", float64(p.Auth.MaxBodyBytes))
+	v.positiveDuration("person.idempotency.ttl_seconds", p.Idempotency.TTL)
+	v.positiveDuration("person.idempotency.lock_seconds", p.Idempotency.Lock)
+
+	v.positiveDuration("person.webhooks.poll_interval_seconds", p.Webhooks.PollInterval)
+	v.positiveDuration("person.webhooks.timeout_seconds", p.Webhooks.Timeout)
+	v.positive("person.webhooks.batch_size", float64(p.Webhooks.BatchSize))
+	v.positive("person.webhooks.max_attempts", float64(p.Webhooks.MaxAttempts))
+	v.positiveDuration("person.webhooks.base_backoff_seconds", p.Webhooks.BaseBackoff)
+	v.check(p.Webhooks.MaxBackoff >= p.Webhooks.BaseBackoff, "person.webhooks.max_backoff_seconds",
+		"must not be below base_backoff_seconds, got %v", p.Webhooks.MaxBackoff.Seconds())
+	v.positive("person.webhooks.disable_after_failures", float64(p.Webhooks.DisableAfter))
+
+	v.positive("person.ratings_stream.buffer_size", float64(p.RatingsStream.BufferSize))
+	v.positiveDuration("person.ratings_stream.ttl_seconds", p.RatingsStream.TTL)
+	v.check(p.RatingsStream.MaxDuration > 0 && p.RatingsStream.MaxDuration < serverWriteTimeout, "person.ratings_stream.max_duration_seconds",
+		"must be positive and below the %v server write timeout, got %v", serverWriteTimeout.Seconds(), p.RatingsStream.MaxDuration.Seconds())
+
+	v.positiveDuration("person.health.orders_timeout_seconds", p.Health.OrdersTimeout)
+	v.positiveDuration("person.health.ratings_cache_max_age_seconds", p.Health.RatingsCacheMaxAge)
+	v.required("person.health.migrations_dir", p.Health.MigrationsDir)
+	v.check(identifier.MatchString(p.Health.MigrationsTable), "person.health.migrations_table", "must be a table name, got %q", p.Health.MigrationsTable)
+
+	v.positiveDuration("person.shutdown.drain_timeout_seconds", p.Shutdown.DrainTimeout)
+	v.positiveDuration("person.shutdown.http_timeout_seconds", p.Shutdown.HTTPTimeout)
+	v.positiveDuration("person.shutdown.close_timeout_seconds", p.Shutdown.CloseTimeout)
+
+	v.oneOf("person.tracing.exporter", p.Tracing.Exporter, ExporterNone, ExporterLog)
+
+	if p.RateLimit.Enabled {
+		v.positive("person.rate_limit.capacity", float64(p.RateLimit.Capacity))
+		v.positive("person.rate_limit.refill_per_second", p.RateLimit.RefillPerSecond)
+	}
+	v.positive("person.rate_limit.default_cost", float64(p.RateLimit.DefaultCost))
+	for route, cost := range p.RateLimit.Costs {
+		v.check(cost >= 0, "person.rate_limit.costs."+route, "must not be negative, got %v", cost)
+	}
+
+	v.positiveDuration("person.request_timeout.default_seconds", p.RequestTimeout.Default)
+	for route, timeout := range p.RequestTimeout.Routes {
+		v.check(timeout >= 0, "person.request_timeout.routes."+route, "must not be negative, got %v", timeout.Seconds())
+	}
+
+	v.oneOf("person.rating.strategy", p.Rating.Strategy, ratingStrategies...)
+	v.positiveDuration("person.rating.cache_ttl_seconds", p.Rating.CacheTTL)
+	v.positive("person.rating.batch_concurrency", float64(p.Rating.BatchConcurrency))
+	v.positive("person.rating.bayesian.prior", p.Rating.Bayesian.Prior)
+	v.positive("person.rating.bayesian.min_count", p.Rating.Bayesian.MinCount)
+	v.positive("person.rating.time_decay.half_life_days", p.Rating.TimeDecay.HalfLifeDays)
+
+	v.positiveDuration("person.feature_flags.refresh_seconds", p.FeatureFlags.Refresh)
+	for name, flag := range p.FeatureFlags.Flags {
+		v.flag("person.feature_flags.flags."+name, flag, c.Markets)
+	}
+
+	if len(v.problems) > 0 {
+		sort.Strings(v.problems)
+		return ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
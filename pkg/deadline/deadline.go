@@ -0,0 +1,87 @@
+package deadline
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type Deadlines struct {
+	// timeouts - the current timeouts, replaced as a whole when the settings are reloaded
+	timeouts atomic.Value
+}
+
+type timeouts struct {
+	defaultTimeout time.Duration
+	// routes - the timeout of the routes whose path template ends with the key, zero serves the route without a deadline
+	routes map[string]time.Duration
+}
+
+func NewDeadlines(defaultTimeout time.Duration, routes map[string]time.Duration) *Deadlines {
+	d := &Deadlines{}
+	d.Update(defaultTimeout, routes)
+	return d
+}
+
+// NewDeadlinesFromConfig - person.request_timeout.default_seconds bounds every request and
+// person.request_timeout.routes overrides it for the routes whose path ends with the key
+func NewDeadlinesFromConfig(settings config.Config) *Deadlines {
+	return NewDeadlines(settings.Person.RequestTimeout.Default, settings.Person.RequestTimeout.Routes)
+}
+
+// Update - replace the timeouts, the requests in flight keep the deadline they started with
+func (d *Deadlines) Update(defaultTimeout time.Duration, routes map[string]time.Duration) {
+	d.timeouts.Store(timeouts{defaultTimeout: defaultTimeout, routes: routes})
+}
+
+// Reload - apply person.request_timeout of reloaded settings
+func (d *Deadlines) Reload(settings config.Config) {
+	d.Update(settings.Person.RequestTimeout.Default, settings.Person.RequestTimeout.Routes)
+}
+
+// Middleware - put the route deadline on the request context, the service, the repository and the orders calls give up
+// once it passes
+func (d *Deadlines) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		timeout := d.timeouts.Load().(timeouts).routeTimeout(req)
+		if timeout <= 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (t timeouts) routeTimeout(req *http.Request) time.Duration {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return t.defaultTimeout
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return t.defaultTimeout
+	}
+
+	return t.timeout(template)
+}
+
+// timeout - the timeout of the longest key the route template or the gRPC method ends with, so /ratings/stream is not
+// bounded as /stream
+func (t timeouts) timeout(name string) time.Duration {
+	timeout, matched := t.defaultTimeout, ""
+	for suffix, routeTimeout := range t.routes {
+		if strings.HasSuffix(name, suffix) && len(suffix) > len(matched) {
+			timeout, matched = routeTimeout, suffix
+		}
+	}
+
+	return timeout
+}
@@ -0,0 +1,107 @@
+package deadline
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadline(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "deadline test")
+}
+
+var _ = ginkgo.Describe("request deadlines", func() {
+
+	var (
+		router    *mux.Router
+		deadlines *Deadlines
+		deadline  time.Time
+		bounded   bool
+	)
+
+	serve := func(path string) {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	ginkgo.BeforeEach(func() {
+		deadlines = NewDeadlines(time.Second, map[string]time.Duration{"/ratings": time.Minute, "/ratings/stream": 0})
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			deadline, bounded = r.Context().Deadline()
+		}
+
+		router = mux.NewRouter()
+		router.Use(deadlines.Middleware)
+		router.HandleFunc("/persons", handler)
+		router.HandleFunc("/persons/{id}/ratings", handler)
+		router.HandleFunc("/ratings/stream", handler)
+	})
+
+	ginkgo.Context("validate that requests are bounded by the default timeout", func() {
+
+		ginkgo.It("do", func() {
+			serve("/persons")
+			gomega.Expect(bounded).To(gomega.BeTrue())
+			gomega.Expect(time.Until(deadline)).To(gomega.BeNumerically("<=", time.Second))
+		})
+	})
+
+	ginkgo.Context("validate that the route timeout overrides the default one", func() {
+
+		ginkgo.It("do", func() {
+			serve("/persons/1/ratings")
+			gomega.Expect(bounded).To(gomega.BeTrue())
+			gomega.Expect(time.Until(deadline)).To(gomega.BeNumerically(">", time.Second))
+		})
+	})
+
+	ginkgo.Context("validate that a zero timeout serves the route without a deadline", func() {
+
+		ginkgo.It("do", func() {
+			serve("/ratings/stream")
+			gomega.Expect(bounded).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that updated timeouts bound the following requests", func() {
+
+		ginkgo.It("do", func() {
+			deadlines.Update(time.Minute, map[string]time.Duration{})
+
+			serve("/persons")
+			gomega.Expect(time.Until(deadline)).To(gomega.BeNumerically(">", time.Second))
+			serve("/ratings/stream")
+			gomega.Expect(bounded).To(gomega.BeTrue())
+		})
+	})
+
+	ginkgo.Context("validate that the gRPC calls are bounded by the timeout of their method", func() {
+
+		ginkgo.It("do", func() {
+			deadlines.Update(time.Second, map[string]time.Duration{"PersonService/GetRatings": time.Minute, "PersonService/SearchPersons": 0})
+			call := func(method string) {
+				deadlines.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+					func(ctx context.Context, req interface{}) (interface{}, error) {
+						deadline, bounded = ctx.Deadline()
+						return nil, nil
+					})
+			}
+
+			call("/person.v1.PersonService/GetPerson")
+			gomega.Expect(bounded).To(gomega.BeTrue())
+			gomega.Expect(time.Until(deadline)).To(gomega.BeNumerically("<=", time.Second))
+
+			call("/person.v1.PersonService/GetRatings")
+			gomega.Expect(time.Until(deadline)).To(gomega.BeNumerically(">", time.Second))
+
+			call("/person.v1.PersonService/SearchPersons")
+			gomega.Expect(bounded).To(gomega.BeFalse())
+		})
+	})
+})
@@ -0,0 +1,21 @@
+package deadline
+
+import (
+	"context"
+	"google.golang.org/grpc"
+)
+
+// UnaryInterceptor - put the method deadline on the call context, the timeouts are matched against the full method
+// name, e.g. PersonService/GetRatings. a shorter deadline set by the client is kept
+func (d *Deadlines) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	timeout := d.timeouts.Load().(timeouts).timeout(info.FullMethod)
+	if timeout <= 0 {
+		return handler(ctx, req)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return handler(ctx, req)
+}
@@ -1,15 +1,31 @@
 package events
 
 import (
+	"context"
+	"errors"
 	"github.com/gtforge/global_services_common_go/gett-mq"
 	"github.com/gtforge/global_services_common_go/gett-mq/consumer"
-	"github.com/gtforge/gorm"
+	"github.com/gtforge/go-healthcheck"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/lifecycle"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
 	"github.com/sirupsen/logrus"
+	"sync"
+	"sync/atomic"
 )
 
-const (
-	routingKey = "orders.update_rating"
-	queueName = "orders.update_rating_queue"
+var (
+	ErrNotConnected  = errors.New("rabbitmq publisher is not connected")
+	ErrNotSubscribed = errors.New("rabbitmq consumer is not subscribed")
+	ErrStopped       = errors.New("rabbitmq consumer is stopped, the message is requeued")
+
+	// subscribed - set once the consumer subscribed to its queue, cleared by Stop
+	subscribed int32
+
+	// stopped guards processing, so no message starts once Stop began to wait for the ones in process
+	mu         sync.Mutex
+	stopped    bool
+	processing sync.WaitGroup
 )
 
 type Events interface {
@@ -20,20 +36,71 @@ type Consume struct {
 	RabbitMQ *gettMQ.AMQPConnection
 }
 
-func InitConsumer(db *gorm.DB) {
-	ConsumeEvent(db)
+func InitConsumer(service person.Service) {
+	ConsumeEvent(service)
 }
 
-func ConsumeEvent(db *gorm.DB) error{
+func ConsumeEvent(service person.Service) error{
 
-	err := consumer.Subscribe(queueName, routingKey, GetConsumer(db))
+	options := config.Current().Person.Events
+	err := consumer.Subscribe(options.Queue, options.RoutingKey, GetConsumer(service))
 	if err != nil {
 		logrus.Error("error - unable to sent the event with personID: {} ")
 		return err
 	}
+	atomic.StoreInt32(&subscribed, 1)
 
 	return nil
 }
 
+// Stop - refuse the messages delivered from now on and wait for the ones in process. consumer.Subscribe returns nothing
+// to cancel the subscription with, so the refused messages are nacked and requeued by the broker, for the other
+// replicas or for this one until the drain ends
+func Stop(ctx context.Context) error {
+	mu.Lock()
+	stopped = true
+	mu.Unlock()
+	atomic.StoreInt32(&subscribed, 0)
+
+	return lifecycle.WaitFor(ctx, processing.Wait)
+}
+
+// begin - count a message in process, false once the consumer is stopped
+func begin() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if stopped {
+		return false
+	}
+	processing.Add(1)
+
+	return true
+}
+
+// connected - whether the connection is open, a closed connection also ended the deliveries of the subscription
+func connected(connection *gettMQ.AMQPConnection) bool {
+	return connection != nil && !connection.IsClosed()
+}
+
+// Pinger - the publisher connection and the subscription of the consumer, the subscription is reported down while the
+// connection is closed and once Stop refuses its messages
+func Pinger(connection *gettMQ.AMQPConnection) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		response := map[string]interface{}{"rabbitmq": "OK", "rabbitmq_consumer": "OK"}
+		var err error
+		open := connected(connection)
+		if atomic.LoadInt32(&subscribed) == 0 || !open {
+			response["rabbitmq_consumer"] = ErrNotSubscribed.Error()
+			err = ErrNotSubscribed
+		}
+		if !open {
+			response["rabbitmq"] = ErrNotConnected.Error()
+			err = ErrNotConnected
+		}
+
+		return response, err
+	}
+}
+
 
 
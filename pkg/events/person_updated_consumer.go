@@ -1,24 +1,23 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/gtforge/global_services_common_go/gett-mq"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
 	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
-	"github.com/gtforge/gorm"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
 	"github.com/sirupsen/logrus"
 )
 
-//var PersonUpdatedConsumer = &personUpdatedConsumer{
-//	service: person.NewPersonService(person.NewRepo(gettStorages.DB)),
-//}
-
 type personId struct {
 	ID int64 `json:"id"`
 }
 
-func GetConsumer(db *gorm.DB) *personUpdatedConsumer{
+func GetConsumer(service person.Service) *personUpdatedConsumer{
 	return &personUpdatedConsumer{
-		service: person.NewPersonService(person.NewRepo(db)),
+		service: service,
 	}
 }
 
@@ -27,19 +26,44 @@ type personUpdatedConsumer struct {
 }
 
 func (p personUpdatedConsumer) Process(message gettMQ.MqMessage) error {
+	if !begin() {
+		// gett-mq nacks the refused message and the broker requeues it
+		return ErrStopped
+	}
+	defer processing.Done()
+
+	routingKey := config.Current().Person.Events.RoutingKey
+	span, finish := tracing.StartConsumerSpan(routingKey, message.Headers)
+	defer finish()
+	ctx := context.Background()
+
 	event := personId{}
+	result := metrics.ResultProcessed
 	err := json.Unmarshal(message.Payload, &event)
 	if err != nil {
 		logrus.Error("error - unable to unmarshal the update person event ", err)
+		tracing.Error(span, err)
+		result = metrics.ResultFailed
 	}
+	span.SetTag("person.id", event.ID)
 
 
-	err = p.service.UpdatePersonRating(event.ID, true)
+	err = p.service.UpdatePersonRating(ctx, event.ID, true)
 	if err != nil {
 		logrus.Error("error - unable to update person event ", err)
+		tracing.Error(span, err)
+		result = metrics.ResultFailed
+	}
+	metrics.ConsumerMessages.Inc(routingKey, result)
+
+	updated, err := p.service.GetPersonByID(ctx, event.ID)
+	if err != nil {
+		logrus.Error("error - unable to get the updated person ", err)
+		p.service.AsyncRun(ctx)
+		return nil
 	}
 
-	p.service.AsyncRun()
+	p.service.ForMarket(updated.Market).AsyncRun(ctx)
 
 	return nil
 }
@@ -0,0 +1,271 @@
+package flags
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SourceConfig   = "config"
+	SourceOverride = "override"
+
+	overridesKey = "feature_flags:overrides"
+)
+
+// Store - the flags set through the admin API, they replace the configured rules of the flag on every replica
+type Store interface {
+	Overrides() (map[string]config.Flag, error)
+	SetOverride(name string, flag config.Flag) error
+	DeleteOverride(name string) error
+}
+
+// RedisStore - the overrides as json in a redis hash, by flag name
+type RedisStore struct {
+	client *gettStorages.GtRedisClient
+}
+
+func NewRedisStore(client *gettStorages.GtRedisClient) RedisStore {
+	return RedisStore{client: client}
+}
+
+func (s RedisStore) Overrides() (map[string]config.Flag, error) {
+	values, err := s.client.HGetAll(overridesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]config.Flag, len(values))
+	for name, value := range values {
+		flag := config.Flag{}
+		if err := json.Unmarshal([]byte(value), &flag); err != nil {
+			logrus.Error("skipping the unreadable override of flag ", name, " ", err)
+			continue
+		}
+		overrides[name] = flag
+	}
+
+	return overrides, nil
+}
+
+func (s RedisStore) SetOverride(name string, flag config.Flag) error {
+	bytes, err := json.Marshal(flag)
+	if err != nil {
+		return err
+	}
+
+	return s.client.HSet(overridesKey, name, string(bytes)).Err()
+}
+
+func (s RedisStore) DeleteOverride(name string) error {
+	return s.client.HDel(overridesKey, name).Err()
+}
+
+// MemoryStore - the overrides kept by the process, they only apply to its replica
+type MemoryStore struct {
+	mu        sync.Mutex
+	overrides map[string]config.Flag
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{overrides: map[string]config.Flag{}}
+}
+
+func (s *MemoryStore) Overrides() (map[string]config.Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	overrides := make(map[string]config.Flag, len(s.overrides))
+	for name, flag := range s.overrides {
+		overrides[name] = flag
+	}
+
+	return overrides, nil
+}
+
+func (s *MemoryStore) SetOverride(name string, flag config.Flag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.overrides[name] = flag
+	return nil
+}
+
+func (s *MemoryStore) DeleteOverride(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.overrides, name)
+	return nil
+}
+
+// View - a flag with the rules in effect and where they come from
+type View struct {
+	Name string `json:"name"`
+	config.Flag
+	Source string `json:"source"`
+}
+
+// Flags - evaluates the configured flags with the overrides of the store, each replica keeps the overrides for
+// person.feature_flags.refresh_seconds
+type Flags struct {
+	store Store
+	now   func() time.Time
+
+	mu        sync.Mutex
+	overrides map[string]config.Flag
+	loadedAt  time.Time
+}
+
+func NewFlags(store Store) *Flags {
+	return &Flags{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Instance - the flags of the service, used through Enabled. main sets it up with the store of the service, the
+// overrides are kept by the process until then
+var Instance = NewFlags(NewMemoryStore())
+
+// Enabled - whether the flag is on for the caller of ctx in the market, the helper of the services and the handlers
+func Enabled(ctx context.Context, name, market string) bool {
+	return Instance.Enabled(ctx, name, market)
+}
+
+// Enabled - the flag must be enabled and the market listed, then the listed callers get it and the others by percentage.
+// unknown flags are off
+func (f *Flags) Enabled(ctx context.Context, name, market string) bool {
+	flag, ok := f.flag(name)
+	if !ok || !flag.Enabled {
+		return false
+	}
+	if len(flag.Markets) > 0 && !contains(flag.Markets, strings.ToUpper(market)) {
+		return false
+	}
+
+	caller := callerOf(ctx)
+	if contains(flag.Callers, caller) {
+		return true
+	}
+
+	return bucket(name, caller) < flag.Percentage
+}
+
+// Views - every configured flag with the rules in effect
+func (f *Flags) Views() []View {
+	configured := config.Current().Person.FeatureFlags.Flags
+	views := make([]View, 0, len(configured))
+	for name := range configured {
+		flag, _ := f.flag(name)
+		views = append(views, View{Name: name, Flag: flag, Source: f.source(name)})
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+
+	return views
+}
+
+// Set - override the rules of a configured flag, false when there is no such flag
+func (f *Flags) Set(name string, flag config.Flag) (bool, error) {
+	if _, ok := config.Current().Person.FeatureFlags.Flags[name]; !ok {
+		return false, nil
+	}
+
+	defer f.invalidate()
+	return true, f.store.SetOverride(name, flag.Normalized())
+}
+
+// Reset - drop the override, the configured rules apply again
+func (f *Flags) Reset(name string) error {
+	defer f.invalidate()
+	return f.store.DeleteOverride(name)
+}
+
+// flag - the override of a configured flag, its configured rules otherwise. overrides of flags removed from the
+// configuration are ignored
+func (f *Flags) flag(name string) (config.Flag, bool) {
+	flag, ok := config.Current().Person.FeatureFlags.Flags[name]
+	if !ok {
+		return config.Flag{}, false
+	}
+	if override, ok := f.loadOverrides()[name]; ok {
+		return override, true
+	}
+
+	return flag, true
+}
+
+func (f *Flags) source(name string) string {
+	if _, ok := f.loadOverrides()[name]; ok {
+		return SourceOverride
+	}
+
+	return SourceConfig
+}
+
+// loadOverrides - the kept overrides until they are older than the refresh interval. a failed read keeps the previous
+// ones until the next interval, so redis outages don't slow the requests down
+func (f *Flags) loadOverrides() map[string]config.Flag {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if f.overrides != nil && now.Sub(f.loadedAt) < config.Current().Person.FeatureFlags.Refresh {
+		return f.overrides
+	}
+
+	overrides, err := f.store.Overrides()
+	f.loadedAt = now
+	if err != nil {
+		logrus.Error("unable to read the feature flag overrides ", err)
+		if f.overrides == nil {
+			f.overrides = map[string]config.Flag{}
+		}
+		return f.overrides
+	}
+	f.overrides = overrides
+
+	return f.overrides
+}
+
+func (f *Flags) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.overrides = nil
+}
+
+// callerOf - the subject of the authenticated caller, anonymous callers share one bucket
+func callerOf(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.Subject
+	}
+
+	return ""
+}
+
+// bucket - the stable place of the caller in the rollout of the flag, from 0 to 100. the flag name is hashed too, so
+// the first callers of every flag are not the same ones
+func bucket(name, caller string) float64 {
+	hash := fnv.New32a()
+	hash.Write([]byte(name + ":" + caller))
+	return float64(hash.Sum32()%10000) / 100
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
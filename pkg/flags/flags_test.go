@@ -0,0 +1,178 @@
+package flags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFlags(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "flags test")
+}
+
+type memoryStore struct {
+	overrides map[string]config.Flag
+	reads     int
+	err       error
+}
+
+func (s *memoryStore) Overrides() (map[string]config.Flag, error) {
+	s.reads++
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	overrides := map[string]config.Flag{}
+	for name, flag := range s.overrides {
+		overrides[name] = flag
+	}
+	return overrides, nil
+}
+
+func (s *memoryStore) SetOverride(name string, flag config.Flag) error {
+	s.overrides[name] = flag
+	return nil
+}
+
+func (s *memoryStore) DeleteOverride(name string) error {
+	delete(s.overrides, name)
+	return nil
+}
+
+var _ = ginkgo.Describe("feature flags", func() {
+
+	var (
+		now   time.Time
+		store *memoryStore
+		flags *Flags
+	)
+
+	caller := func(subject string) context.Context {
+		return auth.WithIdentity(context.Background(), auth.Identity{Subject: subject, Method: auth.MethodJWT})
+	}
+
+	ginkgo.BeforeEach(func() {
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		c.Person.FeatureFlags.Flags = map[string]config.Flag{
+			"time_decay_rating": {Enabled: true, Markets: []string{"UK"}, Callers: []string{"backoffice"}, Percentage: 0},
+			"half":              {Enabled: true, Percentage: 50},
+			"off":               {Enabled: false, Percentage: 100},
+		}
+		config.Set(c)
+
+		now = time.Unix(1600000000, 0)
+		store = &memoryStore{overrides: map[string]config.Flag{}}
+		flags = NewFlags(store)
+		flags.now = func() time.Time { return now }
+	})
+
+	ginkgo.AfterEach(func() {
+		config.Set(config.Defaults())
+	})
+
+	ginkgo.Context("validate that the market and caller rules are applied and unknown flags are off", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(flags.Enabled(caller("backoffice"), "time_decay_rating", "uk")).To(gomega.BeTrue())
+			gomega.Expect(flags.Enabled(caller("backoffice"), "time_decay_rating", "IL")).To(gomega.BeFalse())
+			gomega.Expect(flags.Enabled(caller("orders"), "time_decay_rating", "UK")).To(gomega.BeFalse())
+			gomega.Expect(flags.Enabled(caller("backoffice"), "off", "UK")).To(gomega.BeFalse())
+			gomega.Expect(flags.Enabled(caller("backoffice"), "unknown", "UK")).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that the percentage rolls the flag out to a stable share of the callers", func() {
+
+		ginkgo.It("do", func() {
+			on := 0
+			for i := 0; i < 1000; i++ {
+				ctx := caller(fmt.Sprintf("client-%d", i))
+				enabled := flags.Enabled(ctx, "half", "IL")
+				gomega.Expect(flags.Enabled(ctx, "half", "IL")).To(gomega.Equal(enabled))
+				if enabled {
+					on++
+				}
+			}
+			gomega.Expect(on).To(gomega.BeNumerically("~", 500, 60))
+		})
+	})
+
+	ginkgo.Context("validate that an override replaces the configured rules until it is reset", func() {
+
+		ginkgo.It("do", func() {
+			found, err := flags.Set("time_decay_rating", config.Flag{Enabled: true, Percentage: 100})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(found).To(gomega.BeTrue())
+			gomega.Expect(flags.Enabled(caller("orders"), "time_decay_rating", "IL")).To(gomega.BeTrue())
+
+			gomega.Expect(flags.Reset("time_decay_rating")).To(gomega.Succeed())
+			gomega.Expect(flags.Enabled(caller("orders"), "time_decay_rating", "IL")).To(gomega.BeFalse())
+
+			found, err = flags.Set("unknown", config.Flag{Enabled: true})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(found).To(gomega.BeFalse())
+		})
+	})
+
+	ginkgo.Context("validate that the overrides are read once per refresh interval and kept when redis fails", func() {
+
+		ginkgo.It("do", func() {
+			store.overrides["half"] = config.Flag{Enabled: true, Percentage: 100}
+			gomega.Expect(flags.Enabled(caller("orders"), "half", "IL")).To(gomega.BeTrue())
+			gomega.Expect(flags.Enabled(caller("orders"), "half", "IL")).To(gomega.BeTrue())
+			gomega.Expect(store.reads).To(gomega.Equal(1))
+
+			store.err = errors.New("redis is down")
+			now = now.Add(time.Minute)
+			gomega.Expect(flags.Enabled(caller("orders"), "half", "IL")).To(gomega.BeTrue())
+			gomega.Expect(store.reads).To(gomega.Equal(2))
+		})
+	})
+
+	ginkgo.Context("validate that the admin api lists, overrides and resets the flags", func() {
+
+		var router *mux.Router
+
+		serve := func(method, path, body string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: "backoffice", Roles: []string{auth.RoleAdmin}}))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		ginkgo.BeforeEach(func() {
+			router = mux.NewRouter()
+			NewHandler(flags).RegisterRoutes(router)
+		})
+
+		ginkgo.It("do", func() {
+			recorder := serve(http.MethodPut, "/admin/flags/time_decay_rating", `{"enabled":true,"markets":["il"],"percentage":10}`)
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(store.overrides["time_decay_rating"].Markets).To(gomega.Equal([]string{"IL"}))
+
+			recorder = serve(http.MethodGet, "/admin/flags", "")
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(recorder.Body.String()).To(gomega.ContainSubstring(`"name":"time_decay_rating","enabled":true,"markets":["IL"],"callers":null,"percentage":10,"source":"override"`))
+
+			gomega.Expect(serve(http.MethodPut, "/admin/flags/time_decay_rating", `{"enabled":true,"markets":["FR"]}`).Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(serve(http.MethodPut, "/admin/flags/time_decay_rating", `{"percentage":120}`).Code).To(gomega.Equal(http.StatusBadRequest))
+			gomega.Expect(serve(http.MethodPut, "/admin/flags/unknown", `{"enabled":true}`).Code).To(gomega.Equal(http.StatusNotFound))
+
+			gomega.Expect(serve(http.MethodDelete, "/admin/flags/time_decay_rating", "").Code).To(gomega.Equal(http.StatusNoContent))
+			gomega.Expect(store.overrides).NotTo(gomega.HaveKey("time_decay_rating"))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package flags
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"net/http"
+)
+
+var ErrUnknownFlag = errors.New("unknown feature flag, flags are declared under person.feature_flags.flags")
+
+type handler struct {
+	flags  *Flags
+	render *render.Render
+}
+
+func NewHandler(flags *Flags) *handler {
+	return &handler{
+		flags:  flags,
+		render: render.New(),
+	}
+}
+
+// RegisterRoutes - toggling flags needs the admin role
+func (h handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/admin/flags", auth.Require(auth.RoleAdmin, h.GetFlags)).Methods(http.MethodGet)
+	router.HandleFunc("/admin/flags/{name}", auth.Require(auth.RoleAdmin, h.SetFlag)).Methods(http.MethodPut)
+	router.HandleFunc("/admin/flags/{name}", auth.Require(auth.RoleAdmin, h.ResetFlag)).Methods(http.MethodDelete)
+}
+
+func (h handler) GetFlags(w http.ResponseWriter, req *http.Request) {
+	h.render.JSON(w, http.StatusOK, h.flags.Views())
+}
+
+// SetFlag - replace the rules of the flag on every replica until it is reset
+func (h handler) SetFlag(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	name := mux.Vars(req)["name"]
+	flag := config.Flag{}
+	if err := json.NewDecoder(req.Body).Decode(&flag); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := config.Current().ValidateFlag(name, flag); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	found, err := h.flags.Set(name, flag)
+	if err != nil {
+		logrus.Error("couldn't override feature flag ", err)
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if !found {
+		h.renderError(w, http.StatusNotFound, ErrUnknownFlag)
+		return
+	}
+	auth.Logger(req.Context()).WithFields(logrus.Fields{"flag": name, "rules": flag.Normalized()}).Warn("feature flag overridden")
+
+	h.render.JSON(w, http.StatusOK, View{Name: name, Flag: flag.Normalized(), Source: SourceOverride})
+}
+
+// ResetFlag - drop the override, the configured rules apply again
+func (h handler) ResetFlag(w http.ResponseWriter, req *http.Request) {
+	name := mux.Vars(req)["name"]
+	if err := h.flags.Reset(name); err != nil {
+		logrus.Error("couldn't reset feature flag ", err)
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	auth.Logger(req.Context()).WithField("flag", name).Warn("feature flag reset")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) renderError(w http.ResponseWriter, code int, err error) {
+	h.render.JSON(w, code, skeleton.NewAPIError(http.StatusText(code), err))
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Arguments - the argument values of a field with the operation variables substituted
+type Arguments map[string]interface{}
+
+// Arguments - resolve the variables of the field arguments from the request variables and their defaults
+func (o *Operation) Arguments(field *Field, variables map[string]interface{}) (Arguments, error) {
+	arguments := Arguments{}
+	for name, value := range field.Arguments {
+		resolved, err := o.resolve(value, variables)
+		if err != nil {
+			return nil, err
+		}
+		arguments[name] = resolved
+	}
+
+	return arguments, nil
+}
+
+func (o *Operation) resolve(value interface{}, variables map[string]interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case Variable:
+		for _, definition := range o.Variables {
+			if definition.Name != string(v) {
+				continue
+			}
+			if provided, ok := variables[definition.Name]; ok && provided != nil {
+				return provided, nil
+			}
+			if definition.HasDefault {
+				return definition.Default, nil
+			}
+			if strings.HasSuffix(definition.Type, "!") {
+				return nil, fmt.Errorf("variable $%s of type %s is required", definition.Name, definition.Type)
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("variable $%s is not defined", v)
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			resolved, err := o.resolve(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, resolved)
+		}
+		return list, nil
+	case map[string]interface{}:
+		object := map[string]interface{}{}
+		for key, item := range v {
+			resolved, err := o.resolve(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = resolved
+		}
+		return object, nil
+	}
+
+	return value, nil
+}
+
+// Has - whether the argument was given a non null value
+func (a Arguments) Has(name string) bool {
+	return a[name] != nil
+}
+
+// Int - integer variables arrive as float64 from JSON, they are accepted when whole
+func (a Arguments) Int(name string, defaultValue int64) (int64, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return defaultValue, nil
+	case int64:
+		return v, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return int64(v), nil
+		}
+	}
+
+	return 0, fmt.Errorf("argument %s must be an Int", name)
+}
+
+func (a Arguments) String(name string, defaultValue string) (string, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return defaultValue, nil
+	case string:
+		return v, nil
+	}
+
+	return "", fmt.Errorf("argument %s must be a String", name)
+}
+
+func (a Arguments) Bool(name string, defaultValue bool) (bool, error) {
+	switch v := a[name].(type) {
+	case nil:
+		return defaultValue, nil
+	case bool:
+		return v, nil
+	}
+
+	return false, fmt.Errorf("argument %s must be a Boolean", name)
+}
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrNoOperation          = errors.New("the document has no operation")
+	ErrUnknownOperation     = errors.New("unknown operation name")
+	ErrOperationRequired    = errors.New("operationName is required for documents with several operations")
+	ErrUnsupportedOperation = errors.New("only query operations are supported")
+)
+
+type Document struct {
+	Operations []*Operation
+}
+
+type Operation struct {
+	Type       string
+	Name       string
+	Variables  []VariableDefinition
+	Selections []*Field
+}
+
+type VariableDefinition struct {
+	Name       string
+	Type       string
+	Default    interface{}
+	HasDefault bool
+}
+
+// Field - a selected field, Arguments values are literals, []interface{}, map[string]interface{}, Variable or Enum
+type Field struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]interface{}
+	Selections []*Field
+}
+
+// Variable - a reference to an operation variable in an argument value
+type Variable string
+
+type Enum string
+
+// Request - the body of a POST, or the query parameters of a GET
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Response struct {
+	Data   interface{} `json:"data"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// ResponseKey - the alias of the field, or its name
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+// Selects - whether the selection set of the field selects the named field
+func (f *Field) Selects(name string) bool {
+	for _, selection := range f.Selections {
+		if selection.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Operation - the operation to execute, operationName may be empty when the document has a single operation
+func (d *Document) Operation(name string) (*Operation, error) {
+	if len(d.Operations) == 0 {
+		return nil, ErrNoOperation
+	}
+
+	var operation *Operation
+	switch {
+	case name != "":
+		for _, candidate := range d.Operations {
+			if candidate.Name == name {
+				operation = candidate
+			}
+		}
+		if operation == nil {
+			return nil, ErrUnknownOperation
+		}
+	case len(d.Operations) > 1:
+		return nil, ErrOperationRequired
+	default:
+		operation = d.Operations[0]
+	}
+
+	if operation.Type != "query" {
+		return nil, ErrUnsupportedOperation
+	}
+
+	return operation, nil
+}
+
+// Object - a response object keeping its fields in selection order
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func NewObject() *Object {
+	return &Object{values: map[string]interface{}{}}
+}
+
+func (o *Object) Set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *Object) Get(key string) interface{} {
+	return o.values[key]
+}
+
+func (o *Object) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(name)
+		buffer.WriteString(":")
+		buffer.Write(value)
+	}
+	buffer.WriteString("}")
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,509 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+// maxDepth - the deepest nesting of the selection sets, the list and object values and the list types of a document.
+// the parser recurses into them, deeper documents are rejected before they exhaust the stack
+const maxDepth = 32
+
+type token struct {
+	kind     int
+	value    string
+	position int
+}
+
+// SyntaxError - the query is not a valid document of the supported subset of the language
+type SyntaxError struct {
+	Message  string
+	Position int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// Parse - parse the executable subset of the language: operations, variables, aliases, arguments and nested
+// selections. fragments, directives and documents nested deeper than maxDepth are rejected
+func Parse(query string) (*Document, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	document := &Document{}
+	for p.peek().kind != tokenEOF {
+		operation, err := p.parseOperation()
+		if err != nil {
+			return nil, err
+		}
+		document.Operations = append(document.Operations, operation)
+	}
+
+	return document, nil
+}
+
+type parser struct {
+	tokens []token
+	index  int
+	// depth - the nesting of the selection set, value or type being parsed
+	depth int
+}
+
+// enter - go one level deeper at t, leave must be deferred once it succeeded
+func (p *parser) enter(t token) error {
+	if p.depth >= maxDepth {
+		return &SyntaxError{Message: fmt.Sprintf("the document is nested deeper than %d levels", maxDepth), Position: t.position}
+	}
+	p.depth++
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.index]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.index]
+	if t.kind != tokenEOF {
+		p.index++
+	}
+	return t
+}
+
+func (p *parser) isPunctuator(value string) bool {
+	t := p.peek()
+	return t.kind == tokenPunctuator && t.value == value
+}
+
+func (p *parser) expectPunctuator(value string) error {
+	t := p.next()
+	if t.kind != tokenPunctuator || t.value != value {
+		return p.unexpected(t, "expected "+value)
+	}
+	return nil
+}
+
+func (p *parser) expectName() (string, error) {
+	t := p.next()
+	if t.kind != tokenName {
+		return "", p.unexpected(t, "expected a name")
+	}
+	return t.value, nil
+}
+
+func (p *parser) unexpected(t token, message string) error {
+	if t.kind == tokenEOF {
+		return &SyntaxError{Message: message + ", got the end of the document", Position: t.position}
+	}
+	return &SyntaxError{Message: message + ", got " + strconv.Quote(t.value), Position: t.position}
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: "query"}
+	if p.isPunctuator("{") {
+		selections, err := p.parseSelectionSet()
+		operation.Selections = selections
+		return operation, err
+	}
+
+	t := p.next()
+	if t.kind != tokenName || (t.value != "query" && t.value != "mutation" && t.value != "subscription") {
+		return nil, p.unexpected(t, "expected an operation")
+	}
+	operation.Type = t.value
+
+	if p.peek().kind == tokenName {
+		operation.Name = p.next().value
+	}
+	if p.isPunctuator("(") {
+		variables, err := p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		operation.Variables = variables
+	}
+	if p.isPunctuator("@") {
+		return nil, p.unexpected(p.peek(), "directives are not supported")
+	}
+
+	selections, err := p.parseSelectionSet()
+	operation.Selections = selections
+	return operation, err
+}
+
+func (p *parser) parseVariableDefinitions() ([]VariableDefinition, error) {
+	p.next()
+	definitions := make([]VariableDefinition, 0)
+	for !p.isPunctuator(")") {
+		if err := p.expectPunctuator("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.expectName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunctuator(":"); err != nil {
+			return nil, err
+		}
+		typeName, err := p.parseType()
+		if err != nil {
+			return nil, err
+		}
+
+		definition := VariableDefinition{Name: name, Type: typeName}
+		if p.isPunctuator("=") {
+			p.next()
+			value, err := p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+			definition.Default, definition.HasDefault = value, true
+		}
+		definitions = append(definitions, definition)
+	}
+	p.next()
+
+	return definitions, nil
+}
+
+func (p *parser) parseType() (string, error) {
+	var typeName string
+	if p.isPunctuator("[") {
+		if err := p.enter(p.next()); err != nil {
+			return "", err
+		}
+		defer p.leave()
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expectPunctuator("]"); err != nil {
+			return "", err
+		}
+		typeName = "[" + inner + "]"
+	} else {
+		name, err := p.expectName()
+		if err != nil {
+			return "", err
+		}
+		typeName = name
+	}
+
+	if p.isPunctuator("!") {
+		p.next()
+		typeName += "!"
+	}
+
+	return typeName, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*Field, error) {
+	if err := p.enter(p.peek()); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+	if err := p.expectPunctuator("{"); err != nil {
+		return nil, err
+	}
+
+	selections := make([]*Field, 0)
+	for !p.isPunctuator("}") {
+		if p.isPunctuator("...") {
+			return nil, p.unexpected(p.peek(), "fragments are not supported")
+		}
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, field)
+	}
+	p.next()
+
+	if len(selections) == 0 {
+		return nil, p.unexpected(p.peek(), "expected a field")
+	}
+
+	return selections, nil
+}
+
+func (p *parser) parseField() (*Field, error) {
+	name, err := p.expectName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name, Arguments: map[string]interface{}{}}
+	if p.isPunctuator(":") {
+		p.next()
+		field.Alias = name
+		if field.Name, err = p.expectName(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.isPunctuator("(") {
+		p.next()
+		for !p.isPunctuator(")") {
+			argument, err := p.expectName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunctuator(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseValue(false)
+			if err != nil {
+				return nil, err
+			}
+			field.Arguments[argument] = value
+		}
+		p.next()
+	}
+
+	if p.isPunctuator("@") {
+		return nil, p.unexpected(p.peek(), "directives are not supported")
+	}
+
+	if p.isPunctuator("{") {
+		if field.Selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+// parseValue - constant values (variable defaults) can't reference variables
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenInt:
+		return strconv.ParseInt(t.value, 10, 64)
+	case tokenFloat:
+		return strconv.ParseFloat(t.value, 64)
+	case tokenString:
+		return t.value, nil
+	case tokenName:
+		switch t.value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return Enum(t.value), nil
+	case tokenPunctuator:
+		switch t.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected(t, "variables are not allowed in default values")
+			}
+			name, err := p.expectName()
+			return Variable(name), err
+		case "[":
+			if err := p.enter(t); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			list := make([]interface{}, 0)
+			for !p.isPunctuator("]") {
+				value, err := p.parseValue(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, value)
+			}
+			p.next()
+			return list, nil
+		case "{":
+			if err := p.enter(t); err != nil {
+				return nil, err
+			}
+			defer p.leave()
+			object := map[string]interface{}{}
+			for !p.isPunctuator("}") {
+				name, err := p.expectName()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expectPunctuator(":"); err != nil {
+					return nil, err
+				}
+				if object[name], err = p.parseValue(constant); err != nil {
+					return nil, err
+				}
+			}
+			p.next()
+			return object, nil
+		}
+	}
+
+	return nil, p.unexpected(t, "expected a value")
+}
+
+func lex(query string) ([]token, error) {
+	tokens := make([]token, 0)
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunctuator, value: "...", position: i})
+			i += 3
+		case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunctuator, value: string(c), position: i})
+			i++
+		case c == '_' || isLetter(c):
+			start := i
+			for i < len(query) && (query[i] == '_' || isLetter(query[i]) || isDigit(query[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenName, value: query[start:i], position: start})
+		case c == '-' || isDigit(c):
+			t, end, err := lexNumber(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = end
+		case c == '"':
+			if strings.HasPrefix(query[i:], `"""`) {
+				return nil, &SyntaxError{Message: "block strings are not supported", Position: i}
+			}
+			t, end, err := lexString(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = end
+		default:
+			r, _ := utf8.DecodeRuneInString(query[i:])
+			return nil, &SyntaxError{Message: "unexpected character " + strconv.QuoteRune(r), Position: i}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, position: len(query)}), nil
+}
+
+func lexNumber(query string, start int) (token, int, error) {
+	i := start
+	if query[i] == '-' {
+		i++
+	}
+	digits := i
+	for i < len(query) && isDigit(query[i]) {
+		i++
+	}
+	if i == digits {
+		return token{}, 0, &SyntaxError{Message: "invalid number", Position: start}
+	}
+
+	kind := tokenInt
+	if i < len(query) && query[i] == '.' {
+		kind = tokenFloat
+		i++
+		fraction := i
+		for i < len(query) && isDigit(query[i]) {
+			i++
+		}
+		if i == fraction {
+			return token{}, 0, &SyntaxError{Message: "invalid number", Position: start}
+		}
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		kind = tokenFloat
+		i++
+		if i < len(query) && (query[i] == '+' || query[i] == '-') {
+			i++
+		}
+		exponent := i
+		for i < len(query) && isDigit(query[i]) {
+			i++
+		}
+		if i == exponent {
+			return token{}, 0, &SyntaxError{Message: "invalid number", Position: start}
+		}
+	}
+
+	return token{kind: kind, value: query[start:i], position: start}, i, nil
+}
+
+func lexString(query string, start int) (token, int, error) {
+	var value strings.Builder
+	for i := start + 1; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '"':
+			return token{kind: tokenString, value: value.String(), position: start}, i + 1, nil
+		case c == '\n' || c == '\r':
+			return token{}, 0, &SyntaxError{Message: "unterminated string", Position: start}
+		case c == '\\' && i+1 < len(query):
+			escaped := query[i+1]
+			switch escaped {
+			case '"', '\\', '/':
+				value.WriteByte(escaped)
+			case 'b':
+				value.WriteByte('\b')
+			case 'f':
+				value.WriteByte('\f')
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case 'u':
+				if i+6 > len(query) {
+					return token{}, 0, &SyntaxError{Message: "invalid unicode escape", Position: i}
+				}
+				code, err := strconv.ParseUint(query[i+2:i+6], 16, 32)
+				if err != nil {
+					return token{}, 0, &SyntaxError{Message: "invalid unicode escape", Position: i}
+				}
+				value.WriteRune(rune(code))
+				i += 4
+			default:
+				return token{}, 0, &SyntaxError{Message: "invalid escape sequence", Position: i}
+			}
+			i += 2
+		default:
+			value.WriteByte(c)
+			i++
+		}
+	}
+
+	return token{}, 0, &SyntaxError{Message: "unterminated string", Position: start}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
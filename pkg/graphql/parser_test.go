@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"encoding/json"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestGraphQL(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "graphql test")
+}
+
+var _ = ginkgo.Describe("graphql parser", func() {
+
+	ginkgo.Context("validate that a query with variables, aliases and nested selections is parsed", func() {
+
+		ginkgo.It("do", func() {
+			document, err := Parse(`
+				query Persons($first: Int = 10, $name: String!) {
+					top: persons(first: $first, name: $name, ratingUpdated: true) { id name rating }
+					person(id: 7) { id }
+				}`)
+			gomega.Expect(err).To(gomega.BeNil())
+
+			operation, err := document.Operation("")
+			gomega.Expect(err).To(gomega.BeNil())
+			gomega.Expect(operation.Name).To(gomega.Equal("Persons"))
+			gomega.Expect(operation.Selections).To(gomega.HaveLen(2))
+
+			persons := operation.Selections[0]
+			gomega.Expect(persons.ResponseKey()).To(gomega.Equal("top"))
+			gomega.Expect(persons.Selects("rating")).To(gomega.BeTrue())
+
+			arguments, err := operation.Arguments(persons, map[string]interface{}{"name": "dadi"})
+			gomega.Expect(err).To(gomega.BeNil())
+			first, _ := arguments.Int("first", 0)
+			name, _ := arguments.String("name", "")
+			ratingUpdated, _ := arguments.Bool("ratingUpdated", false)
+			gomega.Expect(first).To(gomega.Equal(int64(10)))
+			gomega.Expect(name).To(gomega.Equal("dadi"))
+			gomega.Expect(ratingUpdated).To(gomega.BeTrue())
+
+			_, err = operation.Arguments(persons, map[string]interface{}{})
+			gomega.Expect(err).NotTo(gomega.BeNil())
+		})
+	})
+
+	ginkgo.Context("validate that unsupported and invalid documents are rejected", func() {
+
+		ginkgo.It("do", func() {
+			_, err := Parse(`{ persons { ...PersonFields } }`)
+			gomega.Expect(err).NotTo(gomega.BeNil())
+			_, err = Parse(`{ persons(first: 1 { id } }`)
+			gomega.Expect(err).NotTo(gomega.BeNil())
+
+			_, err = Parse(strings.Repeat("{ persons ", maxDepth) + "{ id }" + strings.Repeat(" }", maxDepth))
+			gomega.Expect(err).To(gomega.BeAssignableToTypeOf(&SyntaxError{}))
+			_, err = Parse("{ persons(name: " + strings.Repeat("[", 100000) + ") { id } }")
+			gomega.Expect(err).To(gomega.BeAssignableToTypeOf(&SyntaxError{}))
+			_, err = Parse(strings.Repeat("{ persons ", maxDepth-1) + "{ id }" + strings.Repeat(" }", maxDepth-1))
+			gomega.Expect(err).To(gomega.BeNil())
+
+			document, err := Parse(`mutation { deletePerson(id: 1) { id } }`)
+			gomega.Expect(err).To(gomega.BeNil())
+			_, err = document.Operation("")
+			gomega.Expect(err).To(gomega.Equal(ErrUnsupportedOperation))
+		})
+	})
+
+	ginkgo.Context("validate that response objects keep the selection order", func() {
+
+		ginkgo.It("do", func() {
+			object := NewObject()
+			object.Set("name", "dadi")
+			object.Set("id", 1)
+			bytes, err := json.Marshal(object)
+			gomega.Expect(err).To(gomega.BeNil())
+			gomega.Expect(string(bytes)).To(gomega.Equal(`{"name":"dadi","id":1}`))
+		})
+	})
+})
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-healthcheck"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"net/http"
+	"time"
+)
+
+const statusOK = "OK"
+
+var ErrNotConnected = errors.New("not connected")
+
+// Options - person.health settings
+type Options struct {
+	// OrdersTimeout - how long the readiness check waits for the orders service
+	OrdersTimeout time.Duration
+	// RatingsCacheMaxAge - the ratings cache is stale once the worker hasn't written it for this long
+	RatingsCacheMaxAge time.Duration
+	MigrationsDir      string
+	// MigrationsTable - the table swan records the applied migrations in
+	MigrationsTable string
+}
+
+func OptionsFromConfig() Options {
+	health := config.Current().Person.Health
+	return Options{
+		OrdersTimeout:      health.OrdersTimeout,
+		RatingsCacheMaxAge: health.RatingsCacheMaxAge,
+		MigrationsDir:      health.MigrationsDir,
+		MigrationsTable:    health.MigrationsTable,
+	}
+}
+
+// Handler - serve the check of the pingers, a failed pinger turns the response into a 503
+func Handler(pingers ...healthcheck.Pinger) http.Handler {
+	return healthcheck.MakeHealthcheckHandler(healthcheck.NewHealthCheck(pingers...))
+}
+
+// Degraded - report the pinger without failing the check, for dependencies the service can serve without
+func Degraded(pinger healthcheck.Pinger) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		response, _ := pinger(ctx)
+		return response, nil
+	}
+}
+
+// RedisPinger - PING the redis the stores, the leaderboard and the rate limiter share
+func RedisPinger(client *gettStorages.GtRedisClient) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		if client == nil {
+			return map[string]interface{}{"redis": ErrNotConnected.Error()}, ErrNotConnected
+		}
+		if err := client.Ping().Err(); err != nil {
+			return map[string]interface{}{"redis": err.Error()}, err
+		}
+
+		return map[string]interface{}{"redis": statusOK}, nil
+	}
+}
+
+// HTTPPinger - GET the url, any status but a 2xx fails the pinger. The timeout bounds the call on top of the deadline
+// of the check
+func HTTPPinger(name, url string, timeout time.Duration) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		if err := get(ctx, url); err != nil {
+			return map[string]interface{}{name: err.Error()}, err
+		}
+
+		return map[string]interface{}{name: statusOK}, nil
+	}
+}
+
+func get(ctx context.Context, url string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return errors.New("responded with " + response.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "health test")
+}
+
+var _ = ginkgo.Describe("health checks", func() {
+
+	failing := func(ctx context.Context) (map[string]interface{}, error) {
+		return map[string]interface{}{"orders": "down"}, errors.New("down")
+	}
+
+	ginkgo.Context("validate that a failed pinger fails the check and a degraded one only reports", func() {
+
+		ginkgo.It("do", func() {
+			recorder := httptest.NewRecorder()
+			Handler(Degraded(failing)).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(recorder.Body.String()).To(gomega.ContainSubstring(`"orders":"down"`))
+
+			recorder = httptest.NewRecorder()
+			Handler(failing).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	ginkgo.Context("validate that the http pinger fails on error statuses and slow responses", func() {
+
+		var server *httptest.Server
+
+		ginkgo.BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/slow":
+					time.Sleep(200 * time.Millisecond)
+				case "/broken":
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}))
+		})
+
+		ginkgo.AfterEach(func() {
+			server.Close()
+		})
+
+		ginkgo.It("do", func() {
+			response, err := HTTPPinger("orders", server.URL+"/alive", time.Second)(context.Background())
+			gomega.Expect(err).To(gomega.BeNil())
+			gomega.Expect(response).To(gomega.Equal(map[string]interface{}{"orders": statusOK}))
+
+			_, err = HTTPPinger("orders", server.URL+"/broken", time.Second)(context.Background())
+			gomega.Expect(err).To(gomega.HaveOccurred())
+
+			_, err = HTTPPinger("orders", server.URL+"/slow", 50*time.Millisecond)(context.Background())
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+	})
+
+	ginkgo.Context("validate that the latest migration is the newest timestamp prefix", func() {
+
+		var dir string
+
+		ginkgo.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "migrations")
+			for _, name := range []string{"20210124153923_create_person_table.sql", "20261019150000_create_webhooks_tables.sql", "README.md"} {
+				ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0644)
+			}
+		})
+
+		ginkgo.AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(latestMigration(dir)).To(gomega.Equal(int64(20261019150000)))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/gtforge/go-healthcheck"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const migrationsKey = "migrations"
+
+// MigrationsPinger - fail while the latest migration of the directory is not applied to the database, the replicas of
+// a new release wait for the migrate job before taking traffic
+func MigrationsPinger(db *sql.DB, dir, table string) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		latest, err := latestMigration(dir)
+		if err != nil {
+			return map[string]interface{}{migrationsKey: err.Error()}, err
+		}
+
+		var applied int64
+		query := "SELECT COALESCE(MAX(version_id), 0) FROM " + table + " WHERE is_applied"
+		if err := db.QueryRowContext(ctx, query).Scan(&applied); err != nil {
+			return map[string]interface{}{migrationsKey: err.Error()}, err
+		}
+
+		response := map[string]interface{}{migrationsKey: map[string]interface{}{"latest": latest, "applied": applied}}
+		if applied < latest {
+			return response, fmt.Errorf("migrations are behind, applied %v of %v", applied, latest)
+		}
+
+		return response, nil
+	}
+}
+
+// latestMigration - the version of the newest migration, the timestamp prefix of its file name
+func latestMigration(dir string) (int64, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".sql" {
+			continue
+		}
+		prefix := strings.SplitN(file.Name(), "_", 2)[0]
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,253 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	keyPrefix    = "idempotency:"
+	maxKeyLength = 255
+
+	defaultMaxBodyBytes = 1 << 20
+)
+
+var (
+	ErrKeyTooLong     = errors.New("the Idempotency-Key header is limited to 255 characters")
+	ErrKeyReused      = errors.New("the Idempotency-Key was already used with a different request body")
+	ErrKeyInFlight    = errors.New("a request with the same Idempotency-Key is still in progress")
+	ErrKeyExpired     = errors.New("the Idempotency-Key expired while being reserved")
+	ErrUnreadableBody = errors.New("unable to read the request body")
+	ErrBodyTooLarge   = errors.New("the request body is too large to be kept for the Idempotency-Key")
+)
+
+// Keeper - replays the first successful response of an Idempotency-Key to the retries of the same request
+type Keeper struct {
+	store Store
+	// ttl - how long a completed response is replayed
+	ttl time.Duration
+	// lockTTL - how long an in flight key blocks retries in case the request never completes
+	lockTTL time.Duration
+	// maxBodyBytes - the largest body of a request with a key, it is read whole to fingerprint the request
+	maxBodyBytes int64
+	now          func() time.Time
+	render       *render.Render
+}
+
+func NewKeeper(store Store, ttl, lockTTL time.Duration) *Keeper {
+	return &Keeper{
+		store:        store,
+		ttl:          ttl,
+		lockTTL:      lockTTL,
+		maxBodyBytes: defaultMaxBodyBytes,
+		now:          time.Now,
+		render:       render.New(),
+	}
+}
+
+// WithMaxBodyBytes - the largest body of a request with a key, larger ones are rejected with a 413
+func (k *Keeper) WithMaxBodyBytes(maxBodyBytes int64) *Keeper {
+	k.maxBodyBytes = maxBodyBytes
+	return k
+}
+
+// NewKeeperFromConfig - the keeper of the idempotent handlers of the service, the ttls come from person.idempotency
+// and the bodies are capped at person.auth.max_body_bytes like the signed ones
+func NewKeeperFromConfig(store Store) *Keeper {
+	settings := config.Current().Person
+	return NewKeeper(store, settings.Idempotency.TTL, settings.Idempotency.Lock).WithMaxBodyBytes(settings.Auth.MaxBodyBytes)
+}
+
+// Wrap - requests without the Idempotency-Key header are served as is. keys are scoped by caller and path, only
+// successful responses are kept so a failed request can be retried with the same key
+func (k *Keeper) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		idempotencyKey := req.Header.Get(KeyHeader)
+		if idempotencyKey == "" {
+			next(w, req)
+			return
+		}
+		if len(idempotencyKey) > maxKeyLength {
+			k.renderError(w, http.StatusBadRequest, ErrKeyTooLong)
+			return
+		}
+
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, k.maxBodyBytes))
+		req.Body.Close()
+		if err != nil && int64(len(body)) >= k.maxBodyBytes {
+			k.renderError(w, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+		if err != nil {
+			k.renderError(w, http.StatusBadRequest, ErrUnreadableBody)
+			return
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		key := storeKey(req, idempotencyKey)
+		fingerprint := requestFingerprint(req, body)
+		existing, err := k.store.Reserve(key, Record{Status: StatusInFlight, Fingerprint: fingerprint, CreatedAt: k.now()}, k.lockTTL)
+		if err != nil {
+			logrus.Error("couldn't reserve idempotency key, serving the request ", err)
+			next(w, req)
+			return
+		}
+
+		if existing != nil {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				k.renderError(w, http.StatusUnprocessableEntity, ErrKeyReused)
+			case existing.Status != StatusCompleted:
+				k.renderError(w, http.StatusConflict, ErrKeyInFlight)
+			default:
+				replay(w, existing)
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		completed := false
+		defer func() {
+			if !completed {
+				k.release(key)
+			}
+		}()
+
+		next(recorder, req)
+
+		if recorder.statusCode < 200 || recorder.statusCode >= 300 {
+			return
+		}
+		record := Record{
+			Status:      StatusCompleted,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   k.now(),
+		}
+		if err := k.store.Save(key, record, k.ttl); err != nil {
+			logrus.Error("couldn't save idempotent response ", err)
+			return
+		}
+		completed = true
+	}
+}
+
+// Do - the idempotent call of the apis other than http: run is called once per caller, scope and key, the retries of
+// the same request get the response of the first successful run back with replayed set. request is the encoded call
+// the retries are compared with, a call without a key is run as is
+func (k *Keeper) Do(ctx context.Context, scope, idempotencyKey string, request []byte, run func() ([]byte, error)) (response []byte, replayed bool, err error) {
+	if idempotencyKey == "" {
+		response, err = run()
+		return response, false, err
+	}
+	if len(idempotencyKey) > maxKeyLength {
+		return nil, false, ErrKeyTooLong
+	}
+
+	key := keyPrefix + caller(ctx) + ":" + scope + ":" + idempotencyKey
+	fingerprint := fingerprint(scope, request)
+	existing, err := k.store.Reserve(key, Record{Status: StatusInFlight, Fingerprint: fingerprint, CreatedAt: k.now()}, k.lockTTL)
+	if err != nil {
+		logrus.Error("couldn't reserve idempotency key, serving the request ", err)
+		response, err = run()
+		return response, false, err
+	}
+
+	if existing != nil {
+		switch {
+		case existing.Fingerprint != fingerprint:
+			return nil, false, ErrKeyReused
+		case existing.Status != StatusCompleted:
+			return nil, false, ErrKeyInFlight
+		}
+		return existing.Body, true, nil
+	}
+
+	response, err = run()
+	if err != nil {
+		k.release(key)
+		return nil, false, err
+	}
+	if err := k.store.Save(key, Record{Status: StatusCompleted, Fingerprint: fingerprint, Body: response, CreatedAt: k.now()}, k.ttl); err != nil {
+		logrus.Error("couldn't save idempotent response ", err)
+		k.release(key)
+	}
+
+	return response, false, nil
+}
+
+func (k *Keeper) release(key string) {
+	if err := k.store.Release(key); err != nil {
+		logrus.Error("couldn't release idempotency key ", err)
+	}
+}
+
+func (k *Keeper) renderError(w http.ResponseWriter, code int, err error) {
+	k.render.JSON(w, code, skeleton.NewAPIError(http.StatusText(code), err))
+}
+
+func replay(w http.ResponseWriter, record *Record) {
+	if record.ContentType != "" {
+		w.Header().Set("Content-Type", record.ContentType)
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+func storeKey(req *http.Request, idempotencyKey string) string {
+	return keyPrefix + caller(req.Context()) + ":" + req.Method + ":" + req.URL.Path + ":" + idempotencyKey
+}
+
+// caller - the keys are scoped by the authenticated caller, so callers can't replay the responses of each other
+func caller(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.Subject
+	}
+
+	return "anonymous"
+}
+
+func requestFingerprint(req *http.Request, body []byte) string {
+	return fingerprint(req.Method+"\n"+req.URL.Path, body)
+}
+
+func fingerprint(scope string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(scope + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder - writes through to the client and keeps a copy of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
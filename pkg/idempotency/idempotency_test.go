@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"bytes"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdempotency(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "idempotency test")
+}
+
+var _ = ginkgo.Describe("idempotency keeper", func() {
+
+	var (
+		store    *MemoryStore
+		keeper   *Keeper
+		calls    int
+		status   int
+		inFlight func()
+	)
+
+	serve := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/person", bytes.NewBufferString(body))
+		if key != "" {
+			req.Header.Set(KeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		keeper.Wrap(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if inFlight != nil {
+				inFlight()
+			}
+			received, _ := ioutil.ReadAll(r.Body)
+			w.WriteHeader(status)
+			w.Write(received)
+		})(recorder, req)
+		return recorder
+	}
+
+	ginkgo.BeforeEach(func() {
+		store = NewMemoryStore()
+		keeper = NewKeeper(store, time.Hour, time.Minute)
+		calls = 0
+		status = http.StatusCreated
+		inFlight = nil
+	})
+
+	ginkgo.Context("validate that a retry replays the first response", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(serve("key-1", `{"name":"dadi"}`).Code).To(gomega.Equal(http.StatusCreated))
+			recorder := serve("key-1", `{"name":"dadi"}`)
+			gomega.Expect(calls).To(gomega.Equal(1))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusCreated))
+			gomega.Expect(recorder.Body.String()).To(gomega.Equal(`{"name":"dadi"}`))
+			gomega.Expect(recorder.Header().Get(ReplayedHeader)).To(gomega.Equal("true"))
+		})
+	})
+
+	ginkgo.Context("validate that a reused key with a different body is rejected", func() {
+
+		ginkgo.It("do", func() {
+			serve("key-1", `{"name":"dadi"}`)
+			gomega.Expect(serve("key-1", `{"name":"other"}`).Code).To(gomega.Equal(http.StatusUnprocessableEntity))
+			gomega.Expect(calls).To(gomega.Equal(1))
+		})
+	})
+
+	ginkgo.Context("validate that a retry of an in flight request is rejected", func() {
+
+		ginkgo.It("do", func() {
+			var retry *httptest.ResponseRecorder
+			inFlight = func() {
+				inFlight = nil
+				retry = serve("key-1", `{"name":"dadi"}`)
+			}
+			serve("key-1", `{"name":"dadi"}`)
+			gomega.Expect(retry.Code).To(gomega.Equal(http.StatusConflict))
+		})
+	})
+
+	ginkgo.Context("validate that failed requests release the key", func() {
+
+		ginkgo.It("do", func() {
+			status = http.StatusConflict
+			serve("key-1", `{"name":"dadi"}`)
+			status = http.StatusCreated
+			gomega.Expect(serve("key-1", `{"name":"dadi"}`).Code).To(gomega.Equal(http.StatusCreated))
+			gomega.Expect(calls).To(gomega.Equal(2))
+		})
+	})
+
+	ginkgo.Context("validate that requests without a key are not tracked", func() {
+
+		ginkgo.It("do", func() {
+			serve("", `{"name":"dadi"}`)
+			serve("", `{"name":"dadi"}`)
+			gomega.Expect(calls).To(gomega.Equal(2))
+			gomega.Expect(store.records).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("validate that a body over the limit is rejected before the key is reserved", func() {
+
+		ginkgo.It("do", func() {
+			keeper.WithMaxBodyBytes(15)
+			gomega.Expect(serve("key-1", `{"name":"dadi"}`).Code).To(gomega.Equal(http.StatusCreated))
+			gomega.Expect(serve("key-2", `{"name":"dadi!"}`).Code).To(gomega.Equal(http.StatusRequestEntityTooLarge))
+			gomega.Expect(calls).To(gomega.Equal(1))
+			gomega.Expect(store.records).To(gomega.HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,151 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"gopkg.in/redis.v5"
+	"sync"
+	"time"
+)
+
+const (
+	StatusInFlight  = "in_flight"
+	StatusCompleted = "completed"
+)
+
+// Record - the state of an idempotency key, the response is kept once the request completed
+type Record struct {
+	Status      string    `json:"status"`
+	Fingerprint string    `json:"fingerprint"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Store interface {
+	// Reserve - save the record unless the key exists, the existing record is returned otherwise
+	Reserve(key string, record Record, ttl time.Duration) (*Record, error)
+	Save(key string, record Record, ttl time.Duration) error
+	Release(key string) error
+}
+
+// RedisStore - the records shared by all the replicas of the service
+type RedisStore struct {
+	client *gettStorages.GtRedisClient
+}
+
+func NewRedisStore(client *gettStorages.GtRedisClient) RedisStore {
+	return RedisStore{client: client}
+}
+
+func (s RedisStore) Reserve(key string, record Record, ttl time.Duration) (*Record, error) {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	// the key may expire between SETNX and GET, the second attempt then reserves it
+	for attempt := 0; attempt < 2; attempt++ {
+		reserved, err := s.client.SetNX(key, bytes, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		existing, err := s.client.Get(key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result := &Record{}
+		if err := json.Unmarshal(existing, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return nil, ErrKeyExpired
+}
+
+func (s RedisStore) Save(key string, record Record, ttl time.Duration) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(key, bytes, ttl).Err()
+}
+
+func (s RedisStore) Release(key string) error {
+	return s.client.Del(key).Err()
+}
+
+// MemoryStore - the records kept by the process, the keys are only shared by the requests of one replica
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: map[string]memoryRecord{},
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Reserve(key string, record Record, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if existing, ok := s.records[key]; ok && now.Before(existing.expiresAt) {
+		result := existing.Record
+		return &result, nil
+	}
+	s.sweep(now)
+	s.records[key] = memoryRecord{Record: record, expiresAt: now.Add(ttl)}
+
+	return nil, nil
+}
+
+func (s *MemoryStore) Save(key string, record Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = memoryRecord{Record: record, expiresAt: s.now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep - drop the expired records once a minute, run on the reservations so the store doesn't grow with the traffic
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.sweptAt) < time.Minute {
+		return
+	}
+	s.sweptAt = now
+	for key, record := range s.records {
+		if !now.Before(record.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
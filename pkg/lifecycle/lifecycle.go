@@ -0,0 +1,151 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var ErrStageTimeout = errors.New("shutdown stage did not finish in time")
+
+// Options - person.shutdown settings
+type Options struct {
+	// DrainTimeout - how long the messages and the worker jobs in process are waited for
+	DrainTimeout time.Duration
+	// HTTPTimeout - how long the active requests are waited for
+	HTTPTimeout time.Duration
+	// CloseTimeout - how long closing each connection is waited for
+	CloseTimeout time.Duration
+}
+
+func OptionsFromConfig() Options {
+	shutdown := config.Current().Person.Shutdown
+	return Options{
+		DrainTimeout: shutdown.DrainTimeout,
+		HTTPTimeout:  shutdown.HTTPTimeout,
+		CloseTimeout: shutdown.CloseTimeout,
+	}
+}
+
+type stage struct {
+	name    string
+	timeout time.Duration
+	stop    func(ctx context.Context) error
+}
+
+// Manager - stops the components of the service in the order they were added once the process is signalled
+type Manager struct {
+	stages  []stage
+	signals chan os.Signal
+}
+
+func NewManager() *Manager {
+	return &Manager{signals: make(chan os.Signal, 1)}
+}
+
+// Add - a shutdown stage, it gets a context of its own bounded by the timeout so a slow stage doesn't take the time of
+// the ones after it
+func (m *Manager) Add(name string, timeout time.Duration, stop func(ctx context.Context) error) {
+	m.stages = append(m.stages, stage{name: name, timeout: timeout, stop: stop})
+}
+
+// Wait - block until SIGINT or SIGTERM, or until a component fails
+func (m *Manager) Wait(failed <-chan error) {
+	signal.Notify(m.signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(m.signals)
+
+	select {
+	case sig := <-m.signals:
+		logrus.Info("signal received, shutting down: ", sig.String())
+	case err := <-failed:
+		logrus.Error("component failed, shutting down: ", err)
+	}
+}
+
+// Shutdown - run the stages in order, a failed stage is logged and the next ones still run
+func (m *Manager) Shutdown() error {
+	var failed error
+	for _, s := range m.stages {
+		logger := logrus.WithField("stage", s.name)
+		start := time.Now()
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		err := s.stop(ctx)
+		cancel()
+
+		if err != nil {
+			logger.Error("shutdown stage failed ", err)
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		logger.WithField("duration", time.Since(start).String()).Info("shutdown stage done")
+	}
+
+	return failed
+}
+
+// WaitFor - run a blocking wait, e.g. a sync.WaitGroup, and give up with ErrStageTimeout once the context is done
+func WaitFor(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ErrStageTimeout
+	}
+}
+
+// All - a stage running the stops concurrently, it fails with the first error
+func All(stops ...func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		errs := make(chan error, len(stops))
+		var wg sync.WaitGroup
+		for _, stop := range stops {
+			wg.Add(1)
+			go func(stop func(ctx context.Context) error) {
+				defer wg.Done()
+				errs <- stop(ctx)
+			}(stop)
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// Close - a stage closing a connection, close is given up on once the context is done
+func Close(close func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		errs := make(chan error, 1)
+		go func() {
+			errs <- close()
+		}()
+
+		select {
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ErrStageTimeout
+		}
+	}
+}
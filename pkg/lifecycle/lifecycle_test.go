@@ -0,0 +1,78 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"testing"
+	"time"
+)
+
+func TestLifecycle(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "lifecycle test")
+}
+
+var _ = ginkgo.Describe("shutdown manager", func() {
+
+	var (
+		manager *Manager
+		stopped []string
+	)
+
+	stop := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			stopped = append(stopped, name)
+			return err
+		}
+	}
+
+	ginkgo.BeforeEach(func() {
+		manager = NewManager()
+		stopped = []string{}
+	})
+
+	ginkgo.Context("validate that the stages run in order and a failed stage doesn't stop the next ones", func() {
+
+		ginkgo.It("do", func() {
+			manager.Add("drain", time.Second, stop("drain", nil))
+			manager.Add("http", time.Second, stop("http", errors.New("busy")))
+			manager.Add("db", time.Second, stop("db", nil))
+
+			gomega.Expect(manager.Shutdown()).To(gomega.MatchError("busy"))
+			gomega.Expect(stopped).To(gomega.Equal([]string{"drain", "http", "db"}))
+		})
+	})
+
+	ginkgo.Context("validate that every stage gets a deadline of its own", func() {
+
+		ginkgo.It("do", func() {
+			var remaining time.Duration
+			manager.Add("drain", 50*time.Millisecond, func(ctx context.Context) error {
+				return WaitFor(ctx, func() { time.Sleep(time.Second) })
+			})
+			manager.Add("http", time.Second, func(ctx context.Context) error {
+				deadline, _ := ctx.Deadline()
+				remaining = time.Until(deadline)
+				return ctx.Err()
+			})
+
+			gomega.Expect(manager.Shutdown()).To(gomega.Equal(ErrStageTimeout))
+			gomega.Expect(remaining).To(gomega.BeNumerically(">", 500*time.Millisecond))
+		})
+	})
+
+	ginkgo.Context("validate that all the stops of a stage run and the first error fails it", func() {
+
+		ginkgo.It("do", func() {
+			var consumer, workers bool
+			err := All(
+				func(ctx context.Context) error { consumer = true; return nil },
+				func(ctx context.Context) error { workers = true; return errors.New("stuck") },
+			)(context.Background())
+			gomega.Expect(err).To(gomega.MatchError("stuck"))
+			gomega.Expect(consumer && workers).To(gomega.BeTrue())
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package metrics
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+
+	ResultProcessed = "processed"
+	ResultFailed    = "failed"
+)
+
+// workerBuckets - run durations in seconds, a worker run covers every market
+var workerBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+var (
+	HTTPRequestDuration = Instance.NewHistogramVec("person_http_request_duration_seconds",
+		"Latency of the API requests by route template, method and status code.", DefaultBuckets, "route", "method", "status")
+
+	CacheRequests = Instance.NewCounterVec("person_cache_requests_total",
+		"Cache lookups by cache, entry and result (hit, miss or error).", "cache", "entry", "result")
+
+	OrdersRequestDuration = Instance.NewHistogramVec("person_orders_request_duration_seconds",
+		"Latency of the orders service calls by market.", DefaultBuckets, "market")
+
+	OrdersRequestErrors = Instance.NewCounterVec("person_orders_request_errors_total",
+		"Failed orders service calls by market and reason (unreachable, status or decode).", "market", "reason")
+
+	WorkerRunDuration = Instance.NewHistogramVec("person_worker_run_duration_seconds",
+		"Duration of the worker runs.", workerBuckets, "worker")
+
+	WorkerLastSuccess = Instance.NewGaugeVec("person_worker_last_success_timestamp_seconds",
+		"Unix time of the last worker run that completed without errors.", "worker")
+
+	ConsumerMessages = Instance.NewCounterVec("person_consumer_messages_total",
+		"Consumed messages by routing key and result (processed or failed).", "routing_key", "result")
+)
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"bytes"
+	"github.com/gorilla/mux"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "metrics test")
+}
+
+var _ = ginkgo.Describe("metrics", func() {
+
+	var registry *Registry
+
+	ginkgo.BeforeEach(func() {
+		registry = NewRegistry()
+	})
+
+	exposition := func() string {
+		buffer := &bytes.Buffer{}
+		registry.Write(buffer)
+		return buffer.String()
+	}
+
+	ginkgo.Context("validate that counters and gauges are written per label values", func() {
+
+		ginkgo.It("do", func() {
+			counter := registry.NewCounterVec("cache_requests_total", "Cache lookups.", "cache", "result")
+			gauge := registry.NewGaugeVec("last_success_timestamp_seconds", "Last success.", "worker")
+			counter.Inc("person_store", CacheMiss)
+			counter.Add(2, "person_store", CacheHit)
+			gauge.Set(1571493600, "InMemoryWorker")
+
+			gomega.Expect(exposition()).To(gomega.Equal(`# HELP cache_requests_total Cache lookups.
+# TYPE cache_requests_total counter
+cache_requests_total{cache="person_store",result="hit"} 2
+cache_requests_total{cache="person_store",result="miss"} 1
+# HELP last_success_timestamp_seconds Last success.
+# TYPE last_success_timestamp_seconds gauge
+last_success_timestamp_seconds{worker="InMemoryWorker"} 1571493600
+`))
+		})
+	})
+
+	ginkgo.Context("validate that histograms are written with cumulative buckets", func() {
+
+		ginkgo.It("do", func() {
+			histogram := registry.NewHistogramVec("duration_seconds", "Durations.", []float64{1, 0.1}, "market")
+			histogram.Observe(0.05, "IL")
+			histogram.Observe(0.5, "IL")
+			histogram.Observe(3, "IL")
+
+			gomega.Expect(exposition()).To(gomega.Equal(`# HELP duration_seconds Durations.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{market="IL",le="0.1"} 1
+duration_seconds_bucket{market="IL",le="1"} 2
+duration_seconds_bucket{market="IL",le="+Inf"} 3
+duration_seconds_sum{market="IL"} 3.55
+duration_seconds_count{market="IL"} 3
+`))
+		})
+	})
+
+	ginkgo.Context("validate that label values are escaped", func() {
+
+		ginkgo.It("do", func() {
+			registry.NewCounterVec("errors_total", "Errors.", "reason").Inc("a \"quoted\"\nreason")
+
+			gomega.Expect(exposition()).To(gomega.ContainSubstring(`errors_total{reason="a \"quoted\"\nreason"} 1`))
+		})
+	})
+
+	ginkgo.Context("validate that the middleware observes requests by route template", func() {
+
+		ginkgo.It("do", func() {
+			durations := registry.NewHistogramVec("person_http_request_duration_seconds", "Durations.", DefaultBuckets, "route", "method", "status")
+			router := mux.NewRouter()
+			router.Use(ObserveRequests(durations))
+			router.HandleFunc("/api/v1/person/{id:[0-9]+}", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			})
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/person/42", nil))
+
+			recorder := httptest.NewRecorder()
+			registry.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			gomega.Expect(recorder.Header().Get("Content-Type")).To(gomega.Equal(ContentType))
+			gomega.Expect(recorder.Body.String()).To(gomega.ContainSubstring(
+				`person_http_request_duration_seconds_count{route="/api/v1/person/{id:[0-9]+}",method="GET",status="404"} 1`))
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/statuswriter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware - observe the latency and status of the requests by their route template, so /person/{id} is one series
+func Middleware(next http.Handler) http.Handler {
+	return ObserveRequests(HTTPRequestDuration)(next)
+}
+
+// ObserveRequests - the middleware observing into a histogram labelled by route, method and status
+func ObserveRequests(histogram *HistogramVec) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			recorder, writer := statuswriter.New(w)
+
+			next.ServeHTTP(writer, req)
+
+			histogram.Observe(time.Since(start).Seconds(), routeTemplate(req), req.Method, strconv.Itoa(recorder.Status()))
+		})
+	}
+}
+
+func routeTemplate(req *http.Request) string {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return "unmatched"
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
+	}
+
+	return template
+}
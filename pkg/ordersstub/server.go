@@ -0,0 +1,15 @@
+package ordersstub
+
+import (
+	"net/http/httptest"
+)
+
+// NewServer - the stub on a local port for the tests, point person.orders_url at its URL and close it once done
+func NewServer(options Options) (*httptest.Server, *Stub, error) {
+	stub, err := NewStub(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return httptest.NewServer(stub.Handler()), stub, nil
+}
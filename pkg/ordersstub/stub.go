@@ -0,0 +1,211 @@
+package ordersstub
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxOrders = 20
+	// historyDays - the generated orders were created over the last days
+	historyDays = 90
+
+	// malformedBody - a response cut in the middle of an order, like a connection dropped by a proxy
+	malformedBody = `[{"id":1,"order_id":`
+)
+
+var ErrInvalidRate = errors.New("the error and malformed rates must be between 0 and 1")
+
+// generatedRatings - the generated orders are rated mostly well, like the real ones
+var generatedRatings = []float64{1, 2, 3, 3, 4, 4, 4, 5, 5, 5}
+
+// Order - an order as the orders service serves it
+type Order struct {
+	ID        int64     `json:"id"`
+	OrderID   int64     `json:"order_id"`
+	PersonID  int64     `json:"person_id"`
+	Rating    float64   `json:"rating"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Options - the knobs of the stub, the failures are drawn per request
+type Options struct {
+	// Seed - the same seed generates the same orders for every person
+	Seed int64
+	// MaxOrders - the generated persons have 0 to MaxOrders orders
+	MaxOrders int
+	// Latency - how long every response is held, cut short once the caller gives up
+	Latency time.Duration
+	// ErrorRate - the share of the requests answered with a 500
+	ErrorRate float64
+	// MalformedRate - the share of the requests answered with a 200 and a body that isn't json
+	MalformedRate float64
+}
+
+func (o Options) validate() error {
+	if o.ErrorRate < 0 || o.ErrorRate > 1 || o.MalformedRate < 0 || o.MalformedRate > 1 {
+		return ErrInvalidRate
+	}
+
+	return nil
+}
+
+// Stub - serves the orders of the persons like the orders service. every person has generated orders unless its
+// orders were set or loaded
+type Stub struct {
+	mu      sync.Mutex
+	options Options
+	orders  map[int64][]Order
+	random  *rand.Rand
+	// today - the generated orders are dated back from it, so they don't move during a run
+	today time.Time
+}
+
+func NewStub(options Options) (*Stub, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if options.MaxOrders <= 0 {
+		options.MaxOrders = defaultMaxOrders
+	}
+
+	return &Stub{
+		options: options,
+		orders:  map[int64][]Order{},
+		random:  rand.New(rand.NewSource(options.Seed)),
+		today:   time.Now().UTC().Truncate(24 * time.Hour),
+	}, nil
+}
+
+// SetOptions - change the knobs of the running stub, the seed and the generated orders are kept
+func (s *Stub) SetOptions(options Options) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	options.Seed = s.options.Seed
+	if options.MaxOrders <= 0 {
+		options.MaxOrders = s.options.MaxOrders
+	}
+	s.options = options
+
+	return nil
+}
+
+// SetOrders - the orders of the person from now on, an empty list leaves the person without orders
+func (s *Stub) SetOrders(personID int64, orders []Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.orders[personID] = append([]Order{}, orders...)
+}
+
+// Load - the orders of the json dataset by person id, e.g. {"1": [{"id": 1, "order_id": 10, "rating": 5}]}
+func (s *Stub) Load(r io.Reader) error {
+	dataset := map[string][]Order{}
+	if err := json.NewDecoder(r).Decode(&dataset); err != nil {
+		return err
+	}
+
+	for key, orders := range dataset {
+		personID, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return err
+		}
+		for i := range orders {
+			orders[i].PersonID = personID
+		}
+		s.SetOrders(personID, orders)
+	}
+
+	return nil
+}
+
+// Orders - the orders served for the person
+func (s *Stub) Orders(personID int64) []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if orders, ok := s.orders[personID]; ok {
+		return append([]Order{}, orders...)
+	}
+
+	return s.generate(personID)
+}
+
+// generate - the orders of the person drawn from the seed and its id, the same on every call and every run
+func (s *Stub) generate(personID int64) []Order {
+	random := rand.New(rand.NewSource(s.options.Seed*31 + personID))
+	orders := make([]Order, random.Intn(s.options.MaxOrders+1))
+	for i := range orders {
+		id := personID*1000 + int64(i) + 1
+		orders[i] = Order{
+			ID:        id,
+			OrderID:   1000000 + id,
+			PersonID:  personID,
+			Rating:    generatedRatings[random.Intn(len(generatedRatings))],
+			CreatedAt: s.today.Add(-time.Duration(random.Int63n(historyDays*24)) * time.Hour),
+		}
+	}
+
+	return orders
+}
+
+// Handler - the routes of the orders service the person service calls
+func (s *Stub) Handler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/alive", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	router.HandleFunc("/api/v1/order_by_person/{id:[0-9]+}", s.GetOrdersByPerson).Methods(http.MethodGet)
+
+	return router
+}
+
+// GetOrdersByPerson - the orders of the person after the latency, unless the request drew a failure
+func (s *Stub) GetOrdersByPerson(w http.ResponseWriter, req *http.Request) {
+	personID, err := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "the person id must be a number", http.StatusBadRequest)
+		return
+	}
+
+	options, draw := s.draw()
+	select {
+	case <-time.After(options.Latency):
+	case <-req.Context().Done():
+		return
+	}
+
+	switch {
+	case draw < options.ErrorRate:
+		logrus.WithField("person_id", personID).Debug("orders stub answers with an injected error")
+		http.Error(w, `{"error":"injected failure"}`, http.StatusInternalServerError)
+	case draw < options.ErrorRate+options.MalformedRate:
+		logrus.WithField("person_id", personID).Debug("orders stub answers with a malformed body")
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, malformedBody)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Orders(personID))
+	}
+}
+
+// draw - the options of the request and where it falls on the failure rates
+func (s *Stub) draw() (Options, float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.options, s.random.Float64()
+}
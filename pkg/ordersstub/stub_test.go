@@ -0,0 +1,110 @@
+package ordersstub
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrdersStub(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "orders stub test")
+}
+
+var _ = ginkgo.Describe("orders stub", func() {
+
+	serve := func(stub *Stub, path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		stub.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	ginkgo.Context("validate that the same seed generates the same orders of every person", func() {
+
+		ginkgo.It("do", func() {
+			stub, err := NewStub(Options{Seed: 7})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			again, err := NewStub(Options{Seed: 7})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(stub.Orders(42)).To(gomega.Equal(again.Orders(42)))
+			for _, order := range stub.Orders(42) {
+				gomega.Expect(order.PersonID).To(gomega.Equal(int64(42)))
+				gomega.Expect(order.Rating).To(gomega.BeNumerically(">=", 1))
+				gomega.Expect(order.Rating).To(gomega.BeNumerically("<=", 5))
+			}
+
+			response := serve(stub, "/api/v1/order_by_person/42")
+			gomega.Expect(response.Code).To(gomega.Equal(http.StatusOK))
+			orders := []Order{}
+			gomega.Expect(json.Unmarshal(response.Body.Bytes(), &orders)).To(gomega.Succeed())
+			gomega.Expect(orders).To(gomega.HaveLen(len(stub.Orders(42))))
+		})
+	})
+
+	ginkgo.Context("validate that the loaded and set orders replace the generated ones", func() {
+
+		ginkgo.It("do", func() {
+			stub, err := NewStub(Options{})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(stub.Load(strings.NewReader(`{"1": [{"id": 1, "order_id": 10, "rating": 5}], "2": []}`))).To(gomega.Succeed())
+			stub.SetOrders(3, []Order{{ID: 2, OrderID: 20, PersonID: 3, Rating: 4}})
+
+			gomega.Expect(stub.Orders(1)).To(gomega.Equal([]Order{{ID: 1, OrderID: 10, PersonID: 1, Rating: 5}}))
+			gomega.Expect(stub.Orders(2)).To(gomega.BeEmpty())
+			gomega.Expect(stub.Orders(3)).To(gomega.HaveLen(1))
+			gomega.Expect(serve(stub, "/api/v1/order_by_person/2").Body.String()).To(gomega.Equal("[]\n"))
+
+			gomega.Expect(stub.Load(strings.NewReader(`{"one": []}`))).NotTo(gomega.Succeed())
+		})
+	})
+
+	ginkgo.Context("validate that the error and malformed rates fail the requests", func() {
+
+		ginkgo.It("do", func() {
+			stub, err := NewStub(Options{ErrorRate: 1})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(serve(stub, "/api/v1/order_by_person/1").Code).To(gomega.Equal(http.StatusInternalServerError))
+
+			gomega.Expect(stub.SetOptions(Options{MalformedRate: 1})).To(gomega.Succeed())
+			response := serve(stub, "/api/v1/order_by_person/1")
+			gomega.Expect(response.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(json.Unmarshal(response.Body.Bytes(), &[]Order{})).NotTo(gomega.Succeed())
+
+			gomega.Expect(stub.SetOptions(Options{ErrorRate: 1.5})).To(gomega.Equal(ErrInvalidRate))
+			_, err = NewStub(Options{MalformedRate: -1})
+			gomega.Expect(err).To(gomega.Equal(ErrInvalidRate))
+		})
+	})
+
+	ginkgo.Context("validate that the latency holds the response until the caller gives up", func() {
+
+		ginkgo.It("do", func() {
+			server, _, err := NewServer(Options{Latency: time.Second})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/order_by_person/1", nil)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			start := time.Now()
+			_, err = http.DefaultClient.Do(req.WithContext(ctx))
+			gomega.Expect(err).To(gomega.HaveOccurred())
+			gomega.Expect(time.Since(start)).To(gomega.BeNumerically("<", time.Second))
+
+			response, err := http.Get(server.URL + "/alive")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			defer response.Body.Close()
+			ioutil.ReadAll(response.Body)
+			gomega.Expect(response.StatusCode).To(gomega.Equal(http.StatusOK))
+		})
+	})
+})
@@ -0,0 +1,44 @@
+package person
+
+import (
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/webhooks"
+	"github.com/gtforge/gorm"
+)
+
+// Backends - the storages of the person service. the handler and the service share the stream, so the events
+// published by the service reach the clients of the handler
+type Backends struct {
+	Repository  PersonRepository
+	Store       Provider
+	Cache       InMemoryProvider
+	Leaderboard LeaderboardProvider
+	Stream      RatingStreamProvider
+	// Webhooks - nil drops the events
+	Webhooks webhooks.Publisher
+}
+
+// NewBackends - postgres, the redis store, leaderboard and stream and the ratings files
+func NewBackends(db *gorm.DB, redis *gettStorages.GtRedisClient, publisher webhooks.Publisher) Backends {
+	return Backends{
+		Repository:  NewRepo(db),
+		Store:       NewPersonStore(redis),
+		Cache:       Cache{},
+		Leaderboard: NewLeaderboard(redis),
+		Stream:      NewRatingStream(redis),
+		Webhooks:    publisher,
+	}
+}
+
+// NewMemoryBackends - everything kept by the process, runs the service without postgres and redis. nothing survives
+// a restart and the replicas don't share anything
+func NewMemoryBackends(publisher webhooks.Publisher) Backends {
+	return Backends{
+		Repository:  NewMemoryRepository(),
+		Store:       NewMemoryStore(),
+		Cache:       NewMemoryCache(),
+		Leaderboard: NewMemoryLeaderboard(),
+		Stream:      NewMemoryRatingStream(),
+		Webhooks:    publisher,
+	}
+}
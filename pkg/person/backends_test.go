@@ -0,0 +1,187 @@
+package person
+
+import (
+	"context"
+	"github.com/ansel1/merry"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ordersstub"
+	"github.com/gtforge/gorm"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"gopkg.in/redis.v5"
+	"net/http"
+	"time"
+)
+
+var _ = ginkgo.Describe("memory backends", func() {
+
+	var (
+		ctx      context.Context
+		backends Backends
+		previous config.Config
+	)
+
+	ginkgo.BeforeEach(func() {
+		previous = config.Current()
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		c.Person.Market = "IL"
+		config.Set(c)
+
+		ctx = context.Background()
+		backends = NewMemoryBackends(nil)
+	})
+
+	ginkgo.AfterEach(func() {
+		config.Set(previous)
+	})
+
+	ginkgo.Context("validate that the service creates, lists and deletes persons without postgres and redis", func() {
+
+		ginkgo.It("do", func() {
+			service := NewPersonService(backends)
+			created, err := service.CreatePersons(ctx, &CreatePersonRequest{Name: "Ivan Petrov", Age: 30, Market: "il"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			_, err = service.CreatePersons(ctx, &CreatePersonRequest{Name: "John Smith", Age: 40, Market: "uk"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			persons, err := service.ForMarket("IL").GetPersons(ctx)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(persons).To(gomega.HaveLen(1))
+			gomega.Expect(persons[0].Name).To(gomega.Equal("Ivan Petrov"))
+
+			person, err := service.GetPersonByID(ctx, created.ID)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(person.Market).To(gomega.Equal("IL"))
+
+			gomega.Expect(service.DeletePerson(ctx, created.ID)).To(gomega.Succeed())
+			_, err = service.GetPersonByID(ctx, created.ID)
+			gomega.Expect(gorm.IsRecordNotFoundError(err)).To(gomega.BeTrue())
+			persons, err = service.GetPersons(ctx)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(persons).To(gomega.HaveLen(1))
+		})
+	})
+
+	ginkgo.Context("validate that the repository searches the names by trigram similarity", func() {
+
+		ginkgo.It("do", func() {
+			for _, name := range []string{"Ivan Petrov", "Pavel Ivanov", "Пётр Иванов"} {
+				gomega.Expect(backends.Repository.CreatePerson(ctx, &Person{Name: name, Market: "IL"})).To(gomega.Succeed())
+			}
+			service := NewPersonService(backends)
+
+			matches, err := service.SearchPersons(ctx, "petrov", false, 10)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(matches).To(gomega.HaveLen(1))
+			gomega.Expect(matches[0].Person.Name).To(gomega.Equal("Ivan Petrov"))
+			gomega.Expect(matches[0].Similarity).To(gomega.BeNumerically("~", 7.0/12, 0.001))
+
+			matches, err = service.SearchPersons(ctx, "Иванов", true, 10)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(matches).To(gomega.HaveLen(3))
+			gomega.Expect(matches[0].Person.Name).To(gomega.Equal("Пётр Иванов"))
+			gomega.Expect(matches[1].Person.Name).To(gomega.Equal("Pavel Ivanov"))
+			gomega.Expect(matches[2].Person.Name).To(gomega.Equal("Ivan Petrov"))
+		})
+	})
+
+	ginkgo.Context("validate that the store misses like redis, scopes the keys by market and expires them", func() {
+
+		ginkgo.It("do", func() {
+			store := backends.Store.(MemoryStore)
+			now := time.Unix(1600000000, 0)
+			store.entries.now = func() time.Time { return now }
+
+			_, err := store.GetPersonByID(ctx, 1)
+			gomega.Expect(err).To(gomega.Equal(redis.Nil))
+
+			store.ForMarket("IL").CreatePersons(ctx, &Person{ID: 1, Name: "Ivan Petrov"})
+			person, err := store.ForMarket("IL").GetPersonByID(ctx, 1)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(person.Name).To(gomega.Equal("Ivan Petrov"))
+			_, err = store.ForMarket("UK").GetPersonByID(ctx, 1)
+			gomega.Expect(err).To(gomega.Equal(redis.Nil))
+
+			now = now.Add(config.Current().Person.Store.PersonsTTL)
+			_, err = store.ForMarket("IL").GetPersonByID(ctx, 1)
+			gomega.Expect(err).To(gomega.Equal(redis.Nil))
+		})
+	})
+
+	ginkgo.Context("validate that the leaderboard ranks the market persons and feeds the global ranking", func() {
+
+		ginkgo.It("do", func() {
+			leaderboard := backends.Leaderboard
+			gomega.Expect(leaderboard.ForMarket("IL").SetRatings(ctx, []Rating{{1, 4.5}, {2, 3}, {3, 4.5}})).To(gomega.Succeed())
+			gomega.Expect(leaderboard.ForMarket("UK").SetRatings(ctx, []Rating{{4, 5}})).To(gomega.Succeed())
+
+			top, err := leaderboard.ForMarket("IL").GetTop(ctx, 2, true)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(top).To(gomega.Equal([]RankedRating{{1, 3, 4.5}, {2, 1, 4.5}}))
+
+			rank, err := leaderboard.ForMarket("IL").GetRank(ctx, 2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(*rank).To(gomega.Equal(RatingRank{PersonID: 2, Rating: 3, Position: 3, Total: 3, Percentile: 33.33}))
+
+			count, err := leaderboard.Count(ctx)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(count).To(gomega.Equal(int64(4)))
+
+			gomega.Expect(leaderboard.ForMarket("UK").RemovePerson(ctx, 4)).To(gomega.Succeed())
+			_, err = leaderboard.ForMarket("UK").GetRank(ctx, 4)
+			gomega.Expect(err).To(gomega.Equal(ErrNotRanked))
+		})
+	})
+
+	ginkgo.Context("validate that the stream numbers, buffers and fans out the rating changes", func() {
+
+		ginkgo.It("do", func() {
+			events, unsubscribe := backends.Stream.Subscribe()
+			defer unsubscribe()
+
+			backends.Stream.Publish(RatingChange{PersonID: 1, Rating: 4})
+			backends.Stream.Publish(RatingChange{PersonID: 2, Rating: 3})
+
+			gomega.Expect((<-events).ID).To(gomega.Equal(int64(1)))
+			gomega.Expect((<-events).ID).To(gomega.Equal(int64(2)))
+			buffered, err := backends.Stream.Since(1)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(buffered).To(gomega.HaveLen(1))
+			gomega.Expect(buffered[0].PersonID).To(gomega.Equal(int64(2)))
+		})
+	})
+
+	ginkgo.Context("validate that the ratings are computed from the orders stub and its failures surface", func() {
+
+		ginkgo.It("do", func() {
+			server, stub, err := ordersstub.NewServer(ordersstub.Options{})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			defer server.Close()
+			c := config.Current()
+			defer config.Set(c)
+			withStub := c
+			withStub.Person.OrdersURL = server.URL
+			config.Set(withStub)
+
+			service := NewPersonService(backends)
+			created, err := service.CreatePersons(ctx, &CreatePersonRequest{Name: "Ivan Petrov", Age: 30, Market: "il"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			stub.SetOrders(created.ID, []ordersstub.Order{{ID: 1, OrderID: 10, PersonID: created.ID, Rating: 5}, {ID: 2, OrderID: 11, PersonID: created.ID, Rating: 3}})
+
+			details, err := service.GetRatingByPersonIDWithStrategy(ctx, created.ID, MeanStrategy)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(details.OrderCount).To(gomega.Equal(int64(2)))
+			gomega.Expect(*details.Average).To(gomega.Equal(4.0))
+
+			gomega.Expect(stub.SetOptions(ordersstub.Options{ErrorRate: 1})).To(gomega.Succeed())
+			_, err = service.GetRatingByPersonIDWithStrategy(ctx, created.ID, MeanStrategy)
+			gomega.Expect(merry.Is(err, ErrOrdersService)).To(gomega.BeTrue())
+			gomega.Expect(merry.HTTPCode(err)).To(gomega.Equal(http.StatusBadGateway))
+
+			gomega.Expect(stub.SetOptions(ordersstub.Options{MalformedRate: 1})).To(gomega.Succeed())
+			_, err = service.GetRatingByPersonIDWithStrategy(ctx, created.ID, MeanStrategy)
+			gomega.Expect(merry.Is(err, ErrOrdersService)).To(gomega.BeTrue())
+		})
+	})
+})
@@ -1,25 +1,57 @@
 package person
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
 	"github.com/sirupsen/logrus"
 	"io/ioutil"
+	"os"
+	"time"
 )
 
 type InMemoryProvider interface {
-	GetInMemoryRatings() ([]Rating, error)
-	SetInMemoryRatings(ratings []Rating) error
+	GetInMemoryRatings(ctx context.Context) ([]Rating, error)
+	SetInMemoryRatings(ctx context.Context, ratings []Rating) error
+	// WrittenAt - when the worker last wrote the ratings
+	WrittenAt(ctx context.Context) (time.Time, error)
+	ForMarket(market string) InMemoryProvider
 }
 
 type Cache struct {
+	market string
 }
 
-var CacheInstance = &Cache{}
+// ForMarket - a cache kept in a file of its own per market
+func (c Cache) ForMarket(market string) InMemoryProvider {
+	return Cache{market: market}
+}
+
+func (c Cache) fileName() string {
+	if c.market == "" {
+		return "ratings.json"
+	}
+
+	return "ratings." + c.market + ".json"
+}
+
+const ratingsCache = "ratings"
 
-func (c Cache) GetInMemoryRatings() (rating []Rating, err error) {
-	file, _ := ioutil.ReadFile("ratings.json")
+func (c Cache) GetInMemoryRatings(ctx context.Context) (rating []Rating, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	file, readErr := ioutil.ReadFile(c.fileName())
 
 	err = json.Unmarshal([]byte(file), &rating)
+	switch {
+	case readErr != nil:
+		metrics.CacheRequests.Inc(ratingsCache, "ratings", metrics.CacheMiss)
+	case err != nil:
+		metrics.CacheRequests.Inc(ratingsCache, "ratings", metrics.CacheError)
+	default:
+		metrics.CacheRequests.Inc(ratingsCache, "ratings", metrics.CacheHit)
+	}
 	if err != nil {
 		return rating, err
 	}
@@ -27,9 +59,12 @@ func (c Cache) GetInMemoryRatings() (rating []Rating, err error) {
 	return rating,nil
 }
 
-func (c Cache) SetInMemoryRatings(ratings []Rating) error {
+func (c Cache) SetInMemoryRatings(ctx context.Context, ratings []Rating) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	file, _ := json.MarshalIndent(ratings, "", " ")
-	err := ioutil.WriteFile("ratings.json", file, 0644)
+	err := ioutil.WriteFile(c.fileName(), file, 0644)
 	if err != nil {
 		logrus.Error("unable write to file! ")
 		return err
@@ -37,3 +72,15 @@ func (c Cache) SetInMemoryRatings(ratings []Rating) error {
 
 	return nil
 }
+
+func (c Cache) WrittenAt(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	info, err := os.Stat(c.fileName())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return info.ModTime(), nil
+}
@@ -6,4 +6,9 @@ type CreatePersonRequest struct {
 	Height string `json:"height"`
 	Weight string `json:"weight"`
 	RatingUpdate bool `json:"rating_update"`
+	Market string `json:"market"`
+}
+
+type BatchRatingsRequest struct {
+	PersonIDs []int64 `json:"person_ids"`
 }
@@ -0,0 +1,291 @@
+package person
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/graphql"
+	"github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultGraphQLPersons = 20
+	maxGraphQLPersons     = 100
+	// maxGraphQLBodyBytes - the largest body of a POST query, the one of a GET is bounded by the header limit
+	maxGraphQLBodyBytes = 1 << 20
+)
+
+// GraphQL - executes queries of the schema
+//
+//	type Query {
+//	  person(id: Int!): Person
+//	  persons(first: Int = 20, after: Int, name: String, ratingUpdated: Boolean): [Person!]!
+//	}
+//	type Person {
+//	  id: Int!  name: String!  age: Int!  height: String  weight: String  ratingUpdated: Boolean!
+//	  market: String!  createdAt: String!  rating: Float
+//	}
+//
+// persons are ordered by id, after is the id of the last person of the previous page. the page is filtered and
+// limited by the repository, the persons of other pages aren't loaded. height and weight are null
+// for readers and the ratings of all the persons of a response are fetched with a single batch lookup.
+// the POST bodies are capped at maxGraphQLBodyBytes and the queries nested too deep are rejected by the parser
+func (h handler) GraphQL(w http.ResponseWriter, req *http.Request) {
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+
+	request := graphql.Request{}
+	if req.Method == http.MethodGet {
+		query := req.URL.Query()
+		request.Query, request.OperationName = query.Get("query"), query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+				h.render.JSON(w, http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "variables must be a JSON object"}}})
+				return
+			}
+		}
+	} else {
+		defer req.Body.Close()
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, maxGraphQLBodyBytes))
+		if err != nil {
+			h.render.JSON(w, http.StatusRequestEntityTooLarge, graphql.Response{Errors: []graphql.Error{{Message: "the request body is larger than " + strconv.Itoa(maxGraphQLBodyBytes) + " bytes"}}})
+			return
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			h.render.JSON(w, http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+			return
+		}
+	}
+	logrus.WithFields(logrus.Fields{"operation": request.OperationName}).Debug("graphql query")
+
+	document, err := graphql.Parse(request.Query)
+	if err != nil {
+		h.render.JSON(w, http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+		return
+	}
+	operation, err := document.Operation(request.OperationName)
+	if err != nil {
+		h.render.JSON(w, http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+		return
+	}
+
+	resolver := &graphQLResolver{
+		ctx:       req.Context(),
+		service:   service,
+		operation: operation,
+		variables: request.Variables,
+		ratings:   map[int64]BatchRating{},
+	}
+	data := resolver.execute()
+
+	h.render.JSON(w, http.StatusOK, graphql.Response{Data: data, Errors: resolver.errors})
+}
+
+// graphQLResolver - resolves a single operation, field errors null the field and are reported with their path
+type graphQLResolver struct {
+	ctx       context.Context
+	service   Service
+	operation *graphql.Operation
+	variables map[string]interface{}
+	// ratings - the batch rating lookups of the operation, by person id
+	ratings map[int64]BatchRating
+	errors  []graphql.Error
+}
+
+func (r *graphQLResolver) execute() *graphql.Object {
+	data := graphql.NewObject()
+	for _, field := range r.operation.Selections {
+		path := []interface{}{field.ResponseKey()}
+		switch field.Name {
+		case "person":
+			data.Set(field.ResponseKey(), r.person(field, path))
+		case "persons":
+			data.Set(field.ResponseKey(), r.persons(field, path))
+		case "__typename":
+			data.Set(field.ResponseKey(), "Query")
+		default:
+			r.fail(path, fmt.Sprintf("cannot query field %q on type Query", field.Name))
+		}
+	}
+
+	return data
+}
+
+func (r *graphQLResolver) person(field *graphql.Field, path []interface{}) interface{} {
+	arguments, err := r.operation.Arguments(field, r.variables)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	if !arguments.Has("id") {
+		return r.fail(path, "argument id is required")
+	}
+	id, err := arguments.Int("id", 0)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	if len(field.Selections) == 0 {
+		return r.fail(path, "field person of type Person must have a selection of subfields")
+	}
+
+	person, err := r.service.GetPersonByID(r.ctx, id)
+	if err != nil {
+		logrus.Error("graphql - person by id ", err)
+		return r.fail(path, "person not found")
+	}
+
+	if field.Selects("rating") {
+		r.loadRatings([]int64{person.ID})
+	}
+
+	return r.resolvePerson(field, *person, path)
+}
+
+func (r *graphQLResolver) persons(field *graphql.Field, path []interface{}) interface{} {
+	arguments, err := r.operation.Arguments(field, r.variables)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	first, err := arguments.Int("first", defaultGraphQLPersons)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	if first < 1 || first > maxGraphQLPersons {
+		return r.fail(path, fmt.Sprintf("argument first must be between 1 and %d", maxGraphQLPersons))
+	}
+	after, err := arguments.Int("after", 0)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	name, err := arguments.String("name", "")
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	ratingUpdated, err := arguments.Bool("ratingUpdated", false)
+	if err != nil {
+		return r.fail(path, err.Error())
+	}
+	if len(field.Selections) == 0 {
+		return r.fail(path, "field persons of type [Person!]! must have a selection of subfields")
+	}
+
+	filter := PersonFilter{AfterID: after, Name: name, Limit: int(first)}
+	if arguments.Has("ratingUpdated") {
+		filter.RatingUpdated = &ratingUpdated
+	}
+	page, err := r.service.FindPersons(r.ctx, filter)
+	if err != nil {
+		logrus.Error("graphql - persons ", err)
+		return r.fail(path, "couldn't get persons")
+	}
+
+	if field.Selects("rating") {
+		ids := make([]int64, 0, len(page))
+		for _, person := range page {
+			ids = append(ids, person.ID)
+		}
+		r.loadRatings(ids)
+	}
+
+	result := make([]interface{}, 0, len(page))
+	for i, person := range page {
+		result = append(result, r.resolvePerson(field, person, append(path, i)))
+	}
+
+	return result
+}
+
+func (r *graphQLResolver) resolvePerson(field *graphql.Field, person Person, path []interface{}) *graphql.Object {
+	fullView := canViewFullPerson(r.ctx)
+
+	object := graphql.NewObject()
+	for _, selection := range field.Selections {
+		key := selection.ResponseKey()
+		switch selection.Name {
+		case "id":
+			object.Set(key, person.ID)
+		case "name":
+			object.Set(key, person.Name)
+		case "age":
+			object.Set(key, person.Age)
+		case "height":
+			object.Set(key, maskedString(fullView, person.Height))
+		case "weight":
+			object.Set(key, maskedString(fullView, person.Weight))
+		case "ratingUpdated":
+			object.Set(key, person.RatingUpdated)
+		case "market":
+			object.Set(key, person.Market)
+		case "createdAt":
+			object.Set(key, person.CreatedAt.Format(time.RFC3339))
+		case "rating":
+			object.Set(key, r.rating(person.ID, append(path, key)))
+		case "__typename":
+			object.Set(key, "Person")
+		default:
+			object.Set(key, r.fail(append(path, key), fmt.Sprintf("cannot query field %q on type Person", selection.Name)))
+		}
+	}
+
+	return object
+}
+
+// loadRatings - fetch the ratings of the persons that were not looked up yet in a single batch
+func (r *graphQLResolver) loadRatings(ids []int64) {
+	missing := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := r.ratings[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	ratings, err := r.service.GetRatingsByPersonIDs(r.ctx, missing)
+	if err != nil {
+		logrus.Error("graphql - batch ratings ", err)
+	}
+	for _, id := range missing {
+		rating, ok := ratings[id]
+		if !ok {
+			rating = BatchRating{Status: BatchStatusError, Error: "rating lookup failed"}
+		}
+		r.ratings[id] = rating
+	}
+}
+
+func (r *graphQLResolver) rating(personID int64, path []interface{}) interface{} {
+	r.loadRatings([]int64{personID})
+
+	rating := r.ratings[personID]
+	if rating.Status == BatchStatusError {
+		return r.fail(path, rating.Error)
+	}
+	if rating.Rating == nil || rating.Rating.Average == nil {
+		return nil
+	}
+
+	return *rating.Rating.Average
+}
+
+// fail - report the field error, the field resolves to null
+func (r *graphQLResolver) fail(path []interface{}, message string) interface{} {
+	errorPath := make([]interface{}, len(path))
+	copy(errorPath, path)
+	r.errors = append(r.errors, graphql.Error{Message: message, Path: errorPath})
+	return nil
+}
+
+func maskedString(fullView bool, value string) interface{} {
+	if !fullView {
+		return nil
+	}
+
+	return value
+}
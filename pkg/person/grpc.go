@@ -0,0 +1,456 @@
+package person
+
+import (
+	"context"
+	"github.com/ansel1/merry"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	personv1 "github.com/gtforge/go-skeleton-draft/structure/api/proto/person/v1"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/gtforge/gorm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"time"
+)
+
+// createPersonMethod - the idempotency keys of the persons creations are scoped by the method
+const createPersonMethod = "/person.v1.PersonService/CreatePerson"
+
+type grpcServer struct {
+	service Service
+	keeper  *idempotency.Keeper
+}
+
+// NewGRPCServer - the person.v1.PersonService of the service, with the roles and the batch and search limits of the http
+// routes. the rate limit and the deadline of the calls are left to the interceptors of the server. the keeper replays
+// the persons creations carrying an idempotency key
+func NewGRPCServer(service Service, keeper *idempotency.Keeper) personv1.PersonServiceServer {
+	return &grpcServer{service: service, keeper: keeper}
+}
+
+// GRPCError - the status of a service error, mapped the way the http api maps it to a status code
+func GRPCError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case gorm.IsRecordNotFoundError(err), err == ErrNotRanked:
+		code = codes.NotFound
+	case err == ErrUnknownMarket, err == ErrUnknownRatingStrategy, err == ErrInvalidInterval, err == ErrEmptySearchQuery,
+		err == idempotency.ErrKeyTooLong:
+		code = codes.InvalidArgument
+	case err == idempotency.ErrKeyReused:
+		code = codes.FailedPrecondition
+	case err == idempotency.ErrKeyInFlight:
+		code = codes.Aborted
+	case merry.Is(err, ErrOrdersService):
+		code = codes.Unavailable
+	case err == context.DeadlineExceeded:
+		code = codes.DeadlineExceeded
+	case err == context.Canceled:
+		code = codes.Canceled
+	case merry.HTTPCode(err) == http.StatusBadRequest:
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, err.Error())
+}
+
+// marketService - the service scoped to the market of the call, an empty market is unscoped
+func (g *grpcServer) marketService(ctx context.Context, role, market string) (Service, error) {
+	if err := auth.RequireGRPC(ctx, role); err != nil {
+		return nil, err
+	}
+
+	market, err := NormalizeMarket(market)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+	if market == "" {
+		return g.service, nil
+	}
+
+	return g.service.ForMarket(market), nil
+}
+
+func (g *grpcServer) GetPersons(ctx context.Context, req *personv1.GetPersonsRequest) (*personv1.GetPersonsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	persons, err := service.GetPersons(ctx)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response := &personv1.GetPersonsResponse{Persons: make([]*personv1.Person, 0, len(persons))}
+	for i := range persons {
+		response.Persons = append(response.Persons, personProto(ctx, &persons[i]))
+	}
+
+	return response, nil
+}
+
+func (g *grpcServer) GetPerson(ctx context.Context, req *personv1.GetPersonRequest) (*personv1.Person, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := service.GetPersonByID(ctx, req.Id)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return personProto(ctx, person), nil
+}
+
+// CreatePerson - a call with the idempotency key of a previous successful call gets the person it created
+func (g *grpcServer) CreatePerson(ctx context.Context, req *personv1.CreatePersonRequest) (*personv1.Person, error) {
+	service, err := g.marketService(ctx, auth.RoleEditor, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := proto.Marshal(&personv1.CreatePersonRequest{
+		Market: req.Market, Name: req.Name, Age: req.Age, Height: req.Height, Weight: req.Weight, RatingUpdated: req.RatingUpdated,
+	})
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response, _, err := g.keeper.Do(ctx, createPersonMethod, req.IdempotencyKey, request, func() ([]byte, error) {
+		person, err := service.CreatePersons(ctx, &CreatePersonRequest{
+			Name: req.Name, Age: req.Age, Height: req.Height, Weight: req.Weight, RatingUpdate: req.RatingUpdated,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(personProto(ctx, person))
+	})
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	person := &personv1.Person{}
+	if err := proto.Unmarshal(response, person); err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return person, nil
+}
+
+func (g *grpcServer) UpdatePerson(ctx context.Context, req *personv1.UpdatePersonRequest) (*personv1.Person, error) {
+	service, err := g.marketService(ctx, auth.RoleEditor, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := service.UpdatePerson(ctx, req.Id, &CreatePersonRequest{
+		Name: req.Name, Age: req.Age, Height: req.Height, Weight: req.Weight, RatingUpdate: req.RatingUpdated,
+	})
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return personProto(ctx, person), nil
+}
+
+func (g *grpcServer) DeletePerson(ctx context.Context, req *personv1.DeletePersonRequest) (*personv1.DeletePersonResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleAdmin, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.DeletePerson(ctx, req.Id); err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return &personv1.DeletePersonResponse{Id: req.Id}, nil
+}
+
+func (g *grpcServer) SearchPersons(ctx context.Context, req *personv1.SearchPersonsRequest) (*personv1.SearchPersonsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int(req.Limit)
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "limit must be a number between 1 and %v", maxSearchLimit)
+	}
+
+	matches, err := service.SearchPersons(ctx, req.Query, req.Transliterate, limit)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response := &personv1.SearchPersonsResponse{Matches: make([]*personv1.PersonMatch, 0, len(matches))}
+	for i := range matches {
+		response.Matches = append(response.Matches, &personv1.PersonMatch{
+			Person:     personProto(ctx, &matches[i].Person),
+			Similarity: matches[i].Similarity,
+		})
+	}
+
+	return response, nil
+}
+
+func (g *grpcServer) GetRating(ctx context.Context, req *personv1.GetRatingRequest) (*personv1.RatingDetails, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	var details *RatingDetails
+	if req.Strategy != "" {
+		details, err = service.GetRatingByPersonIDWithStrategy(ctx, req.PersonId, req.Strategy)
+	} else {
+		details, err = service.GetRatingByPersonID(ctx, req.PersonId)
+	}
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return ratingDetailsProto(details), nil
+}
+
+func (g *grpcServer) GetRatings(ctx context.Context, req *personv1.GetRatingsRequest) (*personv1.GetRatingsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	unique := make([]int64, 0, len(req.PersonIds))
+	seen := make(map[int64]bool, len(req.PersonIds))
+	for _, id := range req.PersonIds {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "person_ids is required")
+	}
+	if len(unique) > maxBatchBodyIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "too many person_ids, the limit is %v", maxBatchBodyIDs)
+	}
+
+	ratings, err := service.GetRatingsByPersonIDs(ctx, unique)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response := &personv1.GetRatingsResponse{Ratings: make(map[int64]*personv1.BatchRating, len(ratings))}
+	for id, rating := range ratings {
+		batch := &personv1.BatchRating{Status: rating.Status, Error: rating.Error}
+		if rating.Rating != nil {
+			batch.Rating = doubleValue(rating.Rating.Average)
+		}
+		response.Ratings[id] = batch
+	}
+
+	return response, nil
+}
+
+func (g *grpcServer) GetRatingsByWaitingGroups(ctx context.Context, req *personv1.GetAllRatingsRequest) (*personv1.GetAllRatingsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := service.GetAllRatingsByWaitingGroups(ctx)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return allRatingsProto(ratings), nil
+}
+
+func (g *grpcServer) GetRatingsByChannels(ctx context.Context, req *personv1.GetAllRatingsRequest) (*personv1.GetAllRatingsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings, err := service.GetAllRatingsByChannels(ctx)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return allRatingsProto(ratings), nil
+}
+
+func (g *grpcServer) GetTopRatings(ctx context.Context, req *personv1.GetTopRatingsRequest) (*personv1.GetTopRatingsResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	n := req.N
+	if n == 0 {
+		n = defaultTopRatings
+	}
+	if n < 1 || n > maxTopRatings {
+		return nil, status.Errorf(codes.InvalidArgument, "n must be a number between 1 and %v", maxTopRatings)
+	}
+
+	ratings, err := service.GetTopRatings(ctx, n, !req.Ascending)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response := &personv1.GetTopRatingsResponse{Ratings: make([]*personv1.RankedRating, 0, len(ratings))}
+	for _, rating := range ratings {
+		response.Ratings = append(response.Ratings, &personv1.RankedRating{
+			Position: rating.Position,
+			PersonId: rating.PersonID,
+			Rating:   rating.Rating,
+		})
+	}
+
+	return response, nil
+}
+
+func (g *grpcServer) GetRatingRank(ctx context.Context, req *personv1.GetRatingRankRequest) (*personv1.RatingRank, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := service.GetRatingRank(ctx, req.PersonId)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	return &personv1.RatingRank{
+		PersonId:   rank.PersonID,
+		Rating:     rank.Rating,
+		Position:   rank.Position,
+		Total:      rank.Total,
+		Percentile: rank.Percentile,
+	}, nil
+}
+
+// GetRatingHistory - the last 30 days when from and to are unset, like the http route
+func (g *grpcServer) GetRatingHistory(ctx context.Context, req *personv1.GetRatingHistoryRequest) (*personv1.GetRatingHistoryResponse, error) {
+	service, err := g.marketService(ctx, auth.RoleReader, req.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		if to, err = ptypes.Timestamp(req.To); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid to: "+err.Error())
+		}
+	}
+	from := to.AddDate(0, 0, -defaultHistoryDays)
+	if req.From != nil {
+		if from, err = ptypes.Timestamp(req.From); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid from: "+err.Error())
+		}
+	}
+	if !from.Before(to) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	history, err := service.GetRatingHistory(ctx, req.PersonId, from, to, req.Interval)
+	if err != nil {
+		return nil, GRPCError(err)
+	}
+
+	response := &personv1.GetRatingHistoryResponse{Buckets: make([]*personv1.RatingHistoryBucket, 0, len(history))}
+	for _, bucket := range history {
+		response.Buckets = append(response.Buckets, &personv1.RatingHistoryBucket{
+			From:       timestampProto(bucket.From),
+			To:         timestampProto(bucket.To),
+			Average:    bucket.Average,
+			OrderCount: bucket.OrderCount,
+			Snapshots:  int32(bucket.Snapshots),
+		})
+	}
+
+	return response, nil
+}
+
+// personProto - the person as the caller of the call is allowed to see it
+func personProto(ctx context.Context, p *Person) *personv1.Person {
+	person := &personv1.Person{
+		Id:            p.ID,
+		Name:          p.Name,
+		Age:           p.Age,
+		RatingUpdated: p.RatingUpdated,
+		Market:        p.Market,
+		CreatedAt:     timestampProto(p.CreatedAt),
+	}
+	if canViewFullPerson(ctx) {
+		person.Height, person.Weight = p.Height, p.Weight
+	}
+
+	return person
+}
+
+func ratingDetailsProto(d *RatingDetails) *personv1.RatingDetails {
+	details := &personv1.RatingDetails{
+		PersonId:     d.PersonID,
+		Status:       d.Status,
+		Strategy:     d.Strategy,
+		Average:      doubleValue(d.Average),
+		OrderCount:   d.OrderCount,
+		Min:          doubleValue(d.Min),
+		Max:          doubleValue(d.Max),
+		Distribution: d.Distribution,
+		ComputedAt:   timestampProto(d.ComputedAt),
+		Source:       d.Source,
+		AgeSeconds:   float64(d.AgeSeconds),
+	}
+	if d.LastOrderAt != nil {
+		details.LastOrderAt = timestampProto(*d.LastOrderAt)
+	}
+
+	return details
+}
+
+func allRatingsProto(ratings []Rating) *personv1.GetAllRatingsResponse {
+	response := &personv1.GetAllRatingsResponse{Ratings: make([]*personv1.Rating, 0, len(ratings))}
+	for _, rating := range ratings {
+		response.Ratings = append(response.Ratings, &personv1.Rating{PersonId: rating.PersonID, Rating: rating.Rating})
+	}
+
+	return response
+}
+
+// doubleValue - unset for a missing value
+func doubleValue(value *float64) *personv1.DoubleValue {
+	if value == nil {
+		return nil
+	}
+
+	return &personv1.DoubleValue{Value: *value}
+}
+
+// timestampProto - unset for the zero time
+func timestampProto(t time.Time) *timestamp.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	ts, err := ptypes.TimestampProto(t)
+	if err != nil {
+		return nil
+	}
+
+	return ts
+}
@@ -0,0 +1,139 @@
+package person
+
+import (
+	"context"
+	personv1 "github.com/gtforge/go-skeleton-draft/structure/api/proto/person/v1"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"time"
+)
+
+var _ = ginkgo.Describe("grpc server", func() {
+
+	var (
+		secret     = "secret"
+		server     *grpc.Server
+		connection *grpc.ClientConn
+		client     personv1.PersonServiceClient
+	)
+
+	// call - the context of a caller holding the role
+	call := func(role string) context.Context {
+		token, _ := auth.SignJWT(auth.Claims{Subject: "orders", Roles: []string{role}, ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte(secret))
+		return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	}
+
+	code := func(err error) codes.Code {
+		return status.Code(err)
+	}
+
+	ginkgo.BeforeEach(func() {
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		config.Set(c)
+
+		listener := bufconn.Listen(1 << 20)
+		authenticator := auth.NewAuthenticator(true, secret, nil, time.Minute)
+		server = grpc.NewServer(grpc.UnaryInterceptor(authenticator.UnaryInterceptor))
+		keeper := idempotency.NewKeeper(idempotency.NewMemoryStore(), time.Hour, time.Minute)
+		personv1.RegisterPersonServiceServer(server, NewGRPCServer(NewPersonService(NewMemoryBackends(nil)), keeper))
+		go server.Serve(listener)
+
+		var err error
+		connection, err = grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithDialer(func(string, time.Duration) (net.Conn, error) {
+			return listener.Dial()
+		}))
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+		client = personv1.NewPersonServiceClient(connection)
+	})
+
+	ginkgo.AfterEach(func() {
+		connection.Close()
+		server.Stop()
+		config.Set(config.Defaults())
+	})
+
+	ginkgo.Context("validate that an editor creates a person a reader reads without the measurements", func() {
+
+		ginkgo.It("do", func() {
+			created, err := client.CreatePerson(call(auth.RoleEditor), &personv1.CreatePersonRequest{Market: "uk", Name: "John Smith", Age: 40, Height: "180"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(created.Market).To(gomega.Equal("UK"))
+			gomega.Expect(created.Height).To(gomega.Equal("180"))
+
+			person, err := client.GetPerson(call(auth.RoleReader), &personv1.GetPersonRequest{Id: created.Id})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(person.Name).To(gomega.Equal("John Smith"))
+			gomega.Expect(person.Height).To(gomega.BeEmpty())
+
+			persons, err := client.GetPersons(call(auth.RoleReader), &personv1.GetPersonsRequest{Market: "IL"})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(persons.Persons).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("validate that a retry with the same idempotency key gets the created person back", func() {
+
+		ginkgo.It("do", func() {
+			request := &personv1.CreatePersonRequest{Name: "Ivan Petrov", Age: 30, IdempotencyKey: "create-1"}
+			created, err := client.CreatePerson(call(auth.RoleEditor), request)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			replayed, err := client.CreatePerson(call(auth.RoleEditor), request)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(replayed.Id).To(gomega.Equal(created.Id))
+
+			persons, err := client.GetPersons(call(auth.RoleReader), &personv1.GetPersonsRequest{})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(persons.Persons).To(gomega.HaveLen(1))
+
+			_, err = client.CreatePerson(call(auth.RoleEditor), &personv1.CreatePersonRequest{Name: "Other", IdempotencyKey: "create-1"})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.FailedPrecondition))
+		})
+	})
+
+	ginkgo.Context("validate that the service errors map to status codes", func() {
+
+		ginkgo.It("do", func() {
+			_, err := client.GetPerson(call(auth.RoleReader), &personv1.GetPersonRequest{Id: 42})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.NotFound))
+
+			_, err = client.GetPersons(call(auth.RoleReader), &personv1.GetPersonsRequest{Market: "FR"})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.InvalidArgument))
+
+			_, err = client.GetRating(call(auth.RoleReader), &personv1.GetRatingRequest{PersonId: 42, Strategy: "median"})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.InvalidArgument))
+
+			_, err = client.GetRatingRank(call(auth.RoleReader), &personv1.GetRatingRankRequest{PersonId: 42})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.NotFound))
+
+			_, err = client.SearchPersons(call(auth.RoleReader), &personv1.SearchPersonsRequest{Query: "!!"})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.InvalidArgument))
+
+			_, err = client.GetTopRatings(call(auth.RoleReader), &personv1.GetTopRatingsRequest{N: maxTopRatings + 1})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.InvalidArgument))
+		})
+	})
+
+	ginkgo.Context("validate that calls without credentials or the role are rejected", func() {
+
+		ginkgo.It("do", func() {
+			_, err := client.GetPersons(context.Background(), &personv1.GetPersonsRequest{})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.Unauthenticated))
+
+			_, err = client.CreatePerson(call(auth.RoleReader), &personv1.CreatePersonRequest{Name: "Ivan Petrov"})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.PermissionDenied))
+
+			_, err = client.DeletePerson(call(auth.RoleEditor), &personv1.DeletePersonRequest{Id: 1})
+			gomega.Expect(code(err)).To(gomega.Equal(codes.PermissionDenied))
+		})
+	})
+})
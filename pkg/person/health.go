@@ -0,0 +1,51 @@
+package person
+
+import (
+	"context"
+	"fmt"
+	"github.com/gtforge/go-healthcheck"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/health"
+	"time"
+)
+
+// OrdersPinger - reach the orders service of every market, each with the timeout
+func OrdersPinger(timeout time.Duration) healthcheck.Pinger {
+	pingers := make([]healthcheck.Pinger, 0)
+	for _, market := range Markets() {
+		pingers = append(pingers, health.HTTPPinger("orders_"+market, ordersURL(market)+"/alive", timeout))
+	}
+
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		response := map[string]interface{}{}
+		var failed error
+		for _, pinger := range pingers {
+			values, err := pinger(ctx)
+			if err != nil && failed == nil {
+				failed = err
+			}
+			for k, v := range values {
+				response[k] = v
+			}
+		}
+
+		return response, failed
+	}
+}
+
+// RatingsCachePinger - fail when the worker hasn't written the ratings cache for maxAge
+func RatingsCachePinger(cache InMemoryProvider, maxAge time.Duration) healthcheck.Pinger {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		writtenAt, err := cache.WrittenAt(ctx)
+		if err != nil {
+			return map[string]interface{}{"ratings_cache": err.Error()}, err
+		}
+
+		age := time.Since(writtenAt)
+		response := map[string]interface{}{"ratings_cache": map[string]interface{}{"age_seconds": int64(age.Seconds())}}
+		if age > maxAge {
+			return response, fmt.Errorf("ratings cache is stale, written %v ago", age.Truncate(time.Second))
+		}
+
+		return response, nil
+	}
+}
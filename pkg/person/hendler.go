@@ -1,39 +1,87 @@
 package person
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/ansel1/merry"
 	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/idempotency"
+	"github.com/gtforge/gorm"
 	"github.com/sirupsen/logrus"
 	"github.com/unrolled/render"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTopRatings = 10
+	maxTopRatings     = 100
+
+	defaultHistoryDays = 30
+
+	maxBatchQueryIDs = 100
+	maxBatchBodyIDs  = 1000
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
 )
 
 type handler struct {
 	service Service
+	stream  RatingStreamProvider
+	keeper  *idempotency.Keeper
 	render  *render.Render
 }
 
-func NewHandler(service Service) *handler {
+// NewHandler - the stream must be the one of the service, the keeper replays the persons creations
+func NewHandler(service Service, stream RatingStreamProvider, keeper *idempotency.Keeper) *handler {
 	return &handler{
 		service: service,
+		stream:  stream,
+		keeper:  keeper,
 		render:  render.New(),
 	}
 }
 
+// RegisterRoutes - the routes are served globally and under /markets/{market}, the market may also come from the X-Market header.
+// reads need the reader role, creating and updating persons the editor role, deleting them and rebuilding the leaderboard
+// the admin role.
+// creating a person honours the Idempotency-Key header
 func (h handler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/persons", h.GetPersons).Methods(http.MethodGet)
-	router.HandleFunc("/person/{id:[0-9]+}", h.GetPersonByID).Methods(http.MethodGet)
-	router.HandleFunc("/rating/{id:[0-9]+}", h.GetRatingByPersonID).Methods(http.MethodGet)
-	router.HandleFunc("/ratings/groups", h.GetAllRatingsByWaitingGroups).Methods(http.MethodGet)
-	router.HandleFunc("/ratings/channels", h.GetAllRatingsByChannels).Methods(http.MethodGet)
-	router.HandleFunc("/person", h.CreatePerson).Methods(http.MethodPost)
-	router.HandleFunc("/update_person/{id:[0-9]+}", h.UpdatePerson).Methods(http.MethodPut)
-	router.HandleFunc("/delete_person/{id:[0-9]+}", h.DeletePerson).Methods(http.MethodDelete)
+	h.registerRoutes(router)
+	h.registerRoutes(router.PathPrefix("/markets/{market:[A-Za-z]{2}}").Subrouter())
+}
+
+func (h handler) registerRoutes(router *mux.Router) {
+	router.HandleFunc("/persons", auth.Require(auth.RoleReader, h.GetPersons)).Methods(http.MethodGet)
+	router.HandleFunc("/persons/search", auth.Require(auth.RoleReader, h.SearchPersons)).Methods(http.MethodGet)
+	router.HandleFunc("/person/{id:[0-9]+}", auth.Require(auth.RoleReader, h.GetPersonByID)).Methods(http.MethodGet)
+	router.HandleFunc("/rating/{id:[0-9]+}", auth.Require(auth.RoleReader, h.GetRatingByPersonID)).Methods(http.MethodGet)
+	router.HandleFunc("/ratings", auth.Require(auth.RoleReader, h.GetRatingsByPersonIDs)).Methods(http.MethodGet)
+	router.HandleFunc("/ratings", auth.Require(auth.RoleReader, h.PostRatingsByPersonIDs)).Methods(http.MethodPost)
+	router.HandleFunc("/ratings/groups", auth.Require(auth.RoleReader, h.GetAllRatingsByWaitingGroups)).Methods(http.MethodGet)
+	router.HandleFunc("/ratings/channels", auth.Require(auth.RoleReader, h.GetAllRatingsByChannels)).Methods(http.MethodGet)
+	router.HandleFunc("/ratings/stream", auth.Require(auth.RoleReader, h.GetRatingStream)).Methods(http.MethodGet)
+	router.HandleFunc("/ratings/top", auth.Require(auth.RoleReader, h.GetTopRatings)).Methods(http.MethodGet)
+	router.HandleFunc("/rating/{id:[0-9]+}/rank", auth.Require(auth.RoleReader, h.GetRatingRank)).Methods(http.MethodGet)
+	router.HandleFunc("/rating/{id:[0-9]+}/history", auth.Require(auth.RoleReader, h.GetRatingHistory)).Methods(http.MethodGet)
+	router.HandleFunc("/graphql", auth.Require(auth.RoleReader, h.GraphQL)).Methods(http.MethodGet, http.MethodPost)
+	router.HandleFunc("/person", auth.Require(auth.RoleEditor, h.keeper.Wrap(h.CreatePerson))).Methods(http.MethodPost)
+	router.HandleFunc("/update_person/{id:[0-9]+}", auth.Require(auth.RoleEditor, h.UpdatePerson)).Methods(http.MethodPut)
+	router.HandleFunc("/delete_person/{id:[0-9]+}", auth.Require(auth.RoleAdmin, h.DeletePerson)).Methods(http.MethodDelete)
+	router.HandleFunc("/admin/leaderboard/rebuild", auth.Require(auth.RoleAdmin, h.RebuildLeaderboard)).Methods(http.MethodPost)
 }
 
 func (h handler) CreatePerson(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("create person")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
 	defer req.Body.Close()
 	createPersonRequest := &CreatePersonRequest{}
 	decoder := json.NewDecoder(req.Body)
@@ -43,30 +91,80 @@ func (h handler) CreatePerson(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	person, err := h.service.CreatePersons(createPersonRequest)
+	person, err := service.CreatePersons(req.Context(), createPersonRequest)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusConflict)
 		return
 	}
 
-	h.render.JSON(w, http.StatusCreated, person)
+	h.render.JSON(w, http.StatusCreated, personView(req.Context(), person))
 }
 
 func (h handler) GetPersons(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get persons")
-	persons, err := h.service.GetPersons()
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	persons, err := service.GetPersons(req.Context())
 
 	if err != nil {
 		h.render.JSON(w, http.StatusNotFound, err)
 		return
 	}
-	h.render.JSON(w, http.StatusOK, persons)
+	h.render.JSON(w, http.StatusOK, personsView(req.Context(), persons))
+}
+
+// SearchPersons - ?q= is matched in its script and in latin spelling, ?transliterate=false keeps it to its script
+func (h handler) SearchPersons(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"query": req.URL.Query()}).Debug("search persons")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	query := req.URL.Query()
+
+	limit := defaultSearchLimit
+	if stringLimit := query.Get("limit"); stringLimit != "" {
+		parsed, err := strconv.Atoi(stringLimit)
+		if err != nil || parsed < 1 || parsed > maxSearchLimit {
+			http.Error(w, "limit must be a number between 1 and "+strconv.Itoa(maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	transliterated := true
+	if stringTransliterate := query.Get("transliterate"); stringTransliterate != "" {
+		parsed, err := strconv.ParseBool(stringTransliterate)
+		if err != nil {
+			http.Error(w, "transliterate must be true or false", http.StatusBadRequest)
+			return
+		}
+		transliterated = parsed
+	}
+
+	matches, err := service.SearchPersons(req.Context(), query.Get("q"), transliterated, limit)
+
+	if err == ErrEmptySearchQuery {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.renderError(w, err)
+		return
+	}
+	h.render.JSON(w, http.StatusOK, matchesView(req.Context(), matches))
 }
 
 func (h handler) GetAllRatingsByWaitingGroups(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get all ratings")
-	persons, err := h.service.GetAllRatingsByWaitingGroups()
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	persons, err := service.GetAllRatingsByWaitingGroups(req.Context())
 
 	if err != nil {
 		h.render.JSON(w, http.StatusNotFound, err)
@@ -77,7 +175,11 @@ func (h handler) GetAllRatingsByWaitingGroups(w http.ResponseWriter, req *http.R
 
 func (h handler) GetAllRatingsByChannels(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get all ratings")
-	persons, err := h.service.GetAllRatingsByChannels()
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	persons, err := service.GetAllRatingsByChannels(req.Context())
 
 	if err != nil {
 		h.render.JSON(w, http.StatusNotFound, err)
@@ -88,36 +190,230 @@ func (h handler) GetAllRatingsByChannels(w http.ResponseWriter, req *http.Reques
 
 func (h handler) GetPersonByID(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get person by id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
 	params := mux.Vars(req)
 	stringId := params["id"]
 	id, _ := strconv.ParseInt(stringId, 10, 64)
 
-	person, err := h.service.GetPersonByID(id)
+	person, err := service.GetPersonByID(req.Context(), id)
 
 	if err != nil {
 		h.render.JSON(w, http.StatusNotFound, err)
 		return
 	}
-	h.render.JSON(w, http.StatusOK, person)
+	h.render.JSON(w, http.StatusOK, personView(req.Context(), person))
 }
 
 func (h handler) GetRatingByPersonID(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get rating by person id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
 	params := mux.Vars(req)
 	stringId := params["id"]
 	id, _ := strconv.ParseInt(stringId, 10, 64)
 
-	person, err := h.service.GetRatingByPersonID(id)
+	var rating *RatingDetails
+	var err error
+	if strategy := req.URL.Query().Get("strategy"); strategy != "" {
+		rating, err = service.GetRatingByPersonIDWithStrategy(req.Context(), id, strategy)
+	} else {
+		rating, err = service.GetRatingByPersonID(req.Context(), id)
+	}
+
+	if err != nil {
+		h.renderError(w, err)
+		return
+	}
+	h.render.JSON(w, http.StatusOK, rating)
+}
+
+func (h handler) GetTopRatings(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"query": req.URL.Query()}).Debug("get top ratings")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	query := req.URL.Query()
+
+	n := int64(defaultTopRatings)
+	if stringN := query.Get("n"); stringN != "" {
+		parsed, err := strconv.ParseInt(stringN, 10, 64)
+		if err != nil || parsed < 1 || parsed > maxTopRatings {
+			http.Error(w, "n must be a number between 1 and "+strconv.Itoa(maxTopRatings), http.StatusBadRequest)
+			return
+		}
+		n = parsed
+	}
+
+	desc := true
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		desc = false
+	default:
+		http.Error(w, "order must be asc or desc", http.StatusBadRequest)
+		return
+	}
+
+	ratings, err := service.GetTopRatings(req.Context(), n, desc)
 
 	if err != nil {
 		h.render.JSON(w, http.StatusNotFound, err)
 		return
 	}
-	h.render.JSON(w, http.StatusOK, person)
+	h.render.JSON(w, http.StatusOK, ratings)
+}
+
+func (h handler) GetRatingRank(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("get rating rank by person id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	params := mux.Vars(req)
+	stringId := params["id"]
+	id, _ := strconv.ParseInt(stringId, 10, 64)
+
+	rank, err := service.GetRatingRank(req.Context(), id)
+
+	if err != nil {
+		h.render.Text(w, http.StatusNotFound, err.Error())
+		return
+	}
+	h.render.JSON(w, http.StatusOK, rank)
+}
+
+func (h handler) GetRatingHistory(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req), "query": req.URL.Query()}).Debug("get rating history by person id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	params := mux.Vars(req)
+	stringId := params["id"]
+	id, _ := strconv.ParseInt(stringId, 10, 64)
+	query := req.URL.Query()
+	market, _ := requestMarket(req)
+
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		parsed, err := parseHistoryTime(value, market)
+		if err != nil {
+			http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -defaultHistoryDays)
+	if value := query.Get("from"); value != "" {
+		parsed, err := parseHistoryTime(value, market)
+		if err != nil {
+			http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	history, err := service.GetRatingHistory(req.Context(), id, from, to, query.Get("interval"))
+
+	if err == ErrInvalidInterval {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.render.JSON(w, http.StatusNotFound, err)
+		return
+	}
+	h.render.JSON(w, http.StatusOK, history)
+}
+
+func (h handler) GetRatingsByPersonIDs(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"query": req.URL.Query()}).Debug("get ratings by person ids")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+
+	ids := make([]int64, 0)
+	for _, stringId := range strings.Split(req.URL.Query().Get("person_ids"), ",") {
+		stringId = strings.TrimSpace(stringId)
+		if stringId == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(stringId, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid person id: "+stringId, http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	h.renderBatchRatings(req.Context(), w, service, ids, maxBatchQueryIDs)
+}
+
+func (h handler) PostRatingsByPersonIDs(w http.ResponseWriter, req *http.Request) {
+	logrus.Debug("post ratings by person ids")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	defer req.Body.Close()
+	batchRatingsRequest := &BatchRatingsRequest{}
+	decoder := json.NewDecoder(req.Body)
+
+	if err := decoder.Decode(&batchRatingsRequest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.renderBatchRatings(req.Context(), w, service, batchRatingsRequest.PersonIDs, maxBatchBodyIDs)
+}
+
+func (h handler) renderBatchRatings(ctx context.Context, w http.ResponseWriter, service Service, ids []int64, max int) {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) == 0 {
+		http.Error(w, "person_ids is required", http.StatusBadRequest)
+		return
+	}
+	if len(unique) > max {
+		http.Error(w, "too many person_ids, the limit is "+strconv.Itoa(max), http.StatusBadRequest)
+		return
+	}
+
+	ratings, err := service.GetRatingsByPersonIDs(ctx, unique)
+
+	if err != nil {
+		h.renderError(w, err)
+		return
+	}
+	h.render.JSON(w, http.StatusOK, ratings)
 }
 
 func (h handler) UpdatePerson(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("update person by id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
 	defer req.Body.Close()
 
 	createPersonRequest := &CreatePersonRequest{}
@@ -132,24 +428,28 @@ func (h handler) UpdatePerson(w http.ResponseWriter, req *http.Request) {
 	stringId := params["id"]
 	id, _ := strconv.ParseInt(stringId, 10, 64)
 
-	person, err := h.service.UpdatePerson(id, createPersonRequest)
+	person, err := service.UpdatePerson(req.Context(), id, createPersonRequest)
 
 	if err != nil {
 		h.render.Text(w, http.StatusNotFound, err.Error())
 		return
 	}
-	h.render.JSON(w, http.StatusCreated, person)
+	h.render.JSON(w, http.StatusCreated, personView(req.Context(), person))
 }
 
 func (h handler) DeletePerson(w http.ResponseWriter, req *http.Request) {
 	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("delete person by id")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
 	defer req.Body.Close()
 
 	params := mux.Vars(req)
 	stringId := params["id"]
 	id, _ := strconv.ParseInt(stringId, 10, 64)
 
-	err := h.service.DeletePerson(id)
+	err := service.DeletePerson(req.Context(), id)
 
 	if err != nil {
 		h.render.Text(w, http.StatusNotFound, err.Error())
@@ -158,3 +458,61 @@ func (h handler) DeletePerson(w http.ResponseWriter, req *http.Request) {
 	h.render.JSON(w, http.StatusOK, "person with id: "+stringId)
 
 }
+
+// RebuildLeaderboard - rank the persons of postgres again without waiting for the worker, e.g. after the redis of the
+// leaderboard was flushed
+func (h handler) RebuildLeaderboard(w http.ResponseWriter, req *http.Request) {
+	logrus.WithFields(logrus.Fields{"vars": mux.Vars(req)}).Debug("rebuild the ratings leaderboard")
+	service, ok := h.marketService(w, req)
+	if !ok {
+		return
+	}
+	auth.Logger(req.Context()).Info("rebuilding the ratings leaderboard")
+
+	err := service.RebuildLeaderboard(req.Context())
+
+	if err != nil {
+		h.renderError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// renderError - respond with the skeleton api error format and a status derived from the error
+func (h handler) renderError(w http.ResponseWriter, err error) {
+	code := merry.HTTPCode(err)
+	switch {
+	case err == ErrUnknownRatingStrategy:
+		code = http.StatusBadRequest
+	case gorm.IsRecordNotFoundError(err):
+		code = http.StatusNotFound
+	case err == context.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	}
+
+	h.render.JSON(w, code, skeleton.NewAPIError(http.StatusText(code), err))
+}
+
+// marketService - the service scoped to the market of the request, writes a 400 for unknown markets
+func (h handler) marketService(w http.ResponseWriter, req *http.Request) (Service, bool) {
+	market, err := requestMarket(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if market == "" {
+		return h.service, true
+	}
+
+	return h.service.ForMarket(market), true
+}
+
+// requestMarket - the market from the path segment or the X-Market header, empty when the request is global
+func requestMarket(req *http.Request) (string, error) {
+	market := mux.Vars(req)["market"]
+	if market == "" {
+		market = req.Header.Get(MarketHeader)
+	}
+
+	return NormalizeMarket(market)
+}
@@ -0,0 +1,194 @@
+package person
+
+import (
+	"context"
+	"errors"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/redis.v5"
+	"math"
+	"strconv"
+)
+
+const leaderboardKey = "ratings:leaderboard"
+
+var ErrNotRanked = errors.New("person is not ranked")
+
+type LeaderboardProvider interface {
+	SetRatings(ctx context.Context, ratings []Rating) error
+	GetTop(ctx context.Context, n int64, desc bool) ([]RankedRating, error)
+	GetRank(ctx context.Context, personID int64) (*RatingRank, error)
+	RemovePerson(ctx context.Context, personIDs ...int64) error
+	Count(ctx context.Context) (int64, error)
+	ForMarket(market string) LeaderboardProvider
+}
+
+// Leaderboard keeps the computed ratings in a redis sorted set scored by rating,
+// a market leaderboard also feeds the global one
+type Leaderboard struct {
+	client *gettStorages.GtRedisClient
+	market string
+}
+
+func NewLeaderboard(client *gettStorages.GtRedisClient) Leaderboard {
+	return Leaderboard{client: client}
+}
+
+func (l Leaderboard) ForMarket(market string) LeaderboardProvider {
+	return Leaderboard{client: l.client, market: market}
+}
+
+func (l Leaderboard) key() string {
+	return marketKey(l.market, leaderboardKey)
+}
+
+// keys - the sorted sets written on updates
+func (l Leaderboard) keys() []string {
+	if l.market == "" {
+		return []string{leaderboardKey}
+	}
+
+	return []string{l.key(), leaderboardKey}
+}
+
+func (l Leaderboard) SetRatings(ctx context.Context, ratings []Rating) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	members := make([]redis.Z, 0, len(ratings))
+	for _, r := range ratings {
+		members = append(members, redis.Z{Score: r.Rating, Member: strconv.FormatInt(r.PersonID, 10)})
+	}
+
+	pipe := l.client.Pipeline()
+	for _, key := range l.keys() {
+		pipe.ZAdd(key, members...)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		logrus.Error("unable to update the ratings leaderboard ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (l Leaderboard) GetTop(ctx context.Context, n int64, desc bool) ([]RankedRating, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var cmd *redis.ZSliceCmd
+	if desc {
+		cmd = l.client.ZRevRangeWithScores(l.key(), 0, n-1)
+	} else {
+		cmd = l.client.ZRangeWithScores(l.key(), 0, n-1)
+	}
+
+	members, err := cmd.Result()
+	if err != nil {
+		logrus.Error("couldn't get the ratings leaderboard ", err)
+		return nil, err
+	}
+
+	ranked := make([]RankedRating, 0, len(members))
+	for i, m := range members {
+		id, err := strconv.ParseInt(m.Member.(string), 10, 64)
+		if err != nil {
+			logrus.Error("invalid leaderboard member ", err)
+			continue
+		}
+		ranked = append(ranked, RankedRating{Position: int64(i) + 1, PersonID: id, Rating: m.Score})
+	}
+
+	return ranked, nil
+}
+
+func (l Leaderboard) GetRank(ctx context.Context, personID int64) (*RatingRank, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	member := strconv.FormatInt(personID, 10)
+
+	rank, err := l.client.ZRevRank(l.key(), member).Result()
+	if err == redis.Nil {
+		return nil, ErrNotRanked
+	}
+	if err != nil {
+		logrus.Error("couldn't get the person rank ", err)
+		return nil, err
+	}
+
+	score, err := l.client.ZScore(l.key(), member).Result()
+	if err != nil {
+		logrus.Error("couldn't get the person score ", err)
+		return nil, err
+	}
+
+	total, err := l.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	position := rank + 1
+	return &RatingRank{
+		PersonID:   personID,
+		Rating:     score,
+		Position:   position,
+		Total:      total,
+		Percentile: percentile(position, total),
+	}, nil
+}
+
+// RemovePerson - take the persons off the leaderboard
+func (l Leaderboard) RemovePerson(ctx context.Context, personIDs ...int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if len(personIDs) == 0 {
+		return nil
+	}
+
+	members := make([]interface{}, 0, len(personIDs))
+	for _, id := range personIDs {
+		members = append(members, strconv.FormatInt(id, 10))
+	}
+
+	pipe := l.client.Pipeline()
+	for _, key := range l.keys() {
+		pipe.ZRem(key, members...)
+	}
+
+	if _, err := pipe.Exec(); err != nil {
+		logrus.Error("can't remove person from the leaderboard ", err)
+		return err
+	}
+
+	return nil
+}
+
+func (l Leaderboard) Count(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	count, err := l.client.ZCard(l.key()).Result()
+	if err != nil {
+		logrus.Error("couldn't count the ratings leaderboard ", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// percentile - the share of ranked persons this position is rated at or above
+func percentile(position, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	p := float64(total-position+1) / float64(total) * 100
+	return math.Round(p*100) / 100
+}
@@ -0,0 +1,123 @@
+package person
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ordersstub"
+	"github.com/gtforge/gorm"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+)
+
+var _ = ginkgo.Describe("ratings leaderboard", func() {
+
+	var (
+		ctx                  context.Context
+		ctrl                 *gomock.Controller
+		personRepositoryMock *MockPersonRepository
+		personService        *PersonService
+		server               *httptest.Server
+		stub                 *ordersstub.Stub
+		previous             config.Config
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		server, stub, err = ordersstub.NewServer(ordersstub.Options{})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		previous = config.Current()
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		c.Person.OrdersURL = server.URL
+		config.Set(c)
+
+		ctx = context.Background()
+		ctrl = gomock.NewController(ginkgo.GinkgoT())
+		personRepositoryMock = NewMockPersonRepository(ctrl)
+		personRepositoryMock.EXPECT().GetLastRatingSnapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, gorm.ErrRecordNotFound).AnyTimes()
+		personRepositoryMock.EXPECT().CreateRatingSnapshot(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		personService = &PersonService{
+			repository:  personRepositoryMock,
+			store:       missingStore{},
+			cache:       emptyCache{},
+			leaderboard: NewMemoryLeaderboard(),
+		}
+	})
+
+	ginkgo.AfterEach(func() {
+		ctrl.Finish()
+		server.Close()
+		config.Set(previous)
+	})
+
+	ginkgo.Context("validate that the rebuild ranks the rated persons by market and takes the persons without ratings off", func() {
+
+		ginkgo.BeforeEach(func() {
+			personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return([]Person{
+				{ID: 1, Market: "IL"}, {ID: 2, Market: "IL"}, {ID: 3, Market: "UK"}, {ID: 4, Market: "IL"},
+			}, nil)
+			stub.SetOrders(1, []ordersstub.Order{{ID: 1, Rating: 5}, {ID: 2, Rating: 4}})
+			stub.SetOrders(2, []ordersstub.Order{{ID: 3, Rating: 3}})
+			stub.SetOrders(3, []ordersstub.Order{})
+			stub.SetOrders(4, []ordersstub.Order{{ID: 4, Rating: 5}})
+
+			// the person lost its ratings since the last run
+			gomega.Expect(personService.leaderboard.ForMarket("UK").SetRatings(ctx, []Rating{{3, 4}})).To(gomega.Succeed())
+		})
+
+		ginkgo.It("do", func() {
+			gomega.Expect(personService.RebuildLeaderboard(ctx)).To(gomega.Succeed())
+
+			top, err := personService.ForMarket("IL").GetTopRatings(ctx, 10, true)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(top).To(gomega.Equal([]RankedRating{{1, 4, 5}, {2, 1, 4.5}, {3, 2, 3}}))
+
+			top, err = personService.ForMarket("IL").GetTopRatings(ctx, 1, false)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(top).To(gomega.Equal([]RankedRating{{1, 2, 3}}))
+
+			rank, err := personService.ForMarket("IL").GetRatingRank(ctx, 1)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(*rank).To(gomega.Equal(RatingRank{PersonID: 1, Rating: 4.5, Position: 2, Total: 3, Percentile: 66.67}))
+
+			_, err = personService.ForMarket("UK").GetRatingRank(ctx, 3)
+			gomega.Expect(err).To(gomega.Equal(ErrNotRanked))
+			_, err = personService.GetRatingRank(ctx, 3)
+			gomega.Expect(err).To(gomega.Equal(ErrNotRanked))
+		})
+
+		ginkgo.It("do through the admin route", func() {
+			router := mux.NewRouter()
+			NewHandler(personService, nil, nil).RegisterRoutes(router)
+
+			req := httptest.NewRequest(http.MethodPost, "/admin/leaderboard/rebuild", nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req.WithContext(auth.WithIdentity(ctx, auth.Identity{Subject: "backoffice", Roles: []string{auth.RoleEditor}})))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusForbidden))
+
+			recorder = httptest.NewRecorder()
+			router.ServeHTTP(recorder, req.WithContext(auth.WithIdentity(ctx, auth.Identity{Subject: "backoffice", Roles: []string{auth.RoleAdmin}})))
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusNoContent))
+
+			rank, err := personService.ForMarket("IL").GetRatingRank(ctx, 4)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(rank.Position).To(gomega.Equal(int64(1)))
+		})
+	})
+
+	ginkgo.Context("validate that the percentile is the share of the ranked persons the position is rated at or above", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(percentile(1, 4)).To(gomega.Equal(100.0))
+			gomega.Expect(percentile(4, 4)).To(gomega.Equal(25.0))
+			gomega.Expect(percentile(2, 3)).To(gomega.Equal(66.67))
+			gomega.Expect(percentile(1, 0)).To(gomega.Equal(0.0))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+package person
+
+import (
+	"errors"
+	"github.com/gtforge/global_services_common_go/gett-config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const MarketHeader = "X-Market"
+
+var ErrUnknownMarket = errors.New("unknown market")
+
+// Markets - the markets configured under global.env in base.yml
+func Markets() []string {
+	return config.Current().Markets
+}
+
+// NormalizeMarket - validate the market against the configured ones, an empty market stays unscoped
+func NormalizeMarket(market string) (string, error) {
+	if market == "" {
+		return "", nil
+	}
+
+	market = strings.ToUpper(market)
+	for _, m := range Markets() {
+		if m == market {
+			return market, nil
+		}
+	}
+
+	return "", ErrUnknownMarket
+}
+
+// DefaultMarket - the market of persons created without one
+func DefaultMarket() string {
+	return config.Current().Person.Market
+}
+
+// marketLocation - the time zone of the market, the default market is used for unscoped calls
+func marketLocation(market string) *time.Location {
+	settings := gettConfig.Settings.GlobalSettings
+	if settings == nil {
+		return time.UTC
+	}
+
+	if market == "" {
+		market = DefaultMarket()
+	}
+
+	location, err := time.LoadLocation(settings.GetString("global.env." + market + ".time_zone"))
+	if err != nil {
+		logrus.Error("unable to load the market time zone ", err)
+		return time.UTC
+	}
+
+	return location
+}
+
+// ordersURL - the orders service of the market (global.env.<market>.endpoints.orders.hostname), person.orders_url otherwise
+func ordersURL(market string) string {
+	settings := gettConfig.Settings.GlobalSettings
+	if settings != nil && market != "" {
+		if hostname := settings.GetString("global.env." + market + ".endpoints.orders.hostname"); hostname != "" {
+			return "http://" + hostname
+		}
+	}
+
+	return config.Current().Person.OrdersURL
+}
+
+// marketKey - namespace a redis key by market, unscoped keys are left as is
+func marketKey(market, key string) string {
+	if market == "" {
+		return key
+	}
+
+	return market + ":" + key
+}
@@ -0,0 +1,112 @@
+package person
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"gopkg.in/redis.v5"
+)
+
+var _ = ginkgo.Describe("markets", func() {
+
+	var previous config.Config
+
+	ginkgo.BeforeEach(func() {
+		previous = config.Current()
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		c.Person.Market = "IL"
+		c.Person.OrdersURL = "http://orders.local"
+		config.Set(c)
+	})
+
+	ginkgo.AfterEach(func() {
+		config.Set(previous)
+	})
+
+	ginkgo.Context("validate that the redis keys are namespaced by market and the unscoped ones are left as is", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(marketKey("IL", "persons")).To(gomega.Equal("IL:persons"))
+			gomega.Expect(marketKey("", "persons")).To(gomega.Equal("persons"))
+
+			gomega.Expect(PersonStore{}.ForMarket("UK").(PersonStore).key("persons")).To(gomega.Equal("UK:persons"))
+			gomega.Expect(Leaderboard{market: "UK"}.keys()).To(gomega.Equal([]string{"UK:ratings:leaderboard", "ratings:leaderboard"}))
+			gomega.Expect(Leaderboard{}.keys()).To(gomega.Equal([]string{"ratings:leaderboard"}))
+			gomega.Expect(Cache{}.ForMarket("UK").(Cache).fileName()).To(gomega.Equal("ratings.UK.json"))
+		})
+	})
+
+	ginkgo.Context("validate that the markets are upper cased and checked against the configured ones", func() {
+
+		ginkgo.It("do", func() {
+			market, err := NormalizeMarket("uk")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(market).To(gomega.Equal("UK"))
+
+			market, err = NormalizeMarket("")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(market).To(gomega.BeEmpty())
+
+			_, err = NormalizeMarket("fr")
+			gomega.Expect(err).To(gomega.Equal(ErrUnknownMarket))
+		})
+	})
+
+	ginkgo.Context("validate that the orders service of a market without a hostname is the configured one", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(ordersURL("UK")).To(gomega.Equal("http://orders.local"))
+			gomega.Expect(ordersURL("")).To(gomega.Equal("http://orders.local"))
+		})
+	})
+
+	ginkgo.Context("validate that a scoped service only covers the persons and the cached entries of its market", func() {
+
+		var (
+			ctx                  context.Context
+			ctrl                 *gomock.Controller
+			personRepositoryMock *MockPersonRepository
+			service              Service
+			store                Provider
+		)
+
+		ginkgo.BeforeEach(func() {
+			ctx = context.Background()
+			ctrl = gomock.NewController(ginkgo.GinkgoT())
+			personRepositoryMock = NewMockPersonRepository(ctrl)
+			store = NewMemoryStore()
+			service = PersonService{repository: personRepositoryMock, store: store, cache: emptyCache{}, leaderboard: NewMemoryLeaderboard()}.ForMarket("UK")
+		})
+
+		ginkgo.AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		ginkgo.It("do", func() {
+			store.ForMarket("IL").CreatePersons(ctx, &Person{ID: 1, Name: "Ivan Petrov", Market: "IL"})
+			_, err := store.ForMarket("UK").GetPersonByID(ctx, 1)
+			gomega.Expect(err).To(gomega.Equal(redis.Nil))
+
+			personRepositoryMock.EXPECT().GetPersonsByMarket(gomock.Any(), "UK").Return([]Person{{ID: 2, Market: "UK"}}, nil)
+			persons, err := service.GetPersons(ctx)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(persons).To(gomega.Equal([]Person{{ID: 2, Market: "UK"}}))
+			cached, err := store.ForMarket("UK").GetPersons(ctx)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(cached).To(gomega.Equal(persons))
+			_, err = store.GetPersons(ctx)
+			gomega.Expect(err).To(gomega.Equal(redis.Nil))
+
+			personRepositoryMock.EXPECT().GetPersonsByIDs(gomock.Any(), []int64{1}).Return([]Person{{ID: 1, Market: "IL"}}, nil)
+			ratings, err := service.GetRatingsByPersonIDs(ctx, []int64{1})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(ratings[1].Status).To(gomega.Equal(BatchStatusNotFound))
+
+			_, err = service.CreatePersons(ctx, &CreatePersonRequest{Name: "Ivan Petrov", Market: "il"})
+			gomega.Expect(err).To(gomega.Equal(ErrUnknownMarket))
+		})
+	})
+})
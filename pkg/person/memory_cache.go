@@ -0,0 +1,79 @@
+package person
+
+import (
+	"context"
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
+	"sync"
+	"time"
+)
+
+var ErrRatingsNotCached = errors.New("the ratings are not cached yet")
+
+// MemoryCache - the ratings cache kept by the process instead of the files, shared by the market scopes
+type MemoryCache struct {
+	market  string
+	ratings *memoryRatings
+}
+
+type memoryRatings struct {
+	mu        sync.RWMutex
+	byMarket  map[string][]Rating
+	writtenAt map[string]time.Time
+}
+
+func NewMemoryCache() InMemoryProvider {
+	return MemoryCache{ratings: &memoryRatings{
+		byMarket:  map[string][]Rating{},
+		writtenAt: map[string]time.Time{},
+	}}
+}
+
+func (c MemoryCache) ForMarket(market string) InMemoryProvider {
+	return MemoryCache{market: market, ratings: c.ratings}
+}
+
+func (c MemoryCache) GetInMemoryRatings(ctx context.Context) ([]Rating, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.ratings.mu.RLock()
+	defer c.ratings.mu.RUnlock()
+
+	ratings, ok := c.ratings.byMarket[c.market]
+	if !ok {
+		metrics.CacheRequests.Inc(ratingsCache, "ratings", metrics.CacheMiss)
+		return nil, ErrRatingsNotCached
+	}
+	metrics.CacheRequests.Inc(ratingsCache, "ratings", metrics.CacheHit)
+
+	return append([]Rating{}, ratings...), nil
+}
+
+func (c MemoryCache) SetInMemoryRatings(ctx context.Context, ratings []Rating) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.ratings.mu.Lock()
+	defer c.ratings.mu.Unlock()
+
+	c.ratings.byMarket[c.market] = append([]Rating{}, ratings...)
+	c.ratings.writtenAt[c.market] = time.Now()
+
+	return nil
+}
+
+func (c MemoryCache) WrittenAt(ctx context.Context) (time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return time.Time{}, err
+	}
+	c.ratings.mu.RLock()
+	defer c.ratings.mu.RUnlock()
+
+	writtenAt, ok := c.ratings.writtenAt[c.market]
+	if !ok {
+		return time.Time{}, ErrRatingsNotCached
+	}
+
+	return writtenAt, nil
+}
@@ -0,0 +1,139 @@
+package person
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// MemoryLeaderboard - the sorted sets of the Leaderboard kept by the process, ranked the way redis ranks them
+type MemoryLeaderboard struct {
+	market string
+	sets   *memorySortedSets
+}
+
+type memorySortedSets struct {
+	mu     sync.RWMutex
+	scores map[string]map[int64]float64
+}
+
+func NewMemoryLeaderboard() LeaderboardProvider {
+	return MemoryLeaderboard{sets: &memorySortedSets{scores: map[string]map[int64]float64{}}}
+}
+
+func (l MemoryLeaderboard) ForMarket(market string) LeaderboardProvider {
+	return MemoryLeaderboard{market: market, sets: l.sets}
+}
+
+func (l MemoryLeaderboard) SetRatings(ctx context.Context, ratings []Rating) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.sets.mu.Lock()
+	defer l.sets.mu.Unlock()
+
+	for _, key := range (Leaderboard{market: l.market}).keys() {
+		set, ok := l.sets.scores[key]
+		if !ok {
+			set = map[int64]float64{}
+			l.sets.scores[key] = set
+		}
+		for _, r := range ratings {
+			set[r.PersonID] = r.Rating
+		}
+	}
+
+	return nil
+}
+
+func (l MemoryLeaderboard) GetTop(ctx context.Context, n int64, desc bool) ([]RankedRating, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.sets.mu.RLock()
+	defer l.sets.mu.RUnlock()
+
+	members := l.ranked(desc)
+	if n > 0 && int64(len(members)) > n {
+		members = members[:n]
+	}
+	for i := range members {
+		members[i].Position = int64(i) + 1
+	}
+
+	return members, nil
+}
+
+func (l MemoryLeaderboard) GetRank(ctx context.Context, personID int64) (*RatingRank, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.sets.mu.RLock()
+	defer l.sets.mu.RUnlock()
+
+	members := l.ranked(true)
+	for i, m := range members {
+		if m.PersonID != personID {
+			continue
+		}
+		position, total := int64(i)+1, int64(len(members))
+		return &RatingRank{
+			PersonID:   personID,
+			Rating:     m.Rating,
+			Position:   position,
+			Total:      total,
+			Percentile: percentile(position, total),
+		}, nil
+	}
+
+	return nil, ErrNotRanked
+}
+
+func (l MemoryLeaderboard) RemovePerson(ctx context.Context, personIDs ...int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.sets.mu.Lock()
+	defer l.sets.mu.Unlock()
+
+	for _, key := range (Leaderboard{market: l.market}).keys() {
+		for _, id := range personIDs {
+			delete(l.sets.scores[key], id)
+		}
+	}
+
+	return nil
+}
+
+func (l MemoryLeaderboard) Count(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	l.sets.mu.RLock()
+	defer l.sets.mu.RUnlock()
+
+	return int64(len(l.sets.scores[(Leaderboard{market: l.market}).key()])), nil
+}
+
+// ranked - the members of the market set by score, ties are ordered by their member string like in redis
+func (l MemoryLeaderboard) ranked(desc bool) []RankedRating {
+	set := l.sets.scores[(Leaderboard{market: l.market}).key()]
+	members := make([]RankedRating, 0, len(set))
+	for id, score := range set {
+		members = append(members, RankedRating{PersonID: id, Rating: score})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if desc {
+			a, b = b, a
+		}
+		if a.Rating != b.Rating {
+			return a.Rating < b.Rating
+		}
+		return strconv.FormatInt(a.PersonID, 10) < strconv.FormatInt(b.PersonID, 10)
+	})
+
+	return members
+}
@@ -0,0 +1,295 @@
+package person
+
+import (
+	"context"
+	"github.com/gtforge/gorm"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// similarityThreshold - the default pg_trgm.similarity_threshold, the names the % operator matches
+const similarityThreshold = 0.3
+
+// MemoryRepository - the persons and their rating snapshots kept by the process, runs the service without postgres.
+// missing rows are gorm.ErrRecordNotFound like in the Repo
+type MemoryRepository struct {
+	mu             sync.RWMutex
+	persons        map[int64]Person
+	snapshots      []RatingSnapshot
+	lastPersonID   int64
+	lastSnapshotID int64
+}
+
+func NewMemoryRepository() PersonRepository {
+	return &MemoryRepository{
+		persons: map[int64]Person{},
+	}
+}
+
+func (r *MemoryRepository) CreatePerson(ctx context.Context, person *Person) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastPersonID++
+	person.ID = r.lastPersonID
+	person.BeforeSave()
+	r.persons[person.ID] = *person
+
+	return nil
+}
+
+func (r *MemoryRepository) GetPersons(ctx context.Context) ([]Person, error) {
+	return r.findPersons(ctx, func(Person) bool { return true })
+}
+
+func (r *MemoryRepository) GetPersonsByMarket(ctx context.Context, market string) ([]Person, error) {
+	return r.findPersons(ctx, func(p Person) bool { return p.Market == market })
+}
+
+// findPersons - the persons matching the filter by id, the order postgres returns them in for a fresh table
+func (r *MemoryRepository) findPersons(ctx context.Context, filter func(Person) bool) ([]Person, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	persons := make([]Person, 0, len(r.persons))
+	for _, person := range r.persons {
+		if filter(person) {
+			persons = append(persons, person)
+		}
+	}
+	sort.Slice(persons, func(i, j int) bool { return persons[i].ID < persons[j].ID })
+
+	return persons, nil
+}
+
+func (r *MemoryRepository) GetPersonById(ctx context.Context, id int64) (*Person, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	person, ok := r.persons[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &person, nil
+}
+
+func (r *MemoryRepository) GetPersonsByIDs(ctx context.Context, ids []int64) ([]Person, error) {
+	wanted := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	return r.findPersons(ctx, func(p Person) bool { return wanted[p.ID] })
+}
+
+func (r *MemoryRepository) FindPersons(ctx context.Context, filter PersonFilter) ([]Person, error) {
+	persons, err := r.findPersons(ctx, func(p Person) bool {
+		return p.ID > filter.AfterID &&
+			(filter.Market == "" || p.Market == filter.Market) &&
+			strings.Contains(p.NormalizedName, filter.Name) &&
+			(filter.RatingUpdated == nil || p.RatingUpdated == *filter.RatingUpdated)
+	})
+	if err != nil || len(persons) <= filter.Limit {
+		return persons, err
+	}
+
+	return persons[:filter.Limit], nil
+}
+
+func (r *MemoryRepository) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.persons[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	p.Name = createPersonRequest.Name
+	p.Age = createPersonRequest.Age
+	p.Height = createPersonRequest.Height
+	p.Weight = createPersonRequest.Weight
+	p.RatingUpdated = createPersonRequest.RatingUpdate
+	if createPersonRequest.Market != "" {
+		p.Market = createPersonRequest.Market
+	}
+	p.BeforeSave()
+	r.persons[id] = p
+
+	return &p, nil
+}
+
+func (r *MemoryRepository) UpdatePersonRating(ctx context.Context, id int64, updated bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.persons[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	p.RatingUpdated = updated
+	r.persons[id] = p
+
+	return nil
+}
+
+func (r *MemoryRepository) DeletePerson(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.persons[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.persons, id)
+
+	return nil
+}
+
+func (r *MemoryRepository) CreateRatingSnapshot(ctx context.Context, snapshot *RatingSnapshot) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastSnapshotID++
+	snapshot.ID = r.lastSnapshotID
+	if snapshot.CreatedAt.IsZero() {
+		snapshot.CreatedAt = time.Now()
+	}
+	r.snapshots = append(r.snapshots, *snapshot)
+
+	return nil
+}
+
+func (r *MemoryRepository) GetLastRatingSnapshot(ctx context.Context, personID int64, kind string) (*RatingSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var last *RatingSnapshot
+	for i, snapshot := range r.snapshots {
+		if snapshot.PersonID != personID || snapshot.Kind != kind {
+			continue
+		}
+		if last == nil || !snapshot.CreatedAt.Before(last.CreatedAt) {
+			last = &r.snapshots[i]
+		}
+	}
+	if last == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	snapshot := *last
+	return &snapshot, nil
+}
+
+func (r *MemoryRepository) GetRatingSnapshots(ctx context.Context, personID int64, kind string, from, to time.Time) ([]RatingSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := make([]RatingSnapshot, 0)
+	for _, snapshot := range r.snapshots {
+		if snapshot.PersonID == personID && snapshot.Kind == kind && !snapshot.CreatedAt.Before(from) && snapshot.CreatedAt.Before(to) {
+			snapshots = append(snapshots, snapshot)
+		}
+	}
+	sort.SliceStable(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt) })
+
+	return snapshots, nil
+}
+
+// SearchPersons - the trigram similarity of pg_trgm over the normalized names, or their latin spelling when
+// transliterated
+func (r *MemoryRepository) SearchPersons(ctx context.Context, query string, market string, transliterated bool, limit int) ([]PersonMatch, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	queryTrigrams := trigrams(query)
+	matches := make([]PersonMatch, 0)
+	for _, person := range r.persons {
+		if market != "" && person.Market != market {
+			continue
+		}
+		name := person.NormalizedName
+		if transliterated {
+			name = person.LatinName
+		}
+		if similarity := trigramSimilarity(queryTrigrams, trigrams(name)); similarity >= similarityThreshold {
+			matches = append(matches, PersonMatch{Person: person, Similarity: similarity})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].Person.ID < matches[j].Person.ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches, nil
+}
+
+// trigrams - the trigrams pg_trgm extracts: every word is lower cased and padded with two spaces before and one after
+func trigrams(text string) map[string]bool {
+	result := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = true
+		}
+	}
+
+	return result
+}
+
+// trigramSimilarity - the shared trigrams over all the trigrams of both texts
+func trigramSimilarity(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range a {
+		if b[trigram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
@@ -0,0 +1,290 @@
+package person
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
+	"github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/redis.v5"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval - how often the writes drop the expired entries, the reads skip them in between
+const memorySweepInterval = time.Minute
+
+// memoryEntries - the json values of the redis keys with their expiry, shared by the market scopes of a store
+type memoryEntries struct {
+	mu      sync.Mutex
+	values  map[string]memoryEntry
+	sweptAt time.Time
+	now     func() time.Time
+}
+
+type memoryEntry struct {
+	value []byte
+	// expiresAt - zero for the keys set without a ttl
+	expiresAt time.Time
+}
+
+func newMemoryEntries() *memoryEntries {
+	return &memoryEntries{
+		values: map[string]memoryEntry{},
+		now:    time.Now,
+	}
+}
+
+func (e *memoryEntries) get(key string) ([]byte, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	entry, ok := e.values[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expiresAt.IsZero() && !e.now().Before(entry.expiresAt) {
+		delete(e.values, key)
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// set - a ttl of 0 keeps the key until it is deleted, like redis SET
+func (e *memoryEntries) set(key string, value []byte, ttl time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	entry := memoryEntry{value: value}
+	if ttl > 0 {
+		entry.expiresAt = now.Add(ttl)
+	}
+	e.values[key] = entry
+
+	if now.Sub(e.sweptAt) < memorySweepInterval {
+		return
+	}
+	e.sweptAt = now
+	for k, v := range e.values {
+		if !v.expiresAt.IsZero() && !now.Before(v.expiresAt) {
+			delete(e.values, k)
+		}
+	}
+}
+
+func (e *memoryEntries) del(keys ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, key := range keys {
+		delete(e.values, key)
+	}
+}
+
+// MemoryStore - the PersonStore kept by the process, with the same keys, ttls and redis.Nil misses
+type MemoryStore struct {
+	market  string
+	entries *memoryEntries
+}
+
+func NewMemoryStore() Provider {
+	return MemoryStore{entries: newMemoryEntries()}
+}
+
+// ForMarket - a store sharing the entries, its keys are namespaced by the market
+func (ms MemoryStore) ForMarket(market string) Provider {
+	return MemoryStore{market: market, entries: ms.entries}
+}
+
+func (ms MemoryStore) key(key string) string {
+	return marketKey(ms.market, key)
+}
+
+func (ms MemoryStore) span(operation string) (opentracing.Span, func()) {
+	span, finish := tracing.StartSpan("person_store." + operation)
+	otext.DBType.Set(span, "memory")
+	span.SetTag("market", ms.market)
+	return span, finish
+}
+
+// get - unmarshal the value of the key into v, redis.Nil when the key is missing
+func (ms MemoryStore) get(span opentracing.Span, entry, key string, v interface{}) error {
+	bytes, ok := ms.entries.get(ms.key(key))
+	if !ok {
+		observeCache(span, entry, redis.Nil)
+		return redis.Nil
+	}
+
+	err := json.Unmarshal(bytes, v)
+	observeCache(span, entry, err)
+	return err
+}
+
+func (ms MemoryStore) set(span opentracing.Span, key string, v interface{}, ttl time.Duration) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		logrus.Error("unable marshal ", key, " ", err)
+		tracing.Error(span, err)
+		return
+	}
+	ms.entries.set(ms.key(key), bytes, ttl)
+}
+
+func (ms MemoryStore) GetPersons(ctx context.Context) ([]Person, error) {
+	span, finish := ms.span("get_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return []Person{}, err
+	}
+
+	persons := []Person{}
+	if err := ms.get(span, "persons", "persons", &persons); err != nil {
+		return []Person{}, err
+	}
+
+	return persons, nil
+}
+
+func (ms MemoryStore) GetPersonByID(ctx context.Context, id int64) (*Person, error) {
+	span, finish := ms.span("get_person_by_id")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	person := &Person{}
+	if err := ms.get(span, "person", "person:"+strconv.FormatInt(id, 10), person); err != nil {
+		return nil, err
+	}
+
+	return person, nil
+}
+
+func (ms MemoryStore) SetPersons(ctx context.Context, persons []Person) {
+	span, finish := ms.span("set_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+
+	ms.set(span, "persons", persons, config.Current().Person.Store.PersonsTTL)
+}
+
+func (ms MemoryStore) CreatePersons(ctx context.Context, person *Person) {
+	span, finish := ms.span("create_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+
+	ms.set(span, "person:"+strconv.FormatInt(person.ID, 10), person, config.Current().Person.Store.PersonsTTL)
+}
+
+// UpdatePerson - update the cached person, redis.Nil when it isn't cached
+func (ms MemoryStore) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+	span, finish := ms.span("update_person")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	person := &Person{}
+	if err := ms.get(span, "person", "person:"+strconv.FormatInt(id, 10), person); err != nil {
+		return nil, err
+	}
+	person.Name = createPersonRequest.Name
+	person.Age = createPersonRequest.Age
+	person.Weight = createPersonRequest.Weight
+	person.Height = createPersonRequest.Height
+	ms.set(span, "person:"+strconv.FormatInt(id, 10), person, config.Current().Person.Store.PersonsTTL)
+
+	return person, nil
+}
+
+func (ms MemoryStore) DeletePerson(ctx context.Context, id int64) error {
+	_, finish := ms.span("delete_person")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ms.entries.del(ms.key("person:"+strconv.FormatInt(id, 10)), ms.key("persons"))
+	return nil
+}
+
+func (ms MemoryStore) GetRatingDetails(ctx context.Context, personID int64) (*RatingDetails, error) {
+	span, finish := ms.span("get_rating_details")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	details := &RatingDetails{}
+	if err := ms.get(span, "rating_details", "rating_details:"+strconv.FormatInt(personID, 10), details); err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func (ms MemoryStore) SetRatingDetails(ctx context.Context, details *RatingDetails) {
+	span, finish := ms.span("set_rating_details")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+
+	ms.set(span, "rating_details:"+strconv.FormatInt(details.PersonID, 10), details, config.Current().Person.Rating.CacheTTL)
+}
+
+// GetRatingDetailsBatch - the cached rating details of the given persons, misses are left out of the map
+func (ms MemoryStore) GetRatingDetailsBatch(ctx context.Context, personIDs []int64) map[int64]*RatingDetails {
+	span, finish := ms.span("get_rating_details_batch")
+	defer finish()
+	result := make(map[int64]*RatingDetails)
+	if err := ctx.Err(); err != nil {
+		return result
+	}
+
+	for _, id := range personIDs {
+		details := &RatingDetails{}
+		if err := ms.get(span, "rating_details", "rating_details:"+strconv.FormatInt(id, 10), details); err != nil {
+			continue
+		}
+		result[id] = details
+	}
+	span.SetTag("cache.hits", len(result))
+
+	return result
+}
+
+// GetLastRatingSnapshots - the cached last snapshots of the person, redis.Nil when they aren't cached
+func (ms MemoryStore) GetLastRatingSnapshots(ctx context.Context, personID int64) (*LastRatingSnapshots, error) {
+	span, finish := ms.span("get_last_rating_snapshots")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	last := &LastRatingSnapshots{}
+	if err := ms.get(span, "rating_snapshots", "rating_snapshots:"+strconv.FormatInt(personID, 10), last); err != nil {
+		return nil, err
+	}
+
+	return last, nil
+}
+
+func (ms MemoryStore) SetLastRatingSnapshots(ctx context.Context, last *LastRatingSnapshots) {
+	span, finish := ms.span("set_last_rating_snapshots")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+
+	ms.set(span, "rating_snapshots:"+strconv.FormatInt(last.PersonID, 10), last, lastSnapshotsTTL)
+}
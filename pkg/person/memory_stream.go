@@ -0,0 +1,59 @@
+package person
+
+import (
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"sync"
+	"time"
+)
+
+// MemoryRatingStream - the rating stream of a single replica, the events are numbered and buffered by the process and
+// fanned out to its clients only
+type MemoryRatingStream struct {
+	fanout
+
+	mu          sync.Mutex
+	lastID      int64
+	buffer      []RatingEvent
+	publishedAt time.Time
+}
+
+func NewMemoryRatingStream() RatingStreamProvider {
+	return &MemoryRatingStream{}
+}
+
+func (ms *MemoryRatingStream) Publish(change RatingChange) {
+	ms.mu.Lock()
+	ms.lastID++
+	event := RatingEvent{ID: ms.lastID, RatingChange: change}
+	ms.buffer = append(ms.buffer, event)
+	if size := config.Current().Person.RatingsStream.BufferSize; size > 0 && int64(len(ms.buffer)) > size {
+		ms.buffer = append([]RatingEvent{}, ms.buffer[int64(len(ms.buffer))-size:]...)
+	}
+	ms.publishedAt = time.Now()
+	// broadcast under the lock, so the clients get the events in the order of their ids
+	ms.broadcast(event)
+	ms.mu.Unlock()
+}
+
+// Since - the buffer expires once no event was published for person.ratings_stream.ttl, like the redis one
+func (ms *MemoryRatingStream) Since(lastID int64) ([]RatingEvent, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if ttl := config.Current().Person.RatingsStream.TTL; ttl > 0 && time.Since(ms.publishedAt) >= ttl {
+		ms.buffer = nil
+	}
+
+	events := make([]RatingEvent, 0, len(ms.buffer))
+	for _, event := range ms.buffer {
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func (ms *MemoryRatingStream) Subscribe() (<-chan RatingEvent, func()) {
+	return ms.subscribe()
+}
@@ -5,9 +5,11 @@
 package mock_person
 
 import (
+	context "context"
 	gomock "github.com/golang/mock/gomock"
 	person "github.com/gtforge/go-skeleton-draft/structure/pkg/person"
 	reflect "reflect"
+	time "time"
 )
 
 // MockPersonRepository is a mock of PersonRepository interface
@@ -34,74 +36,192 @@ func (m *MockPersonRepository) EXPECT() *MockPersonRepositoryMockRecorder {
 }
 
 // GetPersons mocks base method
-func (m *MockPersonRepository) GetPersons() ([]person.Person, error) {
+func (m *MockPersonRepository) GetPersons(ctx context.Context) ([]person.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPersons")
+	ret := m.ctrl.Call(m, "GetPersons", ctx)
 	ret0, _ := ret[0].([]person.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPersons indicates an expected call of GetPersons
-func (mr *MockPersonRepositoryMockRecorder) GetPersons() *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) GetPersons(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersons", reflect.TypeOf((*MockPersonRepository)(nil).GetPersons))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersons", reflect.TypeOf((*MockPersonRepository)(nil).GetPersons), ctx)
+}
+
+// GetPersonsByMarket mocks base method
+func (m *MockPersonRepository) GetPersonsByMarket(ctx context.Context, market string) ([]person.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonsByMarket", ctx, market)
+	ret0, _ := ret[0].([]person.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonsByMarket indicates an expected call of GetPersonsByMarket
+func (mr *MockPersonRepositoryMockRecorder) GetPersonsByMarket(ctx, market interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonsByMarket", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonsByMarket), ctx, market)
 }
 
 // CreatePerson mocks base method
-func (m *MockPersonRepository) CreatePerson(person *person.Person) error {
+func (m *MockPersonRepository) CreatePerson(ctx context.Context, person *person.Person) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePerson", person)
+	ret := m.ctrl.Call(m, "CreatePerson", ctx, person)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreatePerson indicates an expected call of CreatePerson
-func (mr *MockPersonRepositoryMockRecorder) CreatePerson(person interface{}) *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) CreatePerson(ctx, person interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePerson", reflect.TypeOf((*MockPersonRepository)(nil).CreatePerson), person)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePerson", reflect.TypeOf((*MockPersonRepository)(nil).CreatePerson), ctx, person)
 }
 
 // GetPersonById mocks base method
-func (m *MockPersonRepository) GetPersonById(id int64) (*person.Person, error) {
+func (m *MockPersonRepository) GetPersonById(ctx context.Context, id int64) (*person.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPersonById", id)
+	ret := m.ctrl.Call(m, "GetPersonById", ctx, id)
 	ret0, _ := ret[0].(*person.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPersonById indicates an expected call of GetPersonById
-func (mr *MockPersonRepositoryMockRecorder) GetPersonById(id interface{}) *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) GetPersonById(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonById", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonById), ctx, id)
+}
+
+// GetPersonsByIDs mocks base method
+func (m *MockPersonRepository) GetPersonsByIDs(ctx context.Context, ids []int64) ([]person.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonsByIDs", ctx, ids)
+	ret0, _ := ret[0].([]person.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonsByIDs indicates an expected call of GetPersonsByIDs
+func (mr *MockPersonRepositoryMockRecorder) GetPersonsByIDs(ctx, ids interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonById", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonsByIDs", reflect.TypeOf((*MockPersonRepository)(nil).GetPersonsByIDs), ctx, ids)
+}
+
+// FindPersons mocks base method
+func (m *MockPersonRepository) FindPersons(ctx context.Context, filter person.PersonFilter) ([]person.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPersons", ctx, filter)
+	ret0, _ := ret[0].([]person.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPersons indicates an expected call of FindPersons
+func (mr *MockPersonRepositoryMockRecorder) FindPersons(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPersons", reflect.TypeOf((*MockPersonRepository)(nil).FindPersons), ctx, filter)
 }
 
 // UpdatePerson mocks base method
-func (m *MockPersonRepository) UpdatePerson(id int64, createPersonRequest *person.CreatePersonRequest) (*person.Person, error) {
+func (m *MockPersonRepository) UpdatePerson(ctx context.Context, id int64, createPersonRequest *person.CreatePersonRequest) (*person.Person, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePerson", id, createPersonRequest)
+	ret := m.ctrl.Call(m, "UpdatePerson", ctx, id, createPersonRequest)
 	ret0, _ := ret[0].(*person.Person)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // UpdatePerson indicates an expected call of UpdatePerson
-func (mr *MockPersonRepositoryMockRecorder) UpdatePerson(id, createPersonRequest interface{}) *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) UpdatePerson(ctx, id, createPersonRequest interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePerson", reflect.TypeOf((*MockPersonRepository)(nil).UpdatePerson), id, createPersonRequest)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePerson", reflect.TypeOf((*MockPersonRepository)(nil).UpdatePerson), ctx, id, createPersonRequest)
+}
+
+// UpdatePersonRating mocks base method
+func (m *MockPersonRepository) UpdatePersonRating(ctx context.Context, id int64, updated bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePersonRating", ctx, id, updated)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePersonRating indicates an expected call of UpdatePersonRating
+func (mr *MockPersonRepositoryMockRecorder) UpdatePersonRating(ctx, id, updated interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePersonRating", reflect.TypeOf((*MockPersonRepository)(nil).UpdatePersonRating), ctx, id, updated)
 }
 
 // DeletePerson mocks base method
-func (m *MockPersonRepository) DeletePerson(id int64) error {
+func (m *MockPersonRepository) DeletePerson(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePerson", id)
+	ret := m.ctrl.Call(m, "DeletePerson", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePerson indicates an expected call of DeletePerson
-func (mr *MockPersonRepositoryMockRecorder) DeletePerson(id interface{}) *gomock.Call {
+func (mr *MockPersonRepositoryMockRecorder) DeletePerson(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePerson", reflect.TypeOf((*MockPersonRepository)(nil).DeletePerson), ctx, id)
+}
+
+// CreateRatingSnapshot mocks base method
+func (m *MockPersonRepository) CreateRatingSnapshot(ctx context.Context, snapshot *person.RatingSnapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRatingSnapshot", ctx, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRatingSnapshot indicates an expected call of CreateRatingSnapshot
+func (mr *MockPersonRepositoryMockRecorder) CreateRatingSnapshot(ctx, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRatingSnapshot", reflect.TypeOf((*MockPersonRepository)(nil).CreateRatingSnapshot), ctx, snapshot)
+}
+
+// GetLastRatingSnapshot mocks base method
+func (m *MockPersonRepository) GetLastRatingSnapshot(ctx context.Context, personID int64, kind string) (*person.RatingSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastRatingSnapshot", ctx, personID, kind)
+	ret0, _ := ret[0].(*person.RatingSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastRatingSnapshot indicates an expected call of GetLastRatingSnapshot
+func (mr *MockPersonRepositoryMockRecorder) GetLastRatingSnapshot(ctx, personID, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastRatingSnapshot", reflect.TypeOf((*MockPersonRepository)(nil).GetLastRatingSnapshot), ctx, personID, kind)
+}
+
+// GetRatingSnapshots mocks base method
+func (m *MockPersonRepository) GetRatingSnapshots(ctx context.Context, personID int64, kind string, from time.Time, to time.Time) ([]person.RatingSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRatingSnapshots", ctx, personID, kind, from, to)
+	ret0, _ := ret[0].([]person.RatingSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRatingSnapshots indicates an expected call of GetRatingSnapshots
+func (mr *MockPersonRepositoryMockRecorder) GetRatingSnapshots(ctx, personID, kind, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRatingSnapshots", reflect.TypeOf((*MockPersonRepository)(nil).GetRatingSnapshots), ctx, personID, kind, from, to)
+}
+
+// SearchPersons mocks base method
+func (m *MockPersonRepository) SearchPersons(ctx context.Context, query string, market string, transliterated bool, limit int) ([]person.PersonMatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPersons", ctx, query, market, transliterated, limit)
+	ret0, _ := ret[0].([]person.PersonMatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPersons indicates an expected call of SearchPersons
+func (mr *MockPersonRepositoryMockRecorder) SearchPersons(ctx, query, market, transliterated, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePerson", reflect.TypeOf((*MockPersonRepository)(nil).DeletePerson), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPersons", reflect.TypeOf((*MockPersonRepository)(nil).SearchPersons), ctx, query, market, transliterated, limit)
 }
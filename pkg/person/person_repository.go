@@ -1,19 +1,29 @@
 package person
 
 import (
+	"context"
+	"database/sql"
 	"github.com/gtforge/gorm"
 	"github.com/sirupsen/logrus"
 	"strconv"
+	"time"
 )
 
-//mockgen -source=./pkg/person/person_repository.go -destination=./pkg/person/mock/person_repository_mock.go PersonRepository //-package=person_repository
+// mockgen -source=./pkg/person/person_repository.go -destination=./pkg/person/mock/person_repository_mock.go PersonRepository //-package=person_repository
 type PersonRepository interface {
-	GetPersons() ([]Person, error)
-	CreatePerson(person *Person) error
-	GetPersonById(id int64) (*Person, error)
-	UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
-	UpdatePersonRating(id int64, updated bool) error
-	DeletePerson(id int64) error
+	GetPersons(ctx context.Context) ([]Person, error)
+	GetPersonsByMarket(ctx context.Context, market string) ([]Person, error)
+	CreatePerson(ctx context.Context, person *Person) error
+	GetPersonById(ctx context.Context, id int64) (*Person, error)
+	GetPersonsByIDs(ctx context.Context, ids []int64) ([]Person, error)
+	FindPersons(ctx context.Context, filter PersonFilter) ([]Person, error)
+	UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
+	UpdatePersonRating(ctx context.Context, id int64, updated bool) error
+	DeletePerson(ctx context.Context, id int64) error
+	CreateRatingSnapshot(ctx context.Context, snapshot *RatingSnapshot) error
+	GetLastRatingSnapshot(ctx context.Context, personID int64, kind string) (*RatingSnapshot, error)
+	GetRatingSnapshots(ctx context.Context, personID int64, kind string, from, to time.Time) ([]RatingSnapshot, error)
+	SearchPersons(ctx context.Context, query string, market string, transliterated bool, limit int) ([]PersonMatch, error)
 }
 
 type Repo struct {
@@ -26,14 +36,60 @@ func NewRepo(db *gorm.DB) PersonRepository {
 	}
 }
 
-func (r *Repo) CreatePerson(person *Person) error {
-	return r.db.Create(person).Error
+// single - run a single insert unless the context is already done. gorm v1 has no per query context, so a running
+// insert isn't cancelled, a transaction around it would only add a BEGIN and a COMMIT round trip to it
+func (r *Repo) single(ctx context.Context, query func(db *gorm.DB) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return query(r.db)
+}
+
+// read - run the queries of a read in a read-only transaction bound to the context, the connection of a cancelled
+// request is released instead of running the query to its end
+func (r *Repo) read(ctx context.Context, queries func(db *gorm.DB) error) error {
+	return r.transaction(ctx, &sql.TxOptions{ReadOnly: true}, queries)
+}
+
+// bound - run the queries of a write in a transaction bound to the context, so a cancelled request doesn't run its
+// remaining queries
+func (r *Repo) bound(ctx context.Context, queries func(db *gorm.DB) error) error {
+	return r.transaction(ctx, &sql.TxOptions{}, queries)
+}
+
+// transaction - gorm v1 has no per query context, the transaction is rolled back by database/sql when the context is
+// done
+func (r *Repo) transaction(ctx context.Context, options *sql.TxOptions, queries func(db *gorm.DB) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := r.db.BeginTx(ctx, options)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if err := queries(tx); err != nil {
+		tx.Rollback()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+func (r *Repo) CreatePerson(ctx context.Context, person *Person) error {
+	return r.single(ctx, func(db *gorm.DB) error {
+		return db.Create(person).Error
+	})
 }
 
-func (r *Repo) GetPersons() ([]Person, error) {
+func (r *Repo) GetPersons(ctx context.Context) ([]Person, error) {
 	persons := make([]Person, 0)
 
-	if err := r.db.Find(&persons).Error; err != nil {
+	if err := r.read(ctx, func(db *gorm.DB) error { return db.Find(&persons).Error }); err != nil {
 		logrus.Error("can't get persons ", err)
 		return nil, err
 	}
@@ -41,10 +97,21 @@ func (r *Repo) GetPersons() ([]Person, error) {
 	return persons, nil
 }
 
-func (r *Repo) GetPersonById(id int64) (*Person, error) {
+func (r *Repo) GetPersonsByMarket(ctx context.Context, market string) ([]Person, error) {
+	persons := make([]Person, 0)
+
+	if err := r.read(ctx, func(db *gorm.DB) error { return db.Where("market = ?", market).Find(&persons).Error }); err != nil {
+		logrus.Error("can't get persons by market ", err)
+		return nil, err
+	}
+
+	return persons, nil
+}
+
+func (r *Repo) GetPersonById(ctx context.Context, id int64) (*Person, error) {
 	person := Person{}
 
-	if err := r.db.First(&person, id).Error; err != nil {
+	if err := r.read(ctx, func(db *gorm.DB) error { return db.First(&person, id).Error }); err != nil {
 		logrus.Error("can't get persons", err)
 		return nil, err
 	}
@@ -52,51 +119,168 @@ func (r *Repo) GetPersonById(id int64) (*Person, error) {
 	return &person, nil
 }
 
-func (r *Repo) UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+// GetPersonsByIDs - the persons of the ids that exist
+func (r *Repo) GetPersonsByIDs(ctx context.Context, ids []int64) ([]Person, error) {
+	persons := make([]Person, 0, len(ids))
+	if len(ids) == 0 {
+		return persons, nil
+	}
+
+	if err := r.read(ctx, func(db *gorm.DB) error { return db.Where("id IN (?)", ids).Find(&persons).Error }); err != nil {
+		logrus.Error("can't get persons by ids ", err)
+		return nil, err
+	}
+
+	return persons, nil
+}
+
+// FindPersons - a page of the persons matching the filter. the normalized names hold only letters, digits and spaces,
+// so the name has no LIKE wildcards to escape
+func (r *Repo) FindPersons(ctx context.Context, filter PersonFilter) ([]Person, error) {
+	persons := make([]Person, 0)
+
+	err := r.read(ctx, func(db *gorm.DB) error {
+		db = db.Where("id > ?", filter.AfterID)
+		if filter.Market != "" {
+			db = db.Where("market = ?", filter.Market)
+		}
+		if filter.Name != "" {
+			db = db.Where("normalized_name LIKE ?", "%"+filter.Name+"%")
+		}
+		if filter.RatingUpdated != nil {
+			db = db.Where("rating_updated = ?", *filter.RatingUpdated)
+		}
+		return db.Order("id").Limit(filter.Limit).Find(&persons).Error
+	})
+	if err != nil {
+		logrus.Error("can't find persons ", err)
+		return nil, err
+	}
+
+	return persons, nil
+}
+
+func (r *Repo) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
 	p := Person{}
 
-	if err := r.db.First(&p, id).Error; err != nil {
+	err := r.bound(ctx, func(db *gorm.DB) error {
+		if err := db.First(&p, id).Error; err != nil {
+			return err
+		}
+
+		db.First(&p)
+		p.Name = createPersonRequest.Name
+		p.Age = createPersonRequest.Age
+		p.Height = createPersonRequest.Height
+		p.Weight = createPersonRequest.Weight
+		p.RatingUpdated = createPersonRequest.RatingUpdate
+		if createPersonRequest.Market != "" {
+			p.Market = createPersonRequest.Market
+		}
+		return db.Save(&p).Error
+	})
+	if err != nil {
 		logrus.Error("can't get persons", err)
 		return nil, err
 	}
 
-	r.db.First(&p)
-	p.Name = createPersonRequest.Name
-	p.Age = createPersonRequest.Age
-	p.Height = createPersonRequest.Height
-	p.Weight = createPersonRequest.Weight
-	p.RatingUpdated = createPersonRequest.RatingUpdate
-	r.db.Save(&p)
-
 	return &p, nil
 }
 
-func (r *Repo) UpdatePersonRating(id int64, updated bool) error {
+func (r *Repo) UpdatePersonRating(ctx context.Context, id int64, updated bool) error {
 	p := Person{}
 
-	if err := r.db.Find(&p, "id = ?", strconv.FormatInt(id, 10)).Error; err != nil {
-		logrus.Error("can't get person by id - update order ", err)
-		return err
-	}
+	return r.bound(ctx, func(db *gorm.DB) error {
+		if err := db.Find(&p, "id = ?", strconv.FormatInt(id, 10)).Error; err != nil {
+			logrus.Error("can't get person by id - update order ", err)
+			return err
+		}
 
-	r.db.First(&p)
+		db.First(&p)
 
-	p.RatingUpdated = updated
-	r.db.Save(&p)
+		p.RatingUpdated = updated
 
-	return nil
+		return db.Save(&p).Error
+	})
 }
 
-
-func (r *Repo) DeletePerson(id int64) error {
+func (r *Repo) DeletePerson(ctx context.Context, id int64) error {
 	// using this to create object like in DB (with all the columns) and then pointer to this object
 	// the query will look like -> DELETE FROM person WHERE id = {id};
 	p := Person{}
 
-	if err := r.db.First(&p, id).Error; err != nil {
-		logrus.Error("can't delete person ", err)
-		return err
+	return r.bound(ctx, func(db *gorm.DB) error {
+		if err := db.First(&p, id).Error; err != nil {
+			logrus.Error("can't delete person ", err)
+			return err
+		}
+
+		return db.Delete(&p, id).Error
+	})
+}
+
+func (r *Repo) CreateRatingSnapshot(ctx context.Context, snapshot *RatingSnapshot) error {
+	return r.single(ctx, func(db *gorm.DB) error {
+		return db.Create(snapshot).Error
+	})
+}
+
+func (r *Repo) GetLastRatingSnapshot(ctx context.Context, personID int64, kind string) (*RatingSnapshot, error) {
+	snapshot := RatingSnapshot{}
+
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Where("person_id = ? AND kind = ?", personID, kind).Order("created_at desc").First(&snapshot).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+func (r *Repo) GetRatingSnapshots(ctx context.Context, personID int64, kind string, from, to time.Time) ([]RatingSnapshot, error) {
+	snapshots := make([]RatingSnapshot, 0)
+
+	err := r.read(ctx, func(db *gorm.DB) error {
+		return db.Where("person_id = ? AND kind = ? AND created_at >= ? AND created_at < ?", personID, kind, from, to).Order("created_at").Find(&snapshots).Error
+	})
+	if err != nil {
+		logrus.Error("can't get rating snapshots ", err)
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// SearchPersons - trigram similarity search over the normalized names, or their latin spelling when transliterated
+func (r *Repo) SearchPersons(ctx context.Context, query string, market string, transliterated bool, limit int) ([]PersonMatch, error) {
+	column := "normalized_name"
+	if transliterated {
+		column = "latin_name"
+	}
+
+	rows := make([]struct {
+		Person
+		Similarity float64
+	}, 0)
+	err := r.read(ctx, func(db *gorm.DB) error {
+		db = db.Table("persons").
+			Select("persons.*, similarity("+column+", ?) AS similarity", query).
+			Where(column+" % ?", query)
+		if market != "" {
+			db = db.Where("market = ?", market)
+		}
+		return db.Order("similarity desc").Limit(limit).Scan(&rows).Error
+	})
+	if err != nil {
+		logrus.Error("can't search persons ", err)
+		return nil, err
+	}
+
+	matches := make([]PersonMatch, 0, len(rows))
+	for _, row := range rows {
+		matches = append(matches, PersonMatch{Person: row.Person, Similarity: row.Similarity})
 	}
 
-	return r.db.Delete(&p, id).Error
+	return matches, nil
 }
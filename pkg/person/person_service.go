@@ -1,7 +1,14 @@
 package person
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/ansel1/merry"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/flags"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/webhooks"
+	"github.com/gtforge/gorm"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"io/ioutil"
@@ -10,220 +17,504 @@ import (
 	"time"
 )
 
+var ErrOrdersService = merry.New("orders service lookup failed").WithHTTPCode(http.StatusBadGateway)
+
 type Service interface {
-	GetPersons() ([]Person, error)
-	GetAllRatingsByWaitingGroups() ([]Rating, error)
-	GetAllRatingsByChannels() ([]Rating, error)
-	CreatePersons(person *CreatePersonRequest) (*Person, error)
-	GetPersonByID(id int64) (*Person, error)
-	GetRatingByPersonID(id int64) (float64, error)
-	UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
-	UpdatePersonRating(id int64, updated bool) (error)
-	DeletePerson(id int64) error
-	AsyncRun()([]Rating, error)
+	GetPersons(ctx context.Context) ([]Person, error)
+	FindPersons(ctx context.Context, filter PersonFilter) ([]Person, error)
+	GetAllRatingsByWaitingGroups(ctx context.Context) ([]Rating, error)
+	GetAllRatingsByChannels(ctx context.Context) ([]Rating, error)
+	CreatePersons(ctx context.Context, person *CreatePersonRequest) (*Person, error)
+	GetPersonByID(ctx context.Context, id int64) (*Person, error)
+	GetRatingByPersonID(ctx context.Context, id int64) (*RatingDetails, error)
+	GetRatingByPersonIDWithStrategy(ctx context.Context, id int64, strategy string) (*RatingDetails, error)
+	GetRatingsByPersonIDs(ctx context.Context, ids []int64) (map[int64]BatchRating, error)
+	UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
+	UpdatePersonRating(ctx context.Context, id int64, updated bool) (error)
+	DeletePerson(ctx context.Context, id int64) error
+	AsyncRun(ctx context.Context)([]Rating, error)
+	GetTopRatings(ctx context.Context, n int64, desc bool) ([]RankedRating, error)
+	GetRatingRank(ctx context.Context, id int64) (*RatingRank, error)
+	RebuildLeaderboard(ctx context.Context) error
+	ForMarket(market string) Service
+	GetRatingHistory(ctx context.Context, id int64, from, to time.Time, interval string) ([]RatingHistoryBucket, error)
+	SearchPersons(ctx context.Context, query string, transliterated bool, limit int) ([]PersonMatch, error)
 }
 
 type PersonService struct {
 	repository PersonRepository
 	store      Provider
 	cache 	   InMemoryProvider
+	leaderboard LeaderboardProvider
+	strategy   RatingStrategy
+	webhooks   webhooks.Publisher
+	stream     RatingStreamProvider
+	market     string
 }
 
-func NewPersonService(personRepository PersonRepository) Service {
+func NewPersonService(backends Backends) Service {
+	strategy, err := NewRatingStrategy("")
+	if err != nil {
+		logrus.Error("invalid configured rating strategy, falling back to mean ", err)
+		strategy = meanRating{}
+	}
+
 	return &PersonService{
-		repository: personRepository,
-		store:      Instance,
-		cache:      CacheInstance,
+		repository: backends.Repository,
+		store:      backends.Store,
+		cache:      backends.Cache,
+		leaderboard: backends.Leaderboard,
+		strategy:   strategy,
+		webhooks:   backends.Webhooks,
+		stream:     backends.Stream,
 	}
 }
 
-func (s PersonService) CreatePersons(person *CreatePersonRequest) (*Person, error) {
+// ForMarket - a service scoped to the market, listings, ratings and redis keys only cover its persons
+func (s PersonService) ForMarket(market string) Service {
+	return s.scoped(market)
+}
+
+func (s PersonService) scoped(market string) PersonService {
+	s.market = market
+	s.store = s.store.ForMarket(market)
+	s.cache = s.cache.ForMarket(market)
+	s.leaderboard = s.leaderboard.ForMarket(market)
+	return s
+}
+
+func (s PersonService) CreatePersons(ctx context.Context, person *CreatePersonRequest) (*Person, error) {
+	market, err := s.personMarket(person.Market)
+	if err != nil {
+		return nil, err
+	}
+
 	p := Person{
 		Name:      person.Name,
 		Age:       person.Age,
 		Weight:    person.Weight,
 		Height:    person.Height,
+		Market:    market,
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.repository.CreatePerson(&p); err != nil {
+	if err := s.repository.CreatePerson(ctx, &p); err != nil {
 		return nil, err
 	}
-	s.store.CreatePersons(&p)
+	s.cachePerson(ctx, &p)
+	s.publish(webhooks.EventPersonCreated, p)
 
 	logrus.Debug("get the new persons value")
-	persons, err := s.repository.GetPersons()
-
-	if err != nil {
-		logrus.Error("error - persons ", err)
+	if err := s.refreshPersons(ctx, p.Market); err != nil {
 		return nil, err
 	}
 
-	s.store.SetPersons(persons)
 	return &p, nil
 }
 
-func (s PersonService) GetPersonByID(id int64) (*Person, error) {
-	person, err := s.store.GetPersonByID(id)
+// personMarket - the market of a created person, the requested one must match the scope of the service
+func (s PersonService) personMarket(requested string) (string, error) {
+	market, err := NormalizeMarket(requested)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case market == "" && s.market == "":
+		return DefaultMarket(), nil
+	case market == "":
+		return s.market, nil
+	case s.market != "" && market != s.market:
+		return "", ErrUnknownMarket
+	}
+
+	return market, nil
+}
+
+func (s PersonService) GetPersonByID(ctx context.Context, id int64) (*Person, error) {
+	person, err := s.store.GetPersonByID(ctx, id)
 	if err == nil {
 		return person, nil
 	}
 
-	person, err = s.repository.GetPersonById(id)
+	person, err = s.repository.GetPersonById(ctx, id)
 
 	if err != nil {
 		logrus.Error("error - person by id ", err)
 		return nil, err
 	}
+	if s.market != "" && person.Market != s.market {
+		return nil, gorm.ErrRecordNotFound
+	}
 	logrus.Debug("persons num {}")
 
 	return person, nil
 }
 
-func (s PersonService) GetRatingByPersonID(id int64) (float64, error) {
+// FindPersons - a page of the persons of the service market, read from postgres. the name is normalized like the
+// names of the persons
+func (s PersonService) FindPersons(ctx context.Context, filter PersonFilter) ([]Person, error) {
+	filter.Market = s.market
+	filter.Name = NormalizeName(filter.Name)
+
+	return s.repository.FindPersons(ctx, filter)
+}
+
+// listPersons - the persons of the service market from postgres
+func (s PersonService) listPersons(ctx context.Context) ([]Person, error) {
+	if s.market == "" {
+		return s.repository.GetPersons(ctx)
+	}
+
+	return s.repository.GetPersonsByMarket(ctx, s.market)
+}
+
+// refreshPersons - reload the cached persons lists of the global and the market scopes
+func (s PersonService) refreshPersons(ctx context.Context, market string) error {
+	for _, scope := range []string{"", market} {
+		persons, err := s.scoped(scope).listPersons(ctx)
+		if err != nil {
+			logrus.Error("error - persons ", err)
+			return err
+		}
+		s.store.ForMarket(scope).SetPersons(ctx, persons)
+	}
+
+	return nil
+}
+
+// cachePerson - write the person to the global and its market scopes
+func (s PersonService) cachePerson(ctx context.Context, p *Person) {
+	s.store.ForMarket("").CreatePersons(ctx, p)
+	s.store.ForMarket(p.Market).CreatePersons(ctx, p)
+}
+
+func (s PersonService) GetRatingByPersonID(ctx context.Context, id int64) (*RatingDetails, error) {
+	person, err := s.GetPersonByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if flags.Enabled(ctx, FlagTimeDecayRating, person.Market) {
+		strategy, err := NewRatingStrategy(TimeDecayStrategy)
+		if err != nil {
+			return nil, err
+		}
+		return s.ratingWithStrategy(ctx, *person, strategy)
+	}
+
+	details, err := s.store.GetRatingDetails(ctx, id)
+	if err == nil {
+		details.Source = RatingSourceCache
+		details.AgeSeconds = int64(time.Since(details.ComputedAt).Seconds())
+		return details, nil
+	}
+
+	details, err = s.fetchRatingDetails(ctx, *person)
+	if err != nil {
+		return nil, err
+	}
+	s.rank(ctx, []*RatingDetails{details}, personMarkets([]Person{*person}))
+
+	return details, nil
+}
+
+// GetRatingsByPersonIDs - resolve the ratings from the cache first, the misses are fetched from the orders service in bounded parallel
+func (s PersonService) GetRatingsByPersonIDs(ctx context.Context, ids []int64) (map[int64]BatchRating, error) {
+	persons, err := s.repository.GetPersonsByIDs(ctx, ids)
+	if err != nil {
+		logrus.Error("error - persons by ids ", err)
+		return nil, err
+	}
+	known := make(map[int64]Person, len(persons))
+	for _, p := range persons {
+		if s.market == "" || p.Market == s.market {
+			known[p.ID] = p
+		}
+	}
+
+	result := make(map[int64]BatchRating, len(ids))
+	lookup := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := known[id]; !ok {
+			result[id] = BatchRating{Status: BatchStatusNotFound, Error: "person not found"}
+			continue
+		}
+		lookup = append(lookup, id)
+	}
+
+	cached := s.store.GetRatingDetailsBatch(ctx, lookup)
+	misses := make([]int64, 0)
+	for _, id := range lookup {
+		details, ok := cached[id]
+		if !ok {
+			misses = append(misses, id)
+			continue
+		}
+		details.Source = RatingSourceCache
+		details.AgeSeconds = int64(time.Since(details.ComputedAt).Seconds())
+		result[id] = BatchRating{Status: BatchStatusOK, Rating: details}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fetched := make([]*RatingDetails, 0, len(misses))
+	semaphore := make(chan struct{}, batchConcurrency())
+dispatch:
+	for i, id := range misses {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			// the caller is gone, the misses left are not fetched
+			mu.Lock()
+			for _, id := range misses[i:] {
+				result[id] = BatchRating{Status: BatchStatusError, Error: ctx.Err().Error()}
+			}
+			mu.Unlock()
+			break dispatch
+		}
+		wg.Add(1)
+		id := id
+		tracing.Go(func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			details, err := s.fetchRatingDetails(ctx, known[id])
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result[id] = BatchRating{Status: BatchStatusError, Error: err.Error()}
+				return
+			}
+			result[id] = BatchRating{Status: BatchStatusOK, Rating: details}
+			fetched = append(fetched, details)
+		})
+	}
+	wg.Wait()
+	s.rank(ctx, fetched, personMarkets(persons))
+
+	return result, nil
+}
+
+// GetRatingByPersonIDWithStrategy - compute the rating with the requested strategy, the leaderboard is left untouched
+func (s PersonService) GetRatingByPersonIDWithStrategy(ctx context.Context, id int64, strategyName string) (*RatingDetails, error) {
+	strategy, err := NewRatingStrategy(strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	person, err := s.GetPersonByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ratingWithStrategy(ctx, *person, strategy)
+}
+
+func (s PersonService) ratingWithStrategy(ctx context.Context, person Person, strategy RatingStrategy) (*RatingDetails, error) {
+	orders, err := s.fetchOrdersByPersonID(ctx, person.ID, person.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	return newRatingDetails(person.ID, orders, strategy, time.Now()), nil
+}
+
+// fetchRatingDetails - compute the rating with the default strategy and refresh the snapshots and the cached details
+func (s PersonService) fetchRatingDetails(ctx context.Context, person Person) (*RatingDetails, error) {
+	orders, err := s.fetchOrdersByPersonID(ctx, person.ID, person.Market)
+	if err != nil {
+		return nil, err
+	}
+
+	details := newRatingDetails(person.ID, orders, s.ratingStrategy(), time.Now())
+	if rating, ok := details.rated(); ok {
+		s.recordRatingSnapshot(ctx, person, rating.Rating, details.OrderCount)
+	}
+	s.store.SetRatingDetails(ctx, details)
+
+	return details, nil
+}
+
+// fetchOrdersByPersonID - the orders of the person from the orders service of its market, also when the service
+// isn't scoped
+func (s PersonService) fetchOrdersByPersonID(ctx context.Context, id int64, market string) ([]Order, error) {
 	orders := make([]Order, 0)
-	response, err := http.Get(fmt.Sprintf("http://localhost:8081/api/v1/order_by_person/%v", id))
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%v/api/v1/order_by_person/%v", ordersURL(market), id), nil)
 	if err != nil {
-		return 0, err
+		logrus.Error("unable to build the orders request ", err)
+		return nil, merry.Here(ErrOrdersService).WithCause(err)
 	}
+	req = req.WithContext(ctx)
+	start := time.Now()
+	response, err := tracing.Do(http.DefaultClient, req)
+	metrics.OrdersRequestDuration.Observe(time.Since(start).Seconds(), market)
+	if err != nil {
+		logrus.Error("unable to reach the orders service ", err)
+		metrics.OrdersRequestErrors.Inc(market, "unreachable")
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, merry.Here(ErrOrdersService).WithCause(err)
+	}
+	defer response.Body.Close()
 
-	body, err2 := ioutil.ReadAll(response.Body)
-	if err2 != nil {
-		panic(err.Error())
+	if response.StatusCode != http.StatusOK {
+		logrus.Error("orders service responded with status ", response.StatusCode)
+		metrics.OrdersRequestErrors.Inc(market, "status")
+		return nil, merry.Here(ErrOrdersService).Appendf("status %v", response.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		logrus.Error("unable to read the orders response ", err)
+		metrics.OrdersRequestErrors.Inc(market, "decode")
+		return nil, err
 	}
 
 	err = json.Unmarshal(body, &orders)
 	if err != nil {
 		logrus.Error("unable unmarshal get persons", err)
-		return 0, err
+		metrics.OrdersRequestErrors.Inc(market, "decode")
+		return nil, merry.Here(ErrOrdersService).WithCause(err)
 	}
 
-	if len(orders) == 0 {
-		return 0.0, nil
-	}
+	return orders, nil
+}
 
-	var sum float64
-	for _, u := range orders {
-		sum += u.Rating
+func (s PersonService) ratingStrategy() RatingStrategy {
+	if s.strategy == nil {
+		return meanRating{}
 	}
 
-	return sum / float64(len(orders)), nil
+	return s.strategy
 }
 
-func (s PersonService) GetPersons() ([]Person, error) {
-	persons, err := s.store.GetPersons()
+func (s PersonService) GetPersons(ctx context.Context) ([]Person, error) {
+	persons, err := s.store.GetPersons(ctx)
 	if err == nil {
 		return persons, nil
 	}
 
-	persons, err = s.repository.GetPersons()
+	persons, err = s.listPersons(ctx)
 
 	if err != nil {
 		logrus.Error("error - persons ", err)
 		return nil, err
 	}
 
-	s.store.SetPersons(persons)
+	s.store.SetPersons(ctx, persons)
 	logrus.Debug("persons num {}", len(persons))
 
 	return persons, nil
 }
 
-func (s PersonService) GetAllRatingsByWaitingGroups() ([]Rating, error) {
-	inMemoryRatings, _ := s.cache.GetInMemoryRatings()
+func (s PersonService) GetAllRatingsByWaitingGroups(ctx context.Context) ([]Rating, error) {
+	inMemoryRatings, _ := s.cache.GetInMemoryRatings(ctx)
 	if len(inMemoryRatings) > 0 {
 		return inMemoryRatings, nil
 	}
 
-	ratings, _ := s.AsyncRun()
+	ratings, _ := s.AsyncRun(ctx)
 
-	s.cache.SetInMemoryRatings(ratings)
+	s.cache.SetInMemoryRatings(ctx, ratings)
 
 	return ratings, nil
 }
 
-func (s PersonService) GetAllRatingsByChannels() ([]Rating, error) {
-	persons, err := s.GetPersons()
+func (s PersonService) GetAllRatingsByChannels(ctx context.Context) ([]Rating, error) {
+	persons, err := s.GetPersons(ctx)
 	if err != nil {
 		logrus.Error("error - persons ", err)
 		return []Rating{}, err
 	}
 
-	c := make(chan Rating)
+	c := make(chan *RatingDetails)
 	for _, person := range persons {
-		go s.GetRatingChannelByPersonID(person, c)
+		go s.GetRatingChannelByPersonID(ctx, person, c)
 
 	}
-	result := make([]Rating, len(persons))
-	for i, _ := range result {
-		result[i] = <-c
-		fmt.Println(fmt.Sprintf("personID: %v rating: %v", result[i].PersonID, result[i].Rating))
+	fetched := make([]*RatingDetails, 0, len(persons))
+	for range persons {
+		if details := <-c; details != nil {
+			fetched = append(fetched, details)
+		}
+	}
+	result, _ := s.rank(ctx, fetched, personMarkets(persons))
+	for _, r := range result {
+		fmt.Println(fmt.Sprintf("personID: %v rating: %v", r.PersonID, r.Rating))
 	}
 
 	return result, nil
 }
 
-func (s PersonService) GetRatingChannelByPersonID(person Person, c chan Rating) {
-	orders := make([]Order, 0)
-	response, err := http.Get(fmt.Sprintf("http://localhost:8081/api/v1/order_by_person/%v", person.ID))
-	if err != nil {
-		c <- Rating{person.ID, 0.0}
-	}
-
-	body, err2 := ioutil.ReadAll(response.Body)
-	if err2 != nil {
-		c <- Rating{person.ID, 0.0}
-	}
-
-	err = json.Unmarshal(body, &orders)
+// GetRatingChannelByPersonID - send the rating details of the person, nil when they couldn't be fetched
+func (s PersonService) GetRatingChannelByPersonID(ctx context.Context, person Person, c chan *RatingDetails) {
+	details, err := s.fetchRatingDetails(ctx, person)
 	if err != nil {
-		c <- Rating{person.ID, 0.0}
+		logrus.WithField("person_id", person.ID).Error("error - rating ", err)
+		c <- nil
+		return
 	}
-
-	if len(orders) == 0 {
-		c <- Rating{person.ID, 0.0}
-	}
-
-	var sum float64
-	for _, u := range orders {
-		sum += u.Rating
-	}
-
-	c <- Rating{person.ID, sum / float64(len(orders))}
+	c <- details
 }
 
-func (s PersonService) UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
-	person, err := s.repository.GetPersonById(id)
+func (s PersonService) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+	person, err := s.repository.GetPersonById(ctx, id)
 	if err != nil {
 		logrus.Error("error - person by id", err)
 		return nil, err
 	}
+	if s.market != "" && person.Market != s.market {
+		return nil, gorm.ErrRecordNotFound
+	}
 
-	personUpdated, err := s.repository.UpdatePerson(person.ID, createPersonRequest)
+	market, err := NormalizeMarket(createPersonRequest.Market)
 	if err != nil {
-		logrus.Error("error - unable to update person with personID: {}", person.ID)
 		return nil, err
 	}
+	createPersonRequest.Market = market
 
-	_, err = s.store.UpdatePerson(person.ID, createPersonRequest)
+	personUpdated, err := s.repository.UpdatePerson(ctx, person.ID, createPersonRequest)
 	if err != nil {
-		logrus.Error("error - update person from redis fails!!!", err)
+		logrus.Error("error - unable to update person with personID: {}", person.ID)
 		return nil, err
 	}
 
-	persons, err := s.repository.GetPersons()
+	s.cachePerson(ctx, personUpdated)
+	if personUpdated.Market != person.Market {
+		s.leaveMarket(ctx, person.ID, person.Market, personUpdated.Market)
+	}
+	s.publish(webhooks.EventPersonUpdated, personUpdated)
 
-	if err != nil {
-		logrus.Error("error - persons", err)
-		return nil, err
+	for _, market := range []string{person.Market, personUpdated.Market} {
+		if err := s.refreshPersons(ctx, market); err != nil {
+			return nil, err
+		}
 	}
 
-	s.store.SetPersons(persons)
 	return personUpdated, nil
 }
 
-func (s PersonService) UpdatePersonRating(id int64, updated bool) error {
-	err := s.repository.UpdatePersonRating(id, updated)
+// leaveMarket - drop the person cached under its old market and move its ranking to the new market leaderboard
+func (s PersonService) leaveMarket(ctx context.Context, id int64, from, to string) {
+	if err := s.store.ForMarket(from).DeletePerson(ctx, id); err != nil {
+		logrus.Error("error - unable to drop the person cached under its old market ", err)
+	}
+
+	leaderboard := s.leaderboard.ForMarket(from)
+	rank, err := leaderboard.GetRank(ctx, id)
+	if err != nil {
+		return
+	}
+	if err := leaderboard.RemovePerson(ctx, id); err != nil {
+		return
+	}
+	s.leaderboard.ForMarket(to).SetRatings(ctx, []Rating{{id, rank.Rating}})
+}
+
+func (s PersonService) UpdatePersonRating(ctx context.Context, id int64, updated bool) error {
+	err := s.repository.UpdatePersonRating(ctx, id, updated)
 	if err != nil {
 		logrus.Error("error - unable to update person with personID: {}", id)
 		return err
@@ -233,49 +524,134 @@ func (s PersonService) UpdatePersonRating(id int64, updated bool) error {
 }
 
 
-func (s PersonService) DeletePerson(id int64) error {
-	err := s.store.DeletePerson(id)
+func (s PersonService) DeletePerson(ctx context.Context, id int64) error {
+	person, err := s.GetPersonByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	err = s.repository.DeletePerson(id)
+	for _, scope := range []string{"", person.Market} {
+		if err := s.store.ForMarket(scope).DeletePerson(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	err = s.repository.DeletePerson(ctx, id)
 
 	if err != nil {
 		logrus.Error("error - delete person by id", err)
 		return err
 	}
+	s.leaderboard.ForMarket(person.Market).RemovePerson(ctx, id)
+	s.publish(webhooks.EventPersonDeleted, person)
 
 	logrus.Debug("get the new persons value")
-	persons, err := s.repository.GetPersons()
+	return s.refreshPersons(ctx, person.Market)
+}
 
-	if err != nil {
-		logrus.Error("error - persons", err)
-		return err
+// publish - notify the webhook subscribers, services built without a publisher don't publish
+func (s PersonService) publish(eventType string, data interface{}) {
+	if s.webhooks != nil {
+		s.webhooks.Publish(eventType, data)
 	}
-
-	s.store.SetPersons(persons)
-	return nil
 }
 
-func (s PersonService) AsyncRun()([]Rating, error)  {
-	persons, err := s.GetPersons()
+func (s PersonService) AsyncRun(ctx context.Context)([]Rating, error)  {
+	persons, err := s.GetPersons(ctx)
 	if err != nil {
 		logrus.Error("error - persons ", err)
 		return []Rating{}, err
 	}
 
-	ratings := make([]Rating, 0)
+	ratings, _ := s.rank(ctx, s.computeRatings(ctx, persons), personMarkets(persons))
+
+	return ratings, nil
+}
+
+// computeRatings - the rating details of the persons, the persons whose orders couldn't be fetched are left out
+func (s PersonService) computeRatings(ctx context.Context, persons []Person) []*RatingDetails {
+	computed := make([]*RatingDetails, 0, len(persons))
+	var mu sync.Mutex
 	var wg sync.WaitGroup
 	for _, u := range persons {
 		wg.Add(1)
-		go func(id int64) {
+		person := u
+		tracing.Go(func() {
 			defer wg.Done()
-			r, _ := s.GetRatingByPersonID(id)
-			ratings = append(ratings, Rating{id, r})
-		}(u.ID)
+			details, err := s.fetchRatingDetails(ctx, person)
+			if err != nil {
+				// the person keeps its last ranking until the orders service answers again
+				logrus.WithField("person_id", person.ID).Error("error - rating ", err)
+				return
+			}
+			mu.Lock()
+			computed = append(computed, details)
+			mu.Unlock()
+		})
 	}
 	wg.Wait()
 
+	return computed
+}
+
+// rank - put the rated persons on the leaderboards of their markets and take the persons without ratings off them, no
+// ratings is not ranked as a 0 rating. the rated persons are returned
+func (s PersonService) rank(ctx context.Context, details []*RatingDetails, markets map[int64]string) ([]Rating, error) {
+	ratings := make([]Rating, 0, len(details))
+	rated := make(map[string][]Rating)
+	unrated := make(map[string][]int64)
+	for _, d := range details {
+		market := markets[d.PersonID]
+		if rating, ok := d.rated(); ok {
+			ratings = append(ratings, rating)
+			rated[market] = append(rated[market], rating)
+			continue
+		}
+		unrated[market] = append(unrated[market], d.PersonID)
+	}
+
+	for market, marketRatings := range rated {
+		if err := s.leaderboard.ForMarket(market).SetRatings(ctx, marketRatings); err != nil {
+			return ratings, err
+		}
+	}
+	for market, ids := range unrated {
+		if err := s.leaderboard.ForMarket(market).RemovePerson(ctx, ids...); err != nil {
+			return ratings, err
+		}
+	}
+
 	return ratings, nil
 }
+
+// personMarkets - the market of every person by id
+func personMarkets(persons []Person) map[int64]string {
+	markets := make(map[int64]string, len(persons))
+	for _, p := range persons {
+		markets[p.ID] = p.Market
+	}
+
+	return markets
+}
+
+// GetTopRatings - the leaderboard as the worker filled it, empty until its first run
+func (s PersonService) GetTopRatings(ctx context.Context, n int64, desc bool) ([]RankedRating, error) {
+	return s.leaderboard.GetTop(ctx, n, desc)
+}
+
+// GetRatingRank - ErrNotRanked until the worker ranked the person
+func (s PersonService) GetRatingRank(ctx context.Context, id int64) (*RatingRank, error) {
+	return s.leaderboard.GetRank(ctx, id)
+}
+
+// RebuildLeaderboard - recompute the ratings of all the persons stored in postgres and refill the leaderboard
+func (s PersonService) RebuildLeaderboard(ctx context.Context) error {
+	persons, err := s.listPersons(ctx)
+	if err != nil {
+		logrus.Error("error - persons ", err)
+		return err
+	}
+
+	_, err = s.rank(ctx, s.computeRatings(ctx, persons), personMarkets(persons))
+	return err
+}
@@ -1,10 +1,12 @@
 package person
 
 import (
+	"context"
 	errors "github.com/ansel1/merry"
 	"github.com/golang/mock/gomock"
 	"github.com/onsi/ginkgo"
 	"github.com/onsi/gomega"
+	"gopkg.in/redis.v5"
 	"testing"
 	"time"
 )
@@ -14,6 +16,56 @@ func TestPersonService(t *testing.T) {
 	ginkgo.RunSpecs(t, "person service test")
 }
 
+// missingStore - a redis store without entries, every lookup misses and the service falls back to the repository
+type missingStore struct{}
+
+func (missingStore) GetPersons(ctx context.Context) ([]Person, error)  { return nil, redis.Nil }
+func (missingStore) CreatePersons(ctx context.Context, person *Person) {}
+func (missingStore) SetPersons(ctx context.Context, persons []Person)  {}
+func (missingStore) GetPersonByID(ctx context.Context, id int64) (*Person, error) {
+	return nil, redis.Nil
+}
+func (missingStore) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+	return nil, redis.Nil
+}
+func (missingStore) DeletePerson(ctx context.Context, id int64) error { return nil }
+func (missingStore) GetRatingDetails(ctx context.Context, personID int64) (*RatingDetails, error) {
+	return nil, redis.Nil
+}
+func (missingStore) SetRatingDetails(ctx context.Context, details *RatingDetails) {}
+func (missingStore) GetRatingDetailsBatch(ctx context.Context, personIDs []int64) map[int64]*RatingDetails {
+	return map[int64]*RatingDetails{}
+}
+func (missingStore) GetLastRatingSnapshots(ctx context.Context, personID int64) (*LastRatingSnapshots, error) {
+	return nil, redis.Nil
+}
+func (missingStore) SetLastRatingSnapshots(ctx context.Context, last *LastRatingSnapshots) {}
+func (s missingStore) ForMarket(market string) Provider                                    { return s }
+
+// emptyCache - an in memory ratings cache that is never filled
+type emptyCache struct{}
+
+func (emptyCache) GetInMemoryRatings(ctx context.Context) ([]Rating, error)       { return nil, nil }
+func (emptyCache) SetInMemoryRatings(ctx context.Context, ratings []Rating) error { return nil }
+func (emptyCache) WrittenAt(ctx context.Context) (time.Time, error) {
+	return time.Time{}, ErrRatingsNotCached
+}
+func (c emptyCache) ForMarket(market string) InMemoryProvider { return c }
+
+// emptyLeaderboard - a leaderboard that ranks no one
+type emptyLeaderboard struct{}
+
+func (emptyLeaderboard) SetRatings(ctx context.Context, ratings []Rating) error { return nil }
+func (emptyLeaderboard) GetTop(ctx context.Context, n int64, desc bool) ([]RankedRating, error) {
+	return []RankedRating{}, nil
+}
+func (emptyLeaderboard) GetRank(ctx context.Context, personID int64) (*RatingRank, error) {
+	return nil, ErrNotRanked
+}
+func (emptyLeaderboard) RemovePerson(ctx context.Context, personIDs ...int64) error { return nil }
+func (emptyLeaderboard) Count(ctx context.Context) (int64, error)                   { return 0, nil }
+func (l emptyLeaderboard) ForMarket(market string) LeaderboardProvider              { return l }
+
 var _ = ginkgo.Describe("person service", func() {
 
 	var (
@@ -25,14 +77,19 @@ var _ = ginkgo.Describe("person service", func() {
 		err                  error
 		ctrl                 *gomock.Controller
 		personRepositoryMock *MockPersonRepository
+		ctx                  context.Context
 	)
 
 	ginkgo.BeforeEach(func() {
 		ctrl = gomock.NewController(ginkgo.GinkgoT())
 		personRepositoryMock = NewMockPersonRepository(ctrl)
 		personService = &PersonService{
-			repository: personRepositoryMock,
+			repository:  personRepositoryMock,
+			store:       missingStore{},
+			cache:       emptyCache{},
+			leaderboard: emptyLeaderboard{},
 		}
+		ctx = context.Background()
 		personsArray = make([]Person, 0)
 	})
 
@@ -40,12 +97,12 @@ var _ = ginkgo.Describe("person service", func() {
 
 		//before -> just before -> it
 		ginkgo.JustBeforeEach(func() {
-			persons, err = personService.GetPersons()
+			persons, err = personService.GetPersons(ctx)
 		})
 		ginkgo.Context("validate that persons not return persons", func() {
 
 			ginkgo.BeforeEach(func() {
-				personRepositoryMock.EXPECT().GetPersons().Return(nil, errors.New("Invalid"))
+				personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return(nil, errors.New("Invalid"))
 			})
 
 			ginkgo.It("do", func() {
@@ -59,7 +116,7 @@ var _ = ginkgo.Describe("person service", func() {
 			ginkgo.BeforeEach(func() {
 				personsArray = append(personsArray, Person{Name: "elad", Height: "179", Weight: "100", Age: 50})
 				personsArray = append(personsArray, Person{Name: "lio", Height: "177", Weight: "110", Age: 28})
-				personRepositoryMock.EXPECT().GetPersons().Return(personsArray, nil)
+				personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return(personsArray, nil)
 			})
 
 			ginkgo.It("do", func() {
@@ -69,11 +126,31 @@ var _ = ginkgo.Describe("person service", func() {
 		})
 	})
 
+	var _ = ginkgo.Describe("findPersons Validations", func() {
+
+		ginkgo.Context("validate that the page is filtered by the repository with the market and the normalized name", func() {
+
+			ginkgo.BeforeEach(func() {
+				updated := true
+				personsArray = append(personsArray, Person{ID: 11, Name: "Élad Cohen", Market: "IL", RatingUpdated: true})
+				personRepositoryMock.EXPECT().
+					FindPersons(gomock.Any(), PersonFilter{Market: "IL", AfterID: 10, Name: "elad cohen", RatingUpdated: &updated, Limit: 5}).
+					Return(personsArray, nil)
+				persons, err = personService.ForMarket("IL").FindPersons(ctx, PersonFilter{AfterID: 10, Name: "Élad  COHEN", RatingUpdated: &updated, Limit: 5})
+			})
+
+			ginkgo.It("do", func() {
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(persons).To(gomega.Equal(personsArray))
+			})
+		})
+	})
+
 	var _ = ginkgo.Describe("getPersonByID Validations", func() {
 
 		//before -> just before -> it
 		ginkgo.JustBeforeEach(func() {
-			person, err = personService.GetPersonByID(personsArray[0].ID)
+			person, err = personService.GetPersonByID(ctx, personsArray[0].ID)
 		})
 
 		ginkgo.BeforeEach(func() {
@@ -83,7 +160,7 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that getPersonById not return person", func() {
 
 			ginkgo.BeforeEach(func() {
-				personRepositoryMock.EXPECT().GetPersonById(personsArray[0].ID).Return(nil, errors.New("Invalid"))
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), personsArray[0].ID).Return(nil, errors.New("Invalid"))
 			})
 
 			ginkgo.It("do", func() {
@@ -95,7 +172,7 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that persons return the specific person", func() {
 
 			ginkgo.BeforeEach(func() {
-				personRepositoryMock.EXPECT().GetPersonById(personsArray[0].ID).Return(&personsArray[0], nil)
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), personsArray[0].ID).Return(&personsArray[0], nil)
 			})
 
 			ginkgo.It("do", func() {
@@ -109,7 +186,7 @@ var _ = ginkgo.Describe("person service", func() {
 
 		//before -> just before -> it
 		ginkgo.JustBeforeEach(func() {
-			err = personService.DeletePerson(personsArray[0].ID)
+			err = personService.DeletePerson(ctx, personsArray[0].ID)
 		})
 
 		ginkgo.BeforeEach(func() {
@@ -119,7 +196,8 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that deletePerson not delete person", func() {
 
 			ginkgo.BeforeEach(func() {
-				personRepositoryMock.EXPECT().DeletePerson(personsArray[0].ID).Return(errors.New("can't delete"))
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), personsArray[0].ID).Return(&personsArray[0], nil)
+				personRepositoryMock.EXPECT().DeletePerson(gomock.Any(), personsArray[0].ID).Return(errors.New("can't delete"))
 			})
 
 			ginkgo.It("do", func() {
@@ -130,7 +208,9 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that deletePerson delete person", func() {
 
 			ginkgo.BeforeEach(func() {
-				personRepositoryMock.EXPECT().DeletePerson(personsArray[0].ID).Return(nil)
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), personsArray[0].ID).Return(&personsArray[0], nil)
+				personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return(personsArray, nil).AnyTimes()
+				personRepositoryMock.EXPECT().DeletePerson(gomock.Any(), personsArray[0].ID).Return(nil)
 			})
 
 			ginkgo.It("do", func() {
@@ -143,7 +223,7 @@ var _ = ginkgo.Describe("person service", func() {
 
 		//before -> just before -> it
 		ginkgo.JustBeforeEach(func() {
-			person, err = personService.CreatePersons(&createPerson)
+			person, err = personService.CreatePersons(ctx, &createPerson)
 		})
 
 		ginkgo.BeforeEach(func() {
@@ -153,7 +233,7 @@ var _ = ginkgo.Describe("person service", func() {
 
 			ginkgo.BeforeEach(func() {
 
-				personRepositoryMock.EXPECT().CreatePerson(gomock.Any()).Return(errors.New("can't create a person"))
+				personRepositoryMock.EXPECT().CreatePerson(gomock.Any(), gomock.Any()).Return(errors.New("can't create a person"))
 			})
 
 			ginkgo.It("do", func() {
@@ -164,8 +244,10 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that createPerson not able to create person", func() {
 
 			ginkgo.BeforeEach(func() {
+				personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return(personsArray, nil).AnyTimes()
+				personRepositoryMock.EXPECT().GetPersonsByMarket(gomock.Any(), gomock.Any()).Return(personsArray, nil).AnyTimes()
 
-				personRepositoryMock.EXPECT().CreatePerson(gomock.Any()).Return(nil)
+				personRepositoryMock.EXPECT().CreatePerson(gomock.Any(), gomock.Any()).Return(nil)
 			})
 
 			ginkgo.It("do", func() {
@@ -181,7 +263,7 @@ var _ = ginkgo.Describe("person service", func() {
 		)
 		//before -> just before -> it
 		ginkgo.JustBeforeEach(func() {
-			person, err = personService.UpdatePerson(1, &createPerson)
+			person, err = personService.UpdatePerson(ctx, 1, &createPerson)
 		})
 
 		ginkgo.BeforeEach(func() {
@@ -190,9 +272,10 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that updatePerson update the person", func() {
 
 			ginkgo.BeforeEach(func() {
-				per = &Person{1, createPerson.Name, createPerson.Age, createPerson.Height, createPerson.Weight, time.Now()}
-				personRepositoryMock.EXPECT().GetPersonById(per.ID).Return(per, nil)
-				personRepositoryMock.EXPECT().UpdatePerson(per.ID, &createPerson).Return(per, nil)
+				per = &Person{ID: 1, Name: createPerson.Name, Age: createPerson.Age, Height: createPerson.Height, Weight: createPerson.Weight, CreatedAt: time.Now()}
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), per.ID).Return(per, nil)
+				personRepositoryMock.EXPECT().UpdatePerson(gomock.Any(), per.ID, &createPerson).Return(per, nil)
+				personRepositoryMock.EXPECT().GetPersons(gomock.Any()).Return([]Person{*per}, nil).AnyTimes()
 			})
 
 			ginkgo.It("do", func() {
@@ -203,9 +286,9 @@ var _ = ginkgo.Describe("person service", func() {
 		ginkgo.Context("validate that updatePerson not update the person", func() {
 
 			ginkgo.BeforeEach(func() {
-				per = &Person{1, createPerson.Name, createPerson.Age, createPerson.Height, createPerson.Weight, time.Now()}
-				personRepositoryMock.EXPECT().GetPersonById(per.ID).Return(per, nil)
-				personRepositoryMock.EXPECT().UpdatePerson(per.ID, &createPerson).Return(nil, errors.New("unable to update person"))
+				per = &Person{ID: 1, Name: createPerson.Name, Age: createPerson.Age, Height: createPerson.Height, Weight: createPerson.Weight, CreatedAt: time.Now()}
+				personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), per.ID).Return(per, nil)
+				personRepositoryMock.EXPECT().UpdatePerson(gomock.Any(), per.ID, &createPerson).Return(nil, errors.New("unable to update person"))
 			})
 
 			ginkgo.It("do", func() {
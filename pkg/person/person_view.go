@@ -0,0 +1,60 @@
+package person
+
+import (
+	"context"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+)
+
+// canViewFullPerson - editors and admins see every field, readers get the ReaderPerson view
+func canViewFullPerson(ctx context.Context) bool {
+	identity, ok := auth.FromContext(ctx)
+	return ok && identity.HasRole(auth.RoleEditor)
+}
+
+func newReaderPerson(p Person) ReaderPerson {
+	return ReaderPerson{
+		ID:            p.ID,
+		Name:          p.Name,
+		Age:           p.Age,
+		RatingUpdated: p.RatingUpdated,
+		Market:        p.Market,
+		CreatedAt:     p.CreatedAt,
+	}
+}
+
+// personView - the person as the caller of the request is allowed to see it
+func personView(ctx context.Context, p *Person) interface{} {
+	if p == nil || canViewFullPerson(ctx) {
+		return p
+	}
+
+	return newReaderPerson(*p)
+}
+
+// personsView - the persons as the caller of the request is allowed to see them
+func personsView(ctx context.Context, persons []Person) interface{} {
+	if canViewFullPerson(ctx) {
+		return persons
+	}
+
+	views := make([]ReaderPerson, 0, len(persons))
+	for _, p := range persons {
+		views = append(views, newReaderPerson(p))
+	}
+
+	return views
+}
+
+// matchesView - the search matches as the caller of the request is allowed to see them
+func matchesView(ctx context.Context, matches []PersonMatch) interface{} {
+	if canViewFullPerson(ctx) {
+		return matches
+	}
+
+	views := make([]ReaderPersonMatch, 0, len(matches))
+	for _, match := range matches {
+		views = append(views, ReaderPersonMatch{Person: newReaderPerson(match.Person), Similarity: match.Similarity})
+	}
+
+	return views
+}
@@ -3,17 +3,21 @@ package person
 import "time"
 
 type Person struct {
-	ID            int64     `json:"id, omitempty"`
+	ID            int64     `json:"id"`
 	Name          string    `json:"name"`
 	Age           int64     `json:"age"`
 	Height        string    `json:"height"`
 	Weight        string    `json:"weight"`
 	RatingUpdated bool      `json:"rating_updated"`
+	Market        string    `json:"market"`
 	CreatedAt     time.Time `json:"created_at" sql:"type:time" gorm:"time"`
+	// the name as searched, maintained by BeforeSave
+	NormalizedName string `json:"-"`
+	LatinName      string `json:"-"`
 }
 
 type Order struct {
-	ID        int64     `json:"id, omitempty"`
+	ID        int64     `json:"id"`
 	OrderID   int64     `json:"order_id"`
 	PersonID  int64     `json:"person_id"`
 	Rating    float64   `json:"rating"`
@@ -25,10 +29,98 @@ type Rating struct {
 	Rating   float64 `json:"rating"`
 }
 
+type RankedRating struct {
+	Position int64   `json:"position"`
+	PersonID int64   `json:"person_id"`
+	Rating   float64 `json:"rating"`
+}
+
+type RatingRank struct {
+	PersonID   int64   `json:"person_id"`
+	Rating     float64 `json:"rating"`
+	Position   int64   `json:"position"`
+	Total      int64   `json:"total"`
+	Percentile float64 `json:"percentile"`
+}
+
 func (Person) TableName() string {
 	return "persons"
 }
 
+const (
+	SnapshotDaily    = "daily"
+	SnapshotOnChange = "change"
+)
+
+type RatingSnapshot struct {
+	ID         int64     `json:"id"`
+	PersonID   int64     `json:"person_id"`
+	Rating     float64   `json:"rating"`
+	OrderCount int64     `json:"order_count"`
+	Kind       string    `json:"kind"`
+	CreatedAt  time.Time `json:"created_at" sql:"type:time" gorm:"time"`
+}
+
+func (RatingSnapshot) TableName() string {
+	return "rating_snapshots"
+}
+
+// LastRatingSnapshots - the latest snapshot of each kind of a person, cached so a rating fetch doesn't query them
+type LastRatingSnapshots struct {
+	PersonID int64           `json:"person_id"`
+	Change   *RatingSnapshot `json:"change"`
+	Daily    *RatingSnapshot `json:"daily"`
+}
+
+// RatingChange - the data of the rating.changed webhook event
+type RatingChange struct {
+	PersonID   int64     `json:"person_id"`
+	Market     string    `json:"market,omitempty"`
+	Rating     float64   `json:"rating"`
+	OrderCount int64     `json:"order_count"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+type RatingHistoryBucket struct {
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Average float64   `json:"average"`
+	// OrderCount - the number of rated orders at the end of the bucket
+	OrderCount int64 `json:"order_count"`
+	Snapshots  int   `json:"snapshots"`
+}
+
 //func (p *Person) GetValue() {
 //
 //}
+
+// ReaderPerson - the person view served to readers, without the body measurements
+type ReaderPerson struct {
+	ID            int64     `json:"id"`
+	Name          string    `json:"name"`
+	Age           int64     `json:"age"`
+	RatingUpdated bool      `json:"rating_updated"`
+	Market        string    `json:"market"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PersonFilter - a page of persons ordered by id, the unset fields don't filter
+type PersonFilter struct {
+	Market string
+	// AfterID - the id of the last person of the previous page
+	AfterID int64
+	// Name - a normalized name the normalized names of the persons contain
+	Name          string
+	RatingUpdated *bool
+	Limit         int
+}
+
+type PersonMatch struct {
+	Person     Person  `json:"person"`
+	Similarity float64 `json:"similarity"`
+}
+
+type ReaderPersonMatch struct {
+	Person     ReaderPerson `json:"person"`
+	Similarity float64      `json:"similarity"`
+}
@@ -0,0 +1,98 @@
+package person
+
+import (
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	RatingStatusRated     = "rated"
+	RatingStatusNoRatings = "no_ratings"
+
+	RatingSourceOrders = "orders_service"
+	RatingSourceCache  = "cache"
+
+	BatchStatusOK       = "ok"
+	BatchStatusNotFound = "not_found"
+	BatchStatusError    = "error"
+)
+
+type RatingDetails struct {
+	PersonID int64  `json:"person_id"`
+	Status   string `json:"status"`
+	Strategy string `json:"strategy"`
+	// Average, Min and Max are null while the person has no rated orders
+	Average      *float64         `json:"average"`
+	OrderCount   int64            `json:"order_count"`
+	Min          *float64         `json:"min"`
+	Max          *float64         `json:"max"`
+	Distribution map[string]int64 `json:"distribution"`
+	LastOrderAt  *time.Time       `json:"last_order_at"`
+	ComputedAt   time.Time        `json:"computed_at"`
+	Source       string           `json:"source"`
+	AgeSeconds   int64            `json:"age_seconds"`
+}
+
+// BatchRating - a single entry of the batch rating lookup
+type BatchRating struct {
+	Status string         `json:"status"`
+	Rating *RatingDetails `json:"rating,omitempty"`
+	Error  string         `json:"error,omitempty"`
+}
+
+// newRatingDetails - summarize the valid orders of a person, the average is computed with the given strategy
+func newRatingDetails(personID int64, orders []Order, strategy RatingStrategy, now time.Time) *RatingDetails {
+	details := &RatingDetails{
+		PersonID:     personID,
+		Status:       RatingStatusNoRatings,
+		Strategy:     strategy.Name(),
+		Distribution: map[string]int64{},
+		ComputedAt:   now,
+		Source:       RatingSourceOrders,
+	}
+	for star := int(minValidRating); star <= int(maxValidRating); star++ {
+		details.Distribution[strconv.Itoa(star)] = 0
+	}
+
+	orders = validOrders(orders)
+	if len(orders) == 0 {
+		return details
+	}
+
+	average := strategy.Compute(orders, now)
+	min, max := orders[0].Rating, orders[0].Rating
+	lastOrderAt := orders[0].CreatedAt
+	for _, o := range orders {
+		min = math.Min(min, o.Rating)
+		max = math.Max(max, o.Rating)
+		if o.CreatedAt.After(lastOrderAt) {
+			lastOrderAt = o.CreatedAt
+		}
+		details.Distribution[strconv.Itoa(int(math.Round(o.Rating)))]++
+	}
+
+	details.Status = RatingStatusRated
+	details.Average = &average
+	details.OrderCount = int64(len(orders))
+	details.Min = &min
+	details.Max = &max
+	details.LastOrderAt = &lastOrderAt
+
+	return details
+}
+
+// rated - the leaderboard entry of the person, false while it has no ratings
+func (d RatingDetails) rated() (Rating, bool) {
+	if d.Average == nil {
+		return Rating{}, false
+	}
+
+	return Rating{d.PersonID, *d.Average}, true
+}
+
+// batchConcurrency - how many orders service calls a batch lookup runs at once
+func batchConcurrency() int {
+	return config.Current().Person.Rating.BatchConcurrency
+}
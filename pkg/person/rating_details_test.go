@@ -0,0 +1,170 @@
+package person
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/ordersstub"
+	"github.com/gtforge/gorm"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http/httptest"
+	"time"
+)
+
+var _ = ginkgo.Describe("rating details", func() {
+
+	var now = time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	ginkgo.Context("validate that a person without valid ratings has no average instead of a 0 rating", func() {
+
+		ginkgo.It("do", func() {
+			details := newRatingDetails(1, []Order{{Rating: 0}, {Rating: 7}}, meanRating{}, now)
+			gomega.Expect(details.Status).To(gomega.Equal(RatingStatusNoRatings))
+			gomega.Expect(details.Average).To(gomega.BeNil())
+			gomega.Expect(details.Min).To(gomega.BeNil())
+			gomega.Expect(details.Max).To(gomega.BeNil())
+			gomega.Expect(details.LastOrderAt).To(gomega.BeNil())
+			gomega.Expect(details.OrderCount).To(gomega.Equal(int64(0)))
+			gomega.Expect(details.Distribution).To(gomega.Equal(map[string]int64{"1": 0, "2": 0, "3": 0, "4": 0, "5": 0}))
+
+			_, ok := details.rated()
+			gomega.Expect(ok).To(gomega.BeFalse())
+
+			body, err := json.Marshal(details)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(string(body)).To(gomega.ContainSubstring(`"status":"no_ratings","strategy":"mean","average":null`))
+		})
+	})
+
+	ginkgo.Context("validate that the valid ratings are summarized", func() {
+
+		ginkgo.It("do", func() {
+			orders := []Order{
+				{Rating: 5, CreatedAt: now.Add(-2 * time.Hour)},
+				{Rating: 2.6, CreatedAt: now.Add(-time.Hour)},
+				{Rating: 9, CreatedAt: now},
+			}
+			details := newRatingDetails(1, orders, meanRating{}, now)
+			gomega.Expect(details.Status).To(gomega.Equal(RatingStatusRated))
+			gomega.Expect(*details.Average).To(gomega.BeNumerically("~", 3.8, 0.0001))
+			gomega.Expect(*details.Min).To(gomega.Equal(2.6))
+			gomega.Expect(*details.Max).To(gomega.Equal(5.0))
+			gomega.Expect(*details.LastOrderAt).To(gomega.Equal(now.Add(-time.Hour)))
+			gomega.Expect(details.OrderCount).To(gomega.Equal(int64(2)))
+			gomega.Expect(details.Distribution).To(gomega.Equal(map[string]int64{"1": 0, "2": 0, "3": 1, "4": 0, "5": 1}))
+
+			rating, ok := details.rated()
+			gomega.Expect(ok).To(gomega.BeTrue())
+			gomega.Expect(rating.PersonID).To(gomega.Equal(int64(1)))
+			gomega.Expect(rating.Rating).To(gomega.Equal(*details.Average))
+		})
+	})
+})
+
+var _ = ginkgo.Describe("batch ratings", func() {
+
+	var (
+		ctx                  context.Context
+		ctrl                 *gomock.Controller
+		personRepositoryMock *MockPersonRepository
+		personService        *PersonService
+		server               *httptest.Server
+		stub                 *ordersstub.Stub
+		previous             config.Config
+	)
+
+	ginkgo.BeforeEach(func() {
+		var err error
+		server, stub, err = ordersstub.NewServer(ordersstub.Options{})
+		gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+		previous = config.Current()
+		c := config.Defaults()
+		c.Markets = []string{"IL", "UK"}
+		c.Person.OrdersURL = server.URL
+		config.Set(c)
+
+		ctx = context.Background()
+		ctrl = gomock.NewController(ginkgo.GinkgoT())
+		personRepositoryMock = NewMockPersonRepository(ctrl)
+		personRepositoryMock.EXPECT().GetLastRatingSnapshot(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, gorm.ErrRecordNotFound).AnyTimes()
+		personRepositoryMock.EXPECT().CreateRatingSnapshot(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		personService = &PersonService{
+			repository:  personRepositoryMock,
+			store:       NewMemoryStore(),
+			cache:       emptyCache{},
+			leaderboard: NewMemoryLeaderboard(),
+		}
+
+		// the cached rating is served as is, the orders service would rate the person differently
+		average := 2.0
+		personService.store.SetRatingDetails(ctx, &RatingDetails{PersonID: 1, Status: RatingStatusRated, Average: &average, ComputedAt: time.Now().Add(-time.Minute)})
+		stub.SetOrders(1, []ordersstub.Order{{ID: 1, Rating: 5}})
+		stub.SetOrders(2, []ordersstub.Order{{ID: 2, Rating: 4}, {ID: 3, Rating: 5}})
+		stub.SetOrders(4, []ordersstub.Order{{ID: 4, Rating: 3}})
+	})
+
+	ginkgo.AfterEach(func() {
+		ctrl.Finish()
+		server.Close()
+		config.Set(previous)
+	})
+
+	ginkgo.Context("validate that the batch serves the cache, fetches the misses and reports the unknown persons", func() {
+
+		ginkgo.It("do", func() {
+			personRepositoryMock.EXPECT().GetPersonsByIDs(gomock.Any(), []int64{1, 2, 3}).Return([]Person{{ID: 1, Market: "IL"}, {ID: 2, Market: "IL"}}, nil)
+
+			ratings, err := personService.GetRatingsByPersonIDs(ctx, []int64{1, 2, 3})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(ratings).To(gomega.HaveLen(3))
+
+			gomega.Expect(ratings[1].Status).To(gomega.Equal(BatchStatusOK))
+			gomega.Expect(ratings[1].Rating.Source).To(gomega.Equal(RatingSourceCache))
+			gomega.Expect(*ratings[1].Rating.Average).To(gomega.Equal(2.0))
+			gomega.Expect(ratings[1].Rating.AgeSeconds).To(gomega.BeNumerically(">=", 60))
+
+			gomega.Expect(ratings[2].Status).To(gomega.Equal(BatchStatusOK))
+			gomega.Expect(ratings[2].Rating.Source).To(gomega.Equal(RatingSourceOrders))
+			gomega.Expect(*ratings[2].Rating.Average).To(gomega.Equal(4.5))
+
+			gomega.Expect(ratings[3]).To(gomega.Equal(BatchRating{Status: BatchStatusNotFound, Error: "person not found"}))
+
+			// the fetched ratings are ranked and cached for the next lookup
+			rank, err := personService.ForMarket("IL").GetRatingRank(ctx, 2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(rank.Rating).To(gomega.Equal(4.5))
+			details, err := personService.store.GetRatingDetails(ctx, 2)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(*details.Average).To(gomega.Equal(4.5))
+		})
+	})
+
+	ginkgo.Context("validate that a failed fetch is an error entry and the rest of the batch is served", func() {
+
+		ginkgo.It("do", func() {
+			personRepositoryMock.EXPECT().GetPersonsByIDs(gomock.Any(), []int64{1, 4}).Return([]Person{{ID: 1, Market: "IL"}, {ID: 4, Market: "IL"}}, nil)
+			gomega.Expect(stub.SetOptions(ordersstub.Options{ErrorRate: 1})).To(gomega.Succeed())
+
+			ratings, err := personService.GetRatingsByPersonIDs(ctx, []int64{1, 4})
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(ratings[1].Status).To(gomega.Equal(BatchStatusOK))
+			gomega.Expect(ratings[4].Status).To(gomega.Equal(BatchStatusError))
+			gomega.Expect(ratings[4].Rating).To(gomega.BeNil())
+			gomega.Expect(ratings[4].Error).NotTo(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("validate that the batch fails when the persons can't be read", func() {
+
+		ginkgo.It("do", func() {
+			personRepositoryMock.EXPECT().GetPersonsByIDs(gomock.Any(), []int64{1}).Return(nil, gorm.ErrInvalidSQL)
+
+			ratings, err := personService.GetRatingsByPersonIDs(ctx, []int64{1})
+			gomega.Expect(err).To(gomega.Equal(gorm.ErrInvalidSQL))
+			gomega.Expect(ratings).To(gomega.BeNil())
+		})
+	})
+})
@@ -0,0 +1,195 @@
+package person
+
+import (
+	"context"
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/webhooks"
+	"github.com/gtforge/gorm"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+var ErrInvalidInterval = errors.New("interval must be day, week or month")
+
+// recordRatingSnapshot - store a snapshot when the rating changed and once per market day
+func (s PersonService) recordRatingSnapshot(ctx context.Context, person Person, rating float64, orderCount int64) {
+	now := time.Now()
+	personID := person.ID
+
+	last, err := s.lastRatingSnapshots(ctx, personID)
+	if err != nil {
+		logrus.Error("error - last rating snapshots ", err)
+		return
+	}
+
+	recorded := false
+	if last.Change == nil || last.Change.Rating != rating || last.Change.OrderCount != orderCount {
+		last.Change = s.createRatingSnapshot(ctx, personID, rating, orderCount, SnapshotOnChange, now)
+		s.ratingChanged(RatingChange{PersonID: personID, Market: person.Market, Rating: rating, OrderCount: orderCount, ChangedAt: now})
+		recorded = true
+	}
+
+	location := marketLocation(person.Market)
+	if last.Daily == nil || !startOfDay(last.Daily.CreatedAt.In(location)).Equal(startOfDay(now.In(location))) {
+		last.Daily = s.createRatingSnapshot(ctx, personID, rating, orderCount, SnapshotDaily, now)
+		recorded = true
+	}
+
+	if recorded {
+		s.snapshotStore().SetLastRatingSnapshots(ctx, last)
+	}
+}
+
+// lastRatingSnapshots - the last snapshots of the person from the store, postgres is only queried on a miss
+func (s PersonService) lastRatingSnapshots(ctx context.Context, personID int64) (*LastRatingSnapshots, error) {
+	last, err := s.snapshotStore().GetLastRatingSnapshots(ctx, personID)
+	if err == nil {
+		return last, nil
+	}
+
+	last = &LastRatingSnapshots{PersonID: personID}
+	if last.Change, err = s.lastRatingSnapshot(ctx, personID, SnapshotOnChange); err != nil {
+		return nil, err
+	}
+	if last.Daily, err = s.lastRatingSnapshot(ctx, personID, SnapshotDaily); err != nil {
+		return nil, err
+	}
+	s.snapshotStore().SetLastRatingSnapshots(ctx, last)
+
+	return last, nil
+}
+
+// lastRatingSnapshot - nil when the person has no snapshot of the kind
+func (s PersonService) lastRatingSnapshot(ctx context.Context, personID int64, kind string) (*RatingSnapshot, error) {
+	snapshot, err := s.repository.GetLastRatingSnapshot(ctx, personID, kind)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil, nil
+	}
+
+	return snapshot, err
+}
+
+// snapshotStore - the snapshots belong to the person whatever service computed them, so their keys aren't scoped by
+// market
+func (s PersonService) snapshotStore() Provider {
+	return s.store.ForMarket("")
+}
+
+// ratingChanged - notify the webhook subscribers and the rating stream clients
+func (s PersonService) ratingChanged(change RatingChange) {
+	s.publish(webhooks.EventRatingChanged, change)
+	if s.stream != nil {
+		s.stream.Publish(change)
+	}
+}
+
+// createRatingSnapshot - the stored snapshot, nil when it couldn't be stored
+func (s PersonService) createRatingSnapshot(ctx context.Context, personID int64, rating float64, orderCount int64, kind string, now time.Time) *RatingSnapshot {
+	snapshot := RatingSnapshot{
+		PersonID:   personID,
+		Rating:     rating,
+		OrderCount: orderCount,
+		Kind:       kind,
+		CreatedAt:  now,
+	}
+
+	if err := s.repository.CreateRatingSnapshot(ctx, &snapshot); err != nil {
+		logrus.Error("error - unable to store rating snapshot ", err)
+		return nil
+	}
+
+	return &snapshot
+}
+
+// GetRatingHistory - the daily snapshots of the person bucketed by the days, weeks or months of its market. a person
+// unknown or outside the service market is gorm.ErrRecordNotFound
+func (s PersonService) GetRatingHistory(ctx context.Context, id int64, from, to time.Time, interval string) ([]RatingHistoryBucket, error) {
+	if interval == "" {
+		interval = IntervalDay
+	}
+	if interval != IntervalDay && interval != IntervalWeek && interval != IntervalMonth {
+		return nil, ErrInvalidInterval
+	}
+
+	person, err := s.GetPersonByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// a day has a single daily snapshot, the change snapshots would count the days with changes more than once
+	snapshots, err := s.repository.GetRatingSnapshots(ctx, id, SnapshotDaily, from, to)
+	if err != nil {
+		logrus.Error("error - rating snapshots ", err)
+		return nil, err
+	}
+
+	return bucketSnapshots(snapshots, interval, marketLocation(person.Market)), nil
+}
+
+// bucketSnapshots - group the daily snapshots, ordered by creation time, into interval buckets
+func bucketSnapshots(snapshots []RatingSnapshot, interval string, location *time.Location) []RatingHistoryBucket {
+	buckets := make([]RatingHistoryBucket, 0)
+
+	var sum float64
+	for _, snapshot := range snapshots {
+		start := bucketStart(snapshot.CreatedAt.In(location), interval)
+
+		if len(buckets) == 0 || !buckets[len(buckets)-1].From.Equal(start) {
+			sum = 0
+			buckets = append(buckets, RatingHistoryBucket{From: start, To: bucketEnd(start, interval)})
+		}
+
+		bucket := &buckets[len(buckets)-1]
+		sum += snapshot.Rating
+		bucket.Snapshots++
+		bucket.Average = sum / float64(bucket.Snapshots)
+		bucket.OrderCount = snapshot.OrderCount
+	}
+
+	return buckets
+}
+
+func bucketStart(t time.Time, interval string) time.Time {
+	day := startOfDay(t)
+
+	switch interval {
+	case IntervalWeek:
+		// weeks start on monday
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case IntervalMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	}
+
+	return day
+}
+
+func bucketEnd(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	}
+
+	return start.AddDate(0, 0, 1)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// parseHistoryTime - accepts a market local date (2006-01-02) or an RFC3339 timestamp
+func parseHistoryTime(value string, market string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, marketLocation(market)); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
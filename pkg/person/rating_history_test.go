@@ -0,0 +1,102 @@
+package person
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/gtforge/gorm"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"time"
+)
+
+var _ = ginkgo.Describe("rating history", func() {
+
+	var (
+		market    = time.FixedZone("market", 2*60*60)
+		snapshots = []RatingSnapshot{
+			{Rating: 4, OrderCount: 1, Kind: SnapshotDaily, CreatedAt: time.Date(2020, 2, 29, 10, 0, 0, 0, time.UTC)},
+			// monday in the market, still sunday in utc
+			{Rating: 3, OrderCount: 2, Kind: SnapshotDaily, CreatedAt: time.Date(2020, 3, 1, 23, 30, 0, 0, time.UTC)},
+			{Rating: 5, OrderCount: 3, Kind: SnapshotDaily, CreatedAt: time.Date(2020, 3, 3, 10, 0, 0, 0, time.UTC)},
+		}
+		local = func(year int, month time.Month, day int) time.Time {
+			return time.Date(year, month, day, 0, 0, 0, 0, market)
+		}
+	)
+
+	ginkgo.Context("validate that the snapshots are bucketed by the market days", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(bucketSnapshots(snapshots, IntervalDay, market)).To(gomega.Equal([]RatingHistoryBucket{
+				{From: local(2020, 2, 29), To: local(2020, 3, 1), Average: 4, OrderCount: 1, Snapshots: 1},
+				{From: local(2020, 3, 2), To: local(2020, 3, 3), Average: 3, OrderCount: 2, Snapshots: 1},
+				{From: local(2020, 3, 3), To: local(2020, 3, 4), Average: 5, OrderCount: 3, Snapshots: 1},
+			}))
+		})
+	})
+
+	ginkgo.Context("validate that the weeks start on monday in the market time zone", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(bucketSnapshots(snapshots, IntervalWeek, market)).To(gomega.Equal([]RatingHistoryBucket{
+				{From: local(2020, 2, 24), To: local(2020, 3, 2), Average: 4, OrderCount: 1, Snapshots: 1},
+				{From: local(2020, 3, 2), To: local(2020, 3, 9), Average: 4, OrderCount: 3, Snapshots: 2},
+			}))
+
+			buckets := bucketSnapshots(snapshots, IntervalWeek, time.UTC)
+			gomega.Expect(buckets).To(gomega.HaveLen(2))
+			gomega.Expect(buckets[0].Snapshots).To(gomega.Equal(2))
+			gomega.Expect(buckets[0].Average).To(gomega.Equal(3.5))
+		})
+	})
+
+	ginkgo.Context("validate that the months average their snapshots and keep the last order count", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(bucketSnapshots(snapshots, IntervalMonth, market)).To(gomega.Equal([]RatingHistoryBucket{
+				{From: local(2020, 2, 1), To: local(2020, 3, 1), Average: 4, OrderCount: 1, Snapshots: 1},
+				{From: local(2020, 3, 1), To: local(2020, 4, 1), Average: 4, OrderCount: 3, Snapshots: 2},
+			}))
+			gomega.Expect(bucketSnapshots(nil, IntervalMonth, market)).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("validate that the history reads the daily snapshots of a known person and refuses an unknown interval", func() {
+
+		var (
+			ctrl                 *gomock.Controller
+			personRepositoryMock *MockPersonRepository
+			personService        *PersonService
+			from, to             = time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+		)
+
+		ginkgo.BeforeEach(func() {
+			ctrl = gomock.NewController(ginkgo.GinkgoT())
+			personRepositoryMock = NewMockPersonRepository(ctrl)
+			personService = &PersonService{repository: personRepositoryMock, store: missingStore{}, cache: emptyCache{}, leaderboard: emptyLeaderboard{}}
+		})
+
+		ginkgo.AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		ginkgo.It("do", func() {
+			personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), int64(1)).Return(&Person{ID: 1, Market: "IL"}, nil).Times(2)
+			personRepositoryMock.EXPECT().GetRatingSnapshots(gomock.Any(), int64(1), SnapshotDaily, from, to).Return(snapshots, nil)
+			buckets, err := personService.GetRatingHistory(context.Background(), 1, from, to, "")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(buckets).To(gomega.HaveLen(3))
+
+			_, err = personService.GetRatingHistory(context.Background(), 1, from, to, "year")
+			gomega.Expect(err).To(gomega.Equal(ErrInvalidInterval))
+
+			// the snapshots of a person of another market aren't read
+			_, err = personService.ForMarket("UK").GetRatingHistory(context.Background(), 1, from, to, "")
+			gomega.Expect(err).To(gomega.Equal(gorm.ErrRecordNotFound))
+
+			personRepositoryMock.EXPECT().GetPersonById(gomock.Any(), int64(2)).Return(nil, gorm.ErrRecordNotFound)
+			_, err = personService.GetRatingHistory(context.Background(), 2, from, to, "")
+			gomega.Expect(err).To(gomega.Equal(gorm.ErrRecordNotFound))
+		})
+	})
+})
@@ -0,0 +1,139 @@
+package person
+
+import (
+	"errors"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"math"
+	"time"
+)
+
+const (
+	MeanStrategy      = "mean"
+	BayesianStrategy  = "bayesian"
+	TimeDecayStrategy = "time_decay"
+
+	// FlagTimeDecayRating - rolls the time decay rating out, the requests it is on for get it instead of the configured
+	// strategy, computed on every request and kept out of the cache and the leaderboard
+	FlagTimeDecayRating = "time_decay_rating"
+
+	minValidRating = 1.0
+	maxValidRating = 5.0
+)
+
+var ErrUnknownRatingStrategy = errors.New("unknown rating strategy")
+
+// RatingStrategy - turns the orders of a person into a single rating
+type RatingStrategy interface {
+	Name() string
+	Compute(orders []Order, now time.Time) float64
+}
+
+type meanRating struct {
+}
+
+func (meanRating) Name() string {
+	return MeanStrategy
+}
+
+func (meanRating) Compute(orders []Order, now time.Time) float64 {
+	orders = validOrders(orders)
+	if len(orders) == 0 {
+		return 0.0
+	}
+
+	var sum float64
+	for _, o := range orders {
+		sum += o.Rating
+	}
+
+	return sum / float64(len(orders))
+}
+
+// bayesianRating - pulls the mean towards the prior until the person has enough orders
+type bayesianRating struct {
+	prior    float64
+	minCount float64
+}
+
+func (bayesianRating) Name() string {
+	return BayesianStrategy
+}
+
+func (b bayesianRating) Compute(orders []Order, now time.Time) float64 {
+	orders = validOrders(orders)
+	if len(orders) == 0 {
+		return 0.0
+	}
+
+	var sum float64
+	for _, o := range orders {
+		sum += o.Rating
+	}
+
+	return (b.prior*b.minCount + sum) / (b.minCount + float64(len(orders)))
+}
+
+// timeDecayRating - weights every order by its age, an order halfLife old weighs half of a new one
+type timeDecayRating struct {
+	halfLife time.Duration
+}
+
+func (timeDecayRating) Name() string {
+	return TimeDecayStrategy
+}
+
+func (t timeDecayRating) Compute(orders []Order, now time.Time) float64 {
+	orders = validOrders(orders)
+	if len(orders) == 0 {
+		return 0.0
+	}
+
+	var sum, weights float64
+	for _, o := range orders {
+		age := now.Sub(o.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		weight := math.Pow(0.5, float64(age)/float64(t.halfLife))
+		sum += o.Rating * weight
+		weights += weight
+	}
+
+	if weights == 0 {
+		return 0.0
+	}
+
+	return sum / weights
+}
+
+// validOrders - drop the orders rated outside the 1-5 scale
+func validOrders(orders []Order) []Order {
+	valid := make([]Order, 0, len(orders))
+	for _, o := range orders {
+		if o.Rating >= minValidRating && o.Rating <= maxValidRating {
+			valid = append(valid, o)
+		}
+	}
+
+	return valid
+}
+
+// NewRatingStrategy - build the strategy by name, empty name means the configured default
+func NewRatingStrategy(name string) (RatingStrategy, error) {
+	if name == "" {
+		name = config.Current().Person.Rating.Strategy
+	}
+
+	switch name {
+	case MeanStrategy:
+		return meanRating{}, nil
+	case BayesianStrategy:
+		bayesian := config.Current().Person.Rating.Bayesian
+		return bayesianRating{prior: bayesian.Prior, minCount: bayesian.MinCount}, nil
+	case TimeDecayStrategy:
+		days := config.Current().Person.Rating.TimeDecay.HalfLifeDays
+		return timeDecayRating{halfLife: time.Duration(days * float64(24*time.Hour))}, nil
+	}
+
+	return nil, ErrUnknownRatingStrategy
+}
@@ -0,0 +1,98 @@
+package person
+
+import (
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"time"
+)
+
+var _ = ginkgo.Describe("rating strategies", func() {
+
+	var (
+		now      = time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+		day      = 24 * time.Hour
+		previous config.Config
+	)
+
+	ginkgo.BeforeEach(func() {
+		previous = config.Current()
+		c := config.Defaults()
+		c.Person.Rating.Bayesian = config.Bayesian{Prior: 4, MinCount: 10}
+		c.Person.Rating.TimeDecay = config.TimeDecay{HalfLifeDays: 10}
+		config.Set(c)
+	})
+
+	ginkgo.AfterEach(func() {
+		config.Set(previous)
+	})
+
+	ginkgo.Context("validate that the bayesian rating pulls the mean towards the prior until there are enough orders", func() {
+
+		ginkgo.It("do", func() {
+			strategy, err := NewRatingStrategy(BayesianStrategy)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			gomega.Expect(strategy.Compute([]Order{{Rating: 5}, {Rating: 5}}, now)).To(gomega.BeNumerically("~", 50.0/12, 0.0001))
+			gomega.Expect(strategy.Compute([]Order{{Rating: 4}}, now)).To(gomega.Equal(4.0))
+
+			many := make([]Order, 0, 990)
+			for i := 0; i < 990; i++ {
+				many = append(many, Order{Rating: 1})
+			}
+			gomega.Expect(strategy.Compute(many, now)).To(gomega.BeNumerically("~", 1.03, 0.0001))
+		})
+	})
+
+	ginkgo.Context("validate that the time decay rating weights an order half life old half of a new one", func() {
+
+		ginkgo.It("do", func() {
+			strategy, err := NewRatingStrategy(TimeDecayStrategy)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+
+			orders := []Order{{Rating: 5, CreatedAt: now}, {Rating: 2, CreatedAt: now.Add(-10 * day)}}
+			gomega.Expect(strategy.Compute(orders, now)).To(gomega.BeNumerically("~", 6.0/1.5, 0.0001))
+
+			orders = []Order{{Rating: 5, CreatedAt: now.Add(-20 * day)}, {Rating: 1, CreatedAt: now}}
+			gomega.Expect(strategy.Compute(orders, now)).To(gomega.BeNumerically("~", 2.25/1.25, 0.0001))
+
+			// an order dated after now weighs like a new one
+			orders = []Order{{Rating: 4, CreatedAt: now.Add(day)}, {Rating: 2, CreatedAt: now}}
+			gomega.Expect(strategy.Compute(orders, now)).To(gomega.Equal(3.0))
+		})
+	})
+
+	ginkgo.Context("validate that the strategies drop the orders rated outside the 1-5 scale", func() {
+
+		ginkgo.It("do", func() {
+			orders := []Order{{Rating: 0, CreatedAt: now}, {Rating: 6, CreatedAt: now}, {Rating: 3, CreatedAt: now}}
+			for _, name := range []string{MeanStrategy, BayesianStrategy, TimeDecayStrategy} {
+				strategy, err := NewRatingStrategy(name)
+				gomega.Expect(err).NotTo(gomega.HaveOccurred())
+				gomega.Expect(strategy.Name()).To(gomega.Equal(name))
+				gomega.Expect(strategy.Compute([]Order{{Rating: 0}, {Rating: 6}}, now)).To(gomega.Equal(0.0))
+			}
+
+			mean, _ := NewRatingStrategy(MeanStrategy)
+			gomega.Expect(mean.Compute(orders, now)).To(gomega.Equal(3.0))
+			bayesian, _ := NewRatingStrategy(BayesianStrategy)
+			gomega.Expect(bayesian.Compute(orders, now)).To(gomega.BeNumerically("~", 43.0/11, 0.0001))
+		})
+	})
+
+	ginkgo.Context("validate that the configured strategy is the default and an unknown one is refused", func() {
+
+		ginkgo.It("do", func() {
+			c := config.Current()
+			c.Person.Rating.Strategy = BayesianStrategy
+			config.Set(c)
+
+			strategy, err := NewRatingStrategy("")
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(strategy.Name()).To(gomega.Equal(BayesianStrategy))
+
+			_, err = NewRatingStrategy("median")
+			gomega.Expect(err).To(gomega.Equal(ErrUnknownRatingStrategy))
+		})
+	})
+})
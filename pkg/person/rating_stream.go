@@ -0,0 +1,294 @@
+package person
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/redis.v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LastEventIDHeader = "Last-Event-ID"
+
+	ratingStreamChannel     = "ratings:stream"
+	ratingStreamBufferKey   = "ratings:stream:buffer"
+	ratingStreamSequenceKey = "ratings:stream:sequence"
+
+	ratingStreamHeartbeat        = 5 * time.Second
+	ratingStreamRetryMillis      = 1000
+	ratingStreamSubscriberBuffer = 64
+)
+
+// RatingEvent - a rating change numbered by the stream, the id is the SSE event id
+type RatingEvent struct {
+	ID int64 `json:"id"`
+	RatingChange
+}
+
+type RatingStreamProvider interface {
+	Publish(change RatingChange)
+	// Since - the buffered events after lastID, oldest first
+	Since(lastID int64) ([]RatingEvent, error)
+	// Subscribe - the events published by every replica from now on, until the returned func is called
+	Subscribe() (<-chan RatingEvent, func())
+}
+
+// RatingStream - events are numbered and buffered in a redis sorted set and fanned out to the replicas with redis
+// pub/sub, each replica keeps one subscription for all its clients
+type RatingStream struct {
+	client *gettStorages.GtRedisClient
+	once   sync.Once
+	fanout
+}
+
+func NewRatingStream(client *gettStorages.GtRedisClient) *RatingStream {
+	return &RatingStream{client: client}
+}
+
+// fanout - the clients of the replica, each gets every event published from its subscription on
+type fanout struct {
+	mutex       sync.Mutex
+	subscribers map[chan RatingEvent]struct{}
+}
+
+// publishScript - number, buffer and publish the event in one step, so the replicas receive the events in the order
+// of their ids and the clients resuming from the last id they got don't skip any. the id is spliced into the encoded
+// change, ARGV[1], as the first field of the event
+var publishScript = redis.NewScript(`
+local id = redis.call('INCR', KEYS[1])
+local event = '{"id":' .. id .. ',' .. string.sub(ARGV[1], 2)
+
+redis.call('ZADD', KEYS[2], id, event)
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[2]) - 1)
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+redis.call('PUBLISH', ARGV[4], event)
+
+return id
+`)
+
+func (rs *RatingStream) Publish(change RatingChange) {
+	bytes, err := json.Marshal(change)
+	if err != nil {
+		logrus.Error("unable marshal rating event ", err)
+		return
+	}
+
+	settings := config.Current().Person.RatingsStream
+	err = publishScript.Run(rs.client, []string{ratingStreamSequenceKey, ratingStreamBufferKey},
+		string(bytes), settings.BufferSize, int64(settings.TTL/time.Second), ratingStreamChannel).Err()
+	if err != nil {
+		logrus.Error("couldn't publish rating event ", err)
+	}
+}
+
+func (rs *RatingStream) Since(lastID int64) ([]RatingEvent, error) {
+	members, err := rs.client.ZRangeByScore(ratingStreamBufferKey, redis.ZRangeBy{
+		Min: "(" + strconv.FormatInt(lastID, 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]RatingEvent, 0, len(members))
+	for _, member := range members {
+		event := RatingEvent{}
+		if err := json.Unmarshal([]byte(member), &event); err != nil {
+			logrus.Error("unable unmarshal rating event ", err)
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+func (rs *RatingStream) Subscribe() (<-chan RatingEvent, func()) {
+	rs.once.Do(func() {
+		go rs.listen()
+	})
+
+	return rs.subscribe()
+}
+
+func (f *fanout) subscribe() (<-chan RatingEvent, func()) {
+	events := make(chan RatingEvent, ratingStreamSubscriberBuffer)
+	f.mutex.Lock()
+	if f.subscribers == nil {
+		f.subscribers = map[chan RatingEvent]struct{}{}
+	}
+	f.subscribers[events] = struct{}{}
+	f.mutex.Unlock()
+
+	return events, func() {
+		f.mutex.Lock()
+		delete(f.subscribers, events)
+		f.mutex.Unlock()
+	}
+}
+
+func (rs *RatingStream) listen() {
+	for {
+		pubsub, err := rs.client.Subscribe(ratingStreamChannel)
+		if err != nil {
+			logrus.Error("couldn't subscribe to rating events ", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		for {
+			message, err := pubsub.ReceiveMessage()
+			if err != nil {
+				logrus.Error("rating events subscription failed ", err)
+				break
+			}
+
+			event := RatingEvent{}
+			if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+				logrus.Error("unable unmarshal rating event ", err)
+				continue
+			}
+			rs.broadcast(event)
+		}
+		pubsub.Close()
+		time.Sleep(time.Second)
+	}
+}
+
+// broadcast - slow clients miss live events rather than hold the others back, they resume with Last-Event-ID
+func (f *fanout) broadcast(event RatingEvent) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for subscriber := range f.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			logrus.Warn("dropped rating event ", event.ID, " for a slow stream client")
+		}
+	}
+}
+
+// GetRatingStream - rating changes as server-sent events, ?person_id=1,2 narrows them to some persons and
+// Last-Event-ID (or ?last_event_id=) resumes from the buffered events.
+// writers that can't flush get the pending events and the response ends, the EventSource then reconnects with
+// Last-Event-ID. the stream also ends before the server write timeout, so clients always reconnect cleanly
+func (h handler) GetRatingStream(w http.ResponseWriter, req *http.Request) {
+	market, err := requestMarket(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	personIDs := map[int64]bool{}
+	if stringIDs := req.URL.Query().Get("person_id"); stringIDs != "" {
+		for _, stringID := range strings.Split(stringIDs, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(stringID), 10, 64)
+			if err != nil || id < 1 {
+				http.Error(w, "person_id must be a comma separated list of ids", http.StatusBadRequest)
+				return
+			}
+			personIDs[id] = true
+		}
+	}
+
+	lastID := int64(0)
+	stringLastID := req.Header.Get(LastEventIDHeader)
+	if stringLastID == "" {
+		stringLastID = req.URL.Query().Get("last_event_id")
+	}
+	if stringLastID != "" {
+		if lastID, err = strconv.ParseInt(stringLastID, 10, 64); err != nil || lastID < 0 {
+			http.Error(w, "Last-Event-ID must be a stream event id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	matches := func(event RatingEvent) bool {
+		return event.ID > lastID && (market == "" || event.Market == market) && (len(personIDs) == 0 || personIDs[event.PersonID])
+	}
+
+	// subscribe before the replay, so the events published in between are not lost
+	events, unsubscribe := h.stream.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", ratingStreamRetryMillis)
+
+	flusher, streaming := w.(http.Flusher)
+	flush := func() {
+		if streaming {
+			flusher.Flush()
+		}
+	}
+	flush()
+
+	sent := 0
+	write := func(event RatingEvent) bool {
+		if !matches(event) {
+			return true
+		}
+		bytes, err := json.Marshal(event)
+		if err != nil {
+			logrus.Error("unable marshal rating event ", err)
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: rating\ndata: %s\n\n", event.ID, bytes); err != nil {
+			return false
+		}
+		lastID = event.ID
+		sent++
+		return true
+	}
+
+	if lastID > 0 {
+		buffered, err := h.stream.Since(lastID)
+		if err != nil {
+			logrus.Error("couldn't replay rating events ", err)
+		}
+		for _, event := range buffered {
+			if !write(event) {
+				return
+			}
+		}
+		flush()
+	}
+
+	deadline := time.NewTimer(config.Current().Person.RatingsStream.MaxDuration)
+	defer deadline.Stop()
+	heartbeat := time.NewTicker(ratingStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		if !streaming && sent > 0 {
+			return
+		}
+
+		select {
+		case <-req.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-heartbeat.C:
+			if streaming {
+				fmt.Fprint(w, ": heartbeat\n\n")
+				flush()
+			}
+		case event := <-events:
+			if !write(event) {
+				return
+			}
+			flush()
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package person
+
+import (
+	"github.com/gorilla/mux"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+type fakeRatingStream struct {
+	buffered []RatingEvent
+	events   chan RatingEvent
+}
+
+func (f *fakeRatingStream) Publish(change RatingChange) {}
+
+func (f *fakeRatingStream) Since(lastID int64) ([]RatingEvent, error) {
+	events := make([]RatingEvent, 0)
+	for _, event := range f.buffered {
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (f *fakeRatingStream) Subscribe() (<-chan RatingEvent, func()) {
+	return f.events, func() {}
+}
+
+// flushlessRecorder - hides http.Flusher like the skeleton logging middleware
+type flushlessRecorder struct {
+	http.ResponseWriter
+}
+
+var _ = ginkgo.Describe("rating stream", func() {
+
+	var (
+		stream *fakeRatingStream
+		router *mux.Router
+	)
+
+	ginkgo.BeforeEach(func() {
+		stream = &fakeRatingStream{
+			buffered: []RatingEvent{
+				{ID: 1, RatingChange: RatingChange{PersonID: 1, Market: "IL", Rating: 4}},
+				{ID: 2, RatingChange: RatingChange{PersonID: 1, Market: "IL", Rating: 4.5}},
+				{ID: 3, RatingChange: RatingChange{PersonID: 2, Market: "IL", Rating: 3}},
+				{ID: 4, RatingChange: RatingChange{PersonID: 1, Market: "RU", Rating: 5}},
+			},
+			events: make(chan RatingEvent, 10),
+		}
+		router = mux.NewRouter()
+		h := handler{stream: stream}
+		router.HandleFunc("/ratings/stream", h.GetRatingStream)
+		router.HandleFunc("/markets/{market}/ratings/stream", h.GetRatingStream)
+	})
+
+	ginkgo.Context("validate that Last-Event-ID resumes the filtered buffered events", func() {
+
+		ginkgo.It("do", func() {
+			req := httptest.NewRequest(http.MethodGet, "/markets/IL/ratings/stream?person_id=1", nil)
+			req.Header.Set(LastEventIDHeader, "1")
+			recorder := httptest.NewRecorder()
+
+			router.ServeHTTP(flushlessRecorder{recorder}, req)
+
+			body := recorder.Body.String()
+			gomega.Expect(recorder.Header().Get("Content-Type")).To(gomega.Equal("text/event-stream"))
+			gomega.Expect(body).To(gomega.ContainSubstring("id: 2\nevent: rating\n"))
+			gomega.Expect(body).NotTo(gomega.ContainSubstring("id: 3\n"))
+			gomega.Expect(body).NotTo(gomega.ContainSubstring("id: 4\n"))
+			gomega.Expect(strings.Count(body, "event: rating")).To(gomega.Equal(1))
+		})
+	})
+
+	ginkgo.Context("validate that live events are sent after the resume point", func() {
+
+		ginkgo.It("do", func() {
+			stream.buffered = nil
+			stream.events <- RatingEvent{ID: 3, RatingChange: RatingChange{PersonID: 2, Market: "IL", Rating: 3}}
+			stream.events <- RatingEvent{ID: 5, RatingChange: RatingChange{PersonID: 2, Market: "IL", Rating: 2}}
+			req := httptest.NewRequest(http.MethodGet, "/ratings/stream?last_event_id=4", nil)
+			recorder := httptest.NewRecorder()
+
+			router.ServeHTTP(flushlessRecorder{recorder}, req)
+
+			body := recorder.Body.String()
+			gomega.Expect(body).To(gomega.ContainSubstring("id: 5\n"))
+			gomega.Expect(body).NotTo(gomega.ContainSubstring("id: 3\n"))
+		})
+	})
+
+	ginkgo.Context("validate that the clients get the events published at once in the order of their ids", func() {
+
+		ginkgo.It("do", func() {
+			memory := NewMemoryRatingStream()
+			events, unsubscribe := memory.Subscribe()
+			defer unsubscribe()
+
+			wg := sync.WaitGroup{}
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					memory.Publish(RatingChange{PersonID: 1, Market: "IL", Rating: 4})
+				}()
+			}
+			wg.Wait()
+
+			for id := int64(1); id <= 50; id++ {
+				gomega.Expect((<-events).ID).To(gomega.Equal(id))
+			}
+		})
+	})
+})
@@ -0,0 +1,70 @@
+package person
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+)
+
+var ErrEmptySearchQuery = errors.New("search query must contain letters or digits")
+
+// nameNormalizer - decompose, drop the combining marks (accents, niqqud, the diaeresis of ё) and compose back
+var nameNormalizer = transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// hebrewFinalForms - the final letters are searched as their regular form
+var hebrewFinalForms = strings.NewReplacer("ך", "כ", "ם", "מ", "ן", "נ", "ף", "פ", "ץ", "צ")
+
+// latinTransliteration - a simplified phonetic romanization of the scripts of the markets' user languages.
+// hebrew vowels are not written, so hebrew names only loosely match their latin spelling
+var latinTransliteration = strings.NewReplacer(
+	"а", "a", "б", "b", "в", "v", "г", "g", "д", "d", "е", "e", "ж", "zh", "з", "z", "и", "i", "й", "y",
+	"к", "k", "л", "l", "м", "m", "н", "n", "о", "o", "п", "p", "р", "r", "с", "s", "т", "t", "у", "u",
+	"ф", "f", "х", "kh", "ц", "ts", "ч", "ch", "ш", "sh", "щ", "shch", "ъ", "", "ы", "y", "ь", "", "э", "e",
+	"ю", "yu", "я", "ya",
+	"א", "a", "ב", "b", "ג", "g", "ד", "d", "ה", "h", "ו", "v", "ז", "z", "ח", "ch", "ט", "t", "י", "y",
+	"כ", "k", "ל", "l", "מ", "m", "נ", "n", "ס", "s", "ע", "a", "פ", "p", "צ", "ts", "ק", "k", "ר", "r",
+	"ש", "sh", "ת", "t",
+)
+
+// NormalizeName - the searchable form of a name: no diacritics, case folded, hebrew final letters folded and
+// punctuation collapsed to single spaces
+func NormalizeName(name string) string {
+	normalized, _, err := transform.String(nameNormalizer, name)
+	if err != nil {
+		normalized = name
+	}
+	normalized = hebrewFinalForms.Replace(strings.ToLower(normalized))
+
+	return strings.Join(strings.FieldsFunc(normalized, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// TransliterateName - the latin spelling of a normalized name, so names typed in another script still match
+func TransliterateName(normalized string) string {
+	return latinTransliteration.Replace(normalized)
+}
+
+// BeforeSave - keep the search columns in sync with the name on every create and update
+func (p *Person) BeforeSave() error {
+	p.NormalizedName = NormalizeName(p.Name)
+	p.LatinName = TransliterateName(p.NormalizedName)
+	return nil
+}
+
+// SearchPersons - persons of the service market whose name resembles the query, most similar first
+func (s PersonService) SearchPersons(ctx context.Context, query string, transliterated bool, limit int) ([]PersonMatch, error) {
+	normalized := NormalizeName(query)
+	if normalized == "" {
+		return nil, ErrEmptySearchQuery
+	}
+	if transliterated {
+		normalized = TransliterateName(normalized)
+	}
+
+	return s.repository.SearchPersons(ctx, normalized, s.market, transliterated, limit)
+}
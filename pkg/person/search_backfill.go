@@ -0,0 +1,37 @@
+package person
+
+import (
+	"github.com/gtforge/gorm"
+)
+
+// BackfillSearchNames - fill the search columns of the persons saved before they existed, with the normalization
+// BeforeSave applies. persons are read in batches by id and the filled ones are skipped, so the job can be run again
+// after a failure. the number of filled persons is returned
+func BackfillSearchNames(db *gorm.DB, batchSize int) (int, error) {
+	filled := 0
+	lastID := int64(0)
+	for {
+		persons := make([]Person, 0, batchSize)
+		err := db.Where("id > ? AND normalized_name = '' AND name <> ''", lastID).Order("id").Limit(batchSize).Find(&persons).Error
+		if err != nil {
+			return filled, err
+		}
+		if len(persons) == 0 {
+			return filled, nil
+		}
+
+		for _, p := range persons {
+			normalized := NormalizeName(p.Name)
+			// UpdateColumns skips the hooks and the updated_at of the person
+			err := db.Model(&Person{}).Where("id = ?", p.ID).UpdateColumns(map[string]interface{}{
+				"normalized_name": normalized,
+				"latin_name":      TransliterateName(normalized),
+			}).Error
+			if err != nil {
+				return filled, err
+			}
+			lastID = p.ID
+			filled++
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package person
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("person search", func() {
+
+	ginkgo.Context("validate that names are normalized across scripts", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(NormalizeName("  José O'Brien ")).To(gomega.Equal("jose o brien"))
+			gomega.Expect(NormalizeName("Алёна")).To(gomega.Equal("алена"))
+			gomega.Expect(NormalizeName("שָׁלוֹם")).To(gomega.Equal("שלומ"))
+			gomega.Expect(TransliterateName(NormalizeName("Алёна Щербакова"))).To(gomega.Equal("alena shcherbakova"))
+		})
+	})
+
+	ginkgo.Context("validate that the search query is normalized and transliterated", func() {
+
+		var (
+			ctrl                 *gomock.Controller
+			personRepositoryMock *MockPersonRepository
+		)
+
+		ginkgo.BeforeEach(func() {
+			ctrl = gomock.NewController(ginkgo.GinkgoT())
+			personRepositoryMock = NewMockPersonRepository(ctrl)
+		})
+
+		ginkgo.AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		ginkgo.It("do", func() {
+			service := PersonService{repository: personRepositoryMock, market: "RU"}
+			personRepositoryMock.EXPECT().SearchPersons(gomock.Any(), "alena", "RU", true, 5).Return([]PersonMatch{{Person: Person{ID: 1}, Similarity: 0.8}}, nil)
+
+			matches, err := service.SearchPersons(context.Background(), "Алёна", true, 5)
+			gomega.Expect(err).To(gomega.BeNil())
+			gomega.Expect(matches).To(gomega.HaveLen(1))
+
+			_, err = service.SearchPersons(context.Background(), " !? ", true, 5)
+			gomega.Expect(err).To(gomega.Equal(ErrEmptySearchQuery))
+		})
+	})
+})
@@ -1,29 +1,90 @@
 package person
 
 import (
+	"context"
 	"encoding/json"
 	"github.com/gtforge/global_services_common_go/gett-storages"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/tracing"
+	"github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/redis.v5"
 	"strconv"
 	"time"
 )
 
 type Provider interface {
-	GetPersons() ([]Person, error)
-	CreatePersons(person *Person)
-	SetPersons([]Person)
-	GetPersonByID(id int64) (*Person, error)
-	UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
-	DeletePerson(id int64) error
+	GetPersons(ctx context.Context) ([]Person, error)
+	CreatePersons(ctx context.Context, person *Person)
+	SetPersons(ctx context.Context, persons []Person)
+	GetPersonByID(ctx context.Context, id int64) (*Person, error)
+	UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error)
+	DeletePerson(ctx context.Context, id int64) error
+	GetRatingDetails(ctx context.Context, personID int64) (*RatingDetails, error)
+	SetRatingDetails(ctx context.Context, details *RatingDetails)
+	GetRatingDetailsBatch(ctx context.Context, personIDs []int64) map[int64]*RatingDetails
+	GetLastRatingSnapshots(ctx context.Context, personID int64) (*LastRatingSnapshots, error)
+	SetLastRatingSnapshots(ctx context.Context, last *LastRatingSnapshots)
+	ForMarket(market string) Provider
 }
 
-var Instance = &PersonStore{}
+const personStoreCache = "person_store"
+
+// lastSnapshotsTTL - the cached last snapshots are rewritten on every change and day, the ttl only drops the persons
+// that aren't rated anymore
+const lastSnapshotsTTL = 48 * time.Hour
 
 type PersonStore struct {
+	client *gettStorages.GtRedisClient
+	market string
 }
 
-func (ps PersonStore) GetPersons() ([]Person, error) {
-	bytes, err := gettStorages.RedisClient.Get("persons").Bytes()
+func NewPersonStore(client *gettStorages.GtRedisClient) PersonStore {
+	return PersonStore{client: client}
+}
+
+// ForMarket - a store whose redis keys are namespaced by the market
+func (ps PersonStore) ForMarket(market string) Provider {
+	return PersonStore{client: ps.client, market: market}
+}
+
+func (ps PersonStore) key(key string) string {
+	return marketKey(ps.market, key)
+}
+
+// span - the span of a store operation, the redis commands it runs are its children. redis.v5 doesn't honour the
+// context, so every operation returns early once the context of its caller is done
+func (ps PersonStore) span(operation string) (opentracing.Span, func()) {
+	span, finish := tracing.StartSpan("person_store." + operation)
+	otext.DBType.Set(span, "redis")
+	span.SetTag("market", ps.market)
+	return span, finish
+}
+
+// observeCache - count a lookup of the redis store and tag its span, redis.Nil is a miss and any other error a failure
+func observeCache(span opentracing.Span, entry string, err error) {
+	result := metrics.CacheHit
+	switch {
+	case err == redis.Nil:
+		result = metrics.CacheMiss
+	case err != nil:
+		result = metrics.CacheError
+		tracing.Error(span, err)
+	}
+	metrics.CacheRequests.Inc(personStoreCache, entry, result)
+	span.SetTag("cache.result", result)
+}
+
+func (ps PersonStore) GetPersons(ctx context.Context) ([]Person, error) {
+	span, finish := ps.span("get_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return []Person{}, err
+	}
+	bytes, err := ps.client.Get(ps.key("persons")).Bytes()
+	observeCache(span, "persons", err)
 
 	if err != nil {
 		logrus.Error("couldn't get redis get persons ", err)
@@ -33,14 +94,21 @@ func (ps PersonStore) GetPersons() ([]Person, error) {
 	err = json.Unmarshal(bytes, &persons)
 	if err != nil {
 		logrus.Error("unable unmarshal get persons", err)
+		tracing.Error(span, err)
 		return []Person{}, err
 	}
 
 	return persons, nil
 }
 
-func (ps PersonStore) GetPersonByID(personId int64) (persons *Person,err error) {
-	bytes, err := gettStorages.RedisClient.Get("person:" + strconv.FormatInt(personId, 10)).Bytes()
+func (ps PersonStore) GetPersonByID(ctx context.Context, personId int64) (persons *Person,err error) {
+	span, finish := ps.span("get_person_by_id")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bytes, err := ps.client.Get(ps.key("person:" + strconv.FormatInt(personId, 10))).Bytes()
+	observeCache(span, "person", err)
 
 	if err != nil {
 		logrus.Error("couldn't get redis get person by ID ", err)
@@ -50,44 +118,63 @@ func (ps PersonStore) GetPersonByID(personId int64) (persons *Person,err error)
 	err = json.Unmarshal(bytes, &persons)
 	if err != nil {
 		logrus.Error("unable unmarshal get person by ID", err)
+		tracing.Error(span, err)
 		return persons, err
 	}
 
 	return persons, nil
 }
 
-func (ps PersonStore) SetPersons(persons []Person) {
+func (ps PersonStore) SetPersons(ctx context.Context, persons []Person) {
+	span, finish := ps.span("set_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
 	bytes, err := json.Marshal(persons)
 	if err != nil {
 		logrus.Error("unable marshal get persons", err)
 	}
-	pipe := gettStorages.RedisClient.Pipeline()
-	pipe.Set("persons", bytes, 25*time.Hour)
+	pipe := ps.client.Pipeline()
+	pipe.Set(ps.key("persons"), bytes, config.Current().Person.Store.PersonsTTL)
 
 	_, err = pipe.Exec()
 	if err != nil {
 		logrus.Error("redis sucks!!", err)
+		tracing.Error(span, err)
 	}
 }
 
-func (ps PersonStore) CreatePersons(person *Person) {
+func (ps PersonStore) CreatePersons(ctx context.Context, person *Person) {
+	span, finish := ps.span("create_persons")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
 	bytes, err := json.Marshal(person)
 	if err != nil {
 		logrus.Error("unable marshal get persons", err)
 	}
-	pipe := gettStorages.RedisClient.Pipeline()
-	pipe.Set("person:"+strconv.FormatInt(person.ID, 10), bytes, 25*time.Hour)
+	pipe := ps.client.Pipeline()
+	pipe.Set(ps.key("person:"+strconv.FormatInt(person.ID, 10)), bytes, config.Current().Person.Store.PersonsTTL)
 
 	_, err = pipe.Exec()
 	if err != nil {
 		logrus.Error("redis sucks!!", err)
+		tracing.Error(span, err)
 	}
 }
 
-func (ps PersonStore) UpdatePerson(id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
-	person, err := ps.GetPersonByID(id)
+func (ps PersonStore) UpdatePerson(ctx context.Context, id int64, createPersonRequest *CreatePersonRequest) (*Person, error) {
+	span, finish := ps.span("update_person")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	person, err := ps.GetPersonByID(ctx, id)
 	if err != nil {
 		logrus.Error("unable marshal get persons ", err)
+		tracing.Error(span, err)
 	}
 
 	person.Name = createPersonRequest.Name
@@ -100,26 +187,172 @@ func (ps PersonStore) UpdatePerson(id int64, createPersonRequest *CreatePersonRe
 		logrus.Error("unable marshal get persons", err)
 	}
 
-	pipe := gettStorages.RedisClient.Pipeline()
-	pipe.Set("person:"+strconv.FormatInt(id, 10), bytes, 25*time.Hour)
+	pipe := ps.client.Pipeline()
+	pipe.Set(ps.key("person:"+strconv.FormatInt(id, 10)), bytes, config.Current().Person.Store.PersonsTTL)
 
 	_, err = pipe.Exec()
 	if err != nil {
 		logrus.Error("redis sucks!!", err)
+		tracing.Error(span, err)
 	}
 	return person, nil
 }
 
-func (ps PersonStore) DeletePerson(id int64) error {
-	if err := gettStorages.RedisClient.Del("person:" + strconv.FormatInt(id, 10)); err != nil {
+func (ps PersonStore) DeletePerson(ctx context.Context, id int64) error {
+	span, finish := ps.span("delete_person")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := ps.client.Del(ps.key("person:" + strconv.FormatInt(id, 10))); err != nil {
 		logrus.Error("can't delete person from redis ", err)
+		tracing.Error(span, err.Err())
 		return err.Err()
 	}
 
 	//remove persons
-	if err := gettStorages.RedisClient.Del("persons"); err != nil {
+	if err := ps.client.Del(ps.key("persons")); err != nil {
 		logrus.Error("can't delete persons from redis ", err)
+		tracing.Error(span, err.Err())
 		return err.Err()
 	}
-	return ps.DeletePerson(id)
+	return ps.DeletePerson(ctx, id)
+}
+
+func (ps PersonStore) GetRatingDetails(ctx context.Context, personID int64) (*RatingDetails, error) {
+	span, finish := ps.span("get_rating_details")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bytes, err := ps.client.Get(ps.key("rating_details:" + strconv.FormatInt(personID, 10))).Bytes()
+	observeCache(span, "rating_details", err)
+
+	if err != nil {
+		logrus.Debug("couldn't get redis rating details ", err)
+		return nil, err
+	}
+
+	details := &RatingDetails{}
+	err = json.Unmarshal(bytes, details)
+	if err != nil {
+		logrus.Error("unable unmarshal rating details", err)
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return details, nil
+}
+
+func (ps PersonStore) SetRatingDetails(ctx context.Context, details *RatingDetails) {
+	span, finish := ps.span("set_rating_details")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+	bytes, err := json.Marshal(details)
+	if err != nil {
+		logrus.Error("unable marshal rating details", err)
+		tracing.Error(span, err)
+		return
+	}
+
+	ttl := config.Current().Person.Rating.CacheTTL
+	err = ps.client.Set(ps.key("rating_details:"+strconv.FormatInt(details.PersonID, 10)), bytes, ttl).Err()
+	if err != nil {
+		logrus.Error("redis sucks!!", err)
+		tracing.Error(span, err)
+	}
+}
+
+// GetRatingDetailsBatch - the cached rating details of the given persons, misses are left out of the map
+func (ps PersonStore) GetRatingDetailsBatch(ctx context.Context, personIDs []int64) map[int64]*RatingDetails {
+	span, finish := ps.span("get_rating_details_batch")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return map[int64]*RatingDetails{}
+	}
+	result := make(map[int64]*RatingDetails)
+	if len(personIDs) == 0 {
+		return result
+	}
+
+	keys := make([]string, 0, len(personIDs))
+	for _, id := range personIDs {
+		keys = append(keys, ps.key("rating_details:"+strconv.FormatInt(id, 10)))
+	}
+
+	values, err := ps.client.MGet(keys...).Result()
+	if err != nil {
+		logrus.Error("couldn't get redis rating details batch ", err)
+		tracing.Error(span, err)
+		metrics.CacheRequests.Add(float64(len(personIDs)), personStoreCache, "rating_details", metrics.CacheError)
+		return result
+	}
+	defer func() {
+		span.SetTag("cache.hits", len(result))
+		metrics.CacheRequests.Add(float64(len(result)), personStoreCache, "rating_details", metrics.CacheHit)
+		metrics.CacheRequests.Add(float64(len(personIDs)-len(result)), personStoreCache, "rating_details", metrics.CacheMiss)
+	}()
+
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		details := &RatingDetails{}
+		if err := json.Unmarshal([]byte(str), details); err != nil {
+			logrus.Error("unable unmarshal rating details", err)
+			continue
+		}
+		result[personIDs[i]] = details
+	}
+
+	return result
+}
+
+// GetLastRatingSnapshots - the cached last snapshots of the person, redis.Nil when they aren't cached
+func (ps PersonStore) GetLastRatingSnapshots(ctx context.Context, personID int64) (*LastRatingSnapshots, error) {
+	span, finish := ps.span("get_last_rating_snapshots")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	bytes, err := ps.client.Get(ps.key("rating_snapshots:" + strconv.FormatInt(personID, 10))).Bytes()
+	observeCache(span, "rating_snapshots", err)
+
+	if err != nil {
+		logrus.Debug("couldn't get redis last rating snapshots ", err)
+		return nil, err
+	}
+
+	last := &LastRatingSnapshots{}
+	err = json.Unmarshal(bytes, last)
+	if err != nil {
+		logrus.Error("unable unmarshal last rating snapshots", err)
+		tracing.Error(span, err)
+		return nil, err
+	}
+
+	return last, nil
+}
+
+func (ps PersonStore) SetLastRatingSnapshots(ctx context.Context, last *LastRatingSnapshots) {
+	span, finish := ps.span("set_last_rating_snapshots")
+	defer finish()
+	if err := ctx.Err(); err != nil {
+		return
+	}
+	bytes, err := json.Marshal(last)
+	if err != nil {
+		logrus.Error("unable marshal last rating snapshots", err)
+		tracing.Error(span, err)
+		return
+	}
+
+	err = ps.client.Set(ps.key("rating_snapshots:"+strconv.FormatInt(last.PersonID, 10)), bytes, lastSnapshotsTTL).Err()
+	if err != nil {
+		logrus.Error("unable to cache the last rating snapshots ", err)
+		tracing.Error(span, err)
+	}
 }
@@ -0,0 +1,170 @@
+package ratelimit
+
+import (
+	"github.com/gtforge/global_services_common_go/gett-storages"
+	"gopkg.in/redis.v5"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Result - the outcome of taking tokens from a bucket
+type Result struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// RetryAfter - until the bucket holds enough tokens for the rejected request
+	RetryAfter time.Duration
+	// Reset - until the bucket is full again
+	Reset time.Duration
+}
+
+type Bucket interface {
+	Take(key string, cost int64, limit Limit, now time.Time) (Result, error)
+}
+
+// Limit - a bucket of Capacity tokens refilled by RefillPerSecond tokens every second
+type Limit struct {
+	Capacity        int64
+	RefillPerSecond float64
+}
+
+// untilTokens - how long the refill takes to bring the bucket from tokens to wanted
+func (l Limit) untilTokens(tokens, wanted float64) time.Duration {
+	if tokens >= wanted || l.RefillPerSecond <= 0 {
+		return 0
+	}
+
+	return time.Duration((wanted - tokens) / l.RefillPerSecond * float64(time.Second))
+}
+
+// result - the headers values of a bucket left with tokens
+func (l Limit) result(allowed bool, tokens float64, cost int64) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     l.Capacity,
+		Remaining: int64(tokens),
+		Reset:     l.untilTokens(tokens, float64(l.Capacity)),
+	}
+	if !allowed {
+		result.RetryAfter = l.untilTokens(tokens, float64(cost))
+	}
+
+	return result
+}
+
+// takeScript - refill the bucket for the time passed since its last update and take the cost when it is covered.
+// the bucket expires once it would have been refilled, so idle clients don't keep keys around
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) / 1000 * rate)
+local allowed = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisBucket - the buckets are shared by all the replicas of the service
+type RedisBucket struct {
+	client *gettStorages.GtRedisClient
+}
+
+func NewRedisBucket(client *gettStorages.GtRedisClient) RedisBucket {
+	return RedisBucket{client: client}
+}
+
+func (b RedisBucket) Take(key string, cost int64, limit Limit, now time.Time) (Result, error) {
+	reply, err := takeScript.Run(b.client, []string{key},
+		limit.Capacity, limit.RefillPerSecond, now.UnixNano()/int64(time.Millisecond), cost).Result()
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Result{}, ErrUnexpectedReply
+	}
+	allowed, _ := values[0].(int64)
+	tokensValue, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensValue, 64)
+	if err != nil {
+		return Result{}, ErrUnexpectedReply
+	}
+
+	return limit.result(allowed == 1, tokens, cost), nil
+}
+
+// MemoryBucket - the takeScript run by the process, the buckets are per replica
+type MemoryBucket struct {
+	mu      sync.Mutex
+	buckets map[string]memoryTokens
+	sweptAt time.Time
+}
+
+type memoryTokens struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time
+}
+
+func NewMemoryBucket() *MemoryBucket {
+	return &MemoryBucket{buckets: map[string]memoryTokens{}}
+}
+
+func (b *MemoryBucket) Take(key string, cost int64, limit Limit, now time.Time) (Result, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket, ok := b.buckets[key]
+	if !ok || !now.Before(bucket.expiresAt) {
+		bucket = memoryTokens{tokens: float64(limit.Capacity), updatedAt: now}
+	}
+
+	tokens := bucket.tokens
+	if elapsed := now.Sub(bucket.updatedAt); elapsed > 0 {
+		tokens += elapsed.Seconds() * limit.RefillPerSecond
+	}
+	if tokens > float64(limit.Capacity) {
+		tokens = float64(limit.Capacity)
+	}
+	allowed := tokens >= float64(cost)
+	if allowed {
+		tokens -= float64(cost)
+	}
+
+	b.sweep(now)
+	b.buckets[key] = memoryTokens{tokens: tokens, updatedAt: now, expiresAt: now.Add(limit.untilTokens(0, float64(limit.Capacity)) + time.Second)}
+
+	return limit.result(allowed, tokens, cost), nil
+}
+
+// sweep - drop the buckets refilled since their last take once a minute, like the expiry of the redis keys
+func (b *MemoryBucket) sweep(now time.Time) {
+	if now.Sub(b.sweptAt) < time.Minute {
+		return
+	}
+	b.sweptAt = now
+	for key, bucket := range b.buckets {
+		if !now.Before(bucket.expiresAt) {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+// UnaryInterceptor - take the method cost from the bucket of the caller, calls over the limit get ResourceExhausted
+// with the retry-after metadata. the costs are matched against the full method name, e.g. PersonService/GetRatings.
+// the interceptor must run after the authentication one, which puts the caller on the context
+func (l *Limiter) UnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	p := l.policy.Load().(policy)
+	if !p.enabled {
+		return handler(ctx, req)
+	}
+
+	address := ""
+	if caller, ok := peer.FromContext(ctx); ok {
+		address = caller.Addr.String()
+	}
+	cost := p.cost(info.FullMethod)
+	result, err := l.bucket.Take(keyPrefix+callerKey(ctx, address), cost, p.limit, l.now())
+	if err != nil {
+		logrus.Error("rate limiter failed, serving the call ", err)
+		return handler(ctx, req)
+	}
+
+	if !result.Allowed {
+		auth.Logger(ctx).WithFields(logrus.Fields{"method": info.FullMethod, "cost": cost}).Warn("rate limited call")
+		grpc.SetHeader(ctx, metadata.Pairs(RetryAfterHeader, strconv.FormatInt(seconds(result.RetryAfter), 10)))
+		return nil, status.Error(codes.ResourceExhausted, ErrRateLimited.Error())
+	}
+
+	return handler(ctx, req)
+}
@@ -0,0 +1,163 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	LimitHeader      = "RateLimit-Limit"
+	RemainingHeader  = "RateLimit-Remaining"
+	ResetHeader      = "RateLimit-Reset"
+	RetryAfterHeader = "Retry-After"
+
+	keyPrefix = "rate_limit:"
+)
+
+var (
+	ErrRateLimited     = errors.New("rate limit exceeded, retry later")
+	ErrUnexpectedReply = errors.New("unexpected rate limit reply from redis")
+)
+
+type Limiter struct {
+	// policy - the current policy, replaced as a whole when the settings are reloaded
+	policy atomic.Value
+	bucket Bucket
+	now    func() time.Time
+	render *render.Render
+}
+
+type policy struct {
+	enabled     bool
+	limit       Limit
+	defaultCost int64
+	// costs - the cost of the routes whose path template ends with the key, e.g. /ratings/channels
+	costs map[string]int64
+}
+
+func NewLimiter(enabled bool, limit Limit, defaultCost int64, costs map[string]int64, bucket Bucket) *Limiter {
+	l := &Limiter{
+		bucket: bucket,
+		now:    time.Now,
+		render: render.New(),
+	}
+	l.Update(enabled, limit, defaultCost, costs)
+	return l
+}
+
+// NewLimiterFromConfig - person.rate_limit holds the bucket size and refill rate and the route costs
+func NewLimiterFromConfig(settings config.Config, bucket Bucket) *Limiter {
+	l := &Limiter{
+		bucket: bucket,
+		now:    time.Now,
+		render: render.New(),
+	}
+	l.Reload(settings)
+	return l
+}
+
+// Update - replace the policy of the running limiter, the requests in flight keep the one they started with
+func (l *Limiter) Update(enabled bool, limit Limit, defaultCost int64, costs map[string]int64) {
+	l.policy.Store(policy{enabled: enabled, limit: limit, defaultCost: defaultCost, costs: costs})
+}
+
+// Reload - apply person.rate_limit of reloaded settings
+func (l *Limiter) Reload(settings config.Config) {
+	rateLimit := settings.Person.RateLimit
+	limit := Limit{Capacity: rateLimit.Capacity, RefillPerSecond: rateLimit.RefillPerSecond}
+
+	l.Update(rateLimit.Enabled, limit, rateLimit.DefaultCost, rateLimit.Costs)
+}
+
+// Middleware - take the route cost from the bucket of the caller, requests over the limit get a 429.
+// redis failures let the request through, the limiter protects the service but must not take it down
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		p := l.policy.Load().(policy)
+		if !p.enabled {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		cost := p.routeCost(req)
+		result, err := l.bucket.Take(keyPrefix+clientKey(req), cost, p.limit, l.now())
+		if err != nil {
+			logrus.Error("rate limiter failed, serving the request ", err)
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		w.Header().Set(LimitHeader, strconv.FormatInt(result.Limit, 10))
+		w.Header().Set(RemainingHeader, strconv.FormatInt(result.Remaining, 10))
+		w.Header().Set(ResetHeader, strconv.FormatInt(seconds(result.Reset), 10))
+
+		if !result.Allowed {
+			auth.Logger(req.Context()).WithFields(logrus.Fields{"path": req.URL.Path, "cost": cost}).Warn("rate limited request")
+			w.Header().Set(RetryAfterHeader, strconv.FormatInt(seconds(result.RetryAfter), 10))
+			l.render.JSON(w, http.StatusTooManyRequests, skeleton.NewAPIError(http.StatusText(http.StatusTooManyRequests), ErrRateLimited))
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (p policy) routeCost(req *http.Request) int64 {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return p.defaultCost
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return p.defaultCost
+	}
+
+	return p.cost(template)
+}
+
+// cost - the cost of the longest key the route template or the gRPC method ends with, so /ratings/channels is not
+// priced as /channels
+func (p policy) cost(name string) int64 {
+	cost, matched := p.defaultCost, ""
+	for suffix, routeCost := range p.costs {
+		if strings.HasSuffix(name, suffix) && len(suffix) > len(matched) {
+			cost, matched = routeCost, suffix
+		}
+	}
+
+	return cost
+}
+
+// clientKey - authenticated callers are limited by identity, anonymous ones by their address
+func clientKey(req *http.Request) string {
+	return callerKey(req.Context(), req.RemoteAddr)
+}
+
+func callerKey(ctx context.Context, address string) string {
+	if identity, ok := auth.FromContext(ctx); ok && identity.Method != auth.MethodNone {
+		return "client:" + identity.Subject
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	return "ip:" + host
+}
+
+func seconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "rate limit test")
+}
+
+var _ = ginkgo.Describe("rate limiter", func() {
+
+	var (
+		now     time.Time
+		limiter *Limiter
+		router  *mux.Router
+	)
+
+	serve := func(path, subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "10.0.0.1:5555"
+		if subject != "" {
+			req = req.WithContext(auth.WithIdentity(req.Context(), auth.Identity{Subject: subject, Method: auth.MethodJWT}))
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	ginkgo.BeforeEach(func() {
+		now = time.Unix(1600000000, 0)
+		limiter = NewLimiter(true, Limit{Capacity: 10, RefillPerSecond: 1}, 1, map[string]int64{"/ratings/channels": 4}, NewMemoryBucket())
+		limiter.now = func() time.Time { return now }
+
+		router = mux.NewRouter()
+		router.Use(limiter.Middleware)
+		ok := func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusOK) }
+		router.HandleFunc("/api/v1/persons", ok)
+		router.HandleFunc("/api/v1/ratings/channels", ok)
+	})
+
+	ginkgo.Context("validate that the rate limit headers are set", func() {
+
+		ginkgo.It("do", func() {
+			recorder := serve("/api/v1/persons", "orders")
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(recorder.Header().Get(LimitHeader)).To(gomega.Equal("10"))
+			gomega.Expect(recorder.Header().Get(RemainingHeader)).To(gomega.Equal("9"))
+			gomega.Expect(recorder.Header().Get(ResetHeader)).To(gomega.Equal("1"))
+		})
+	})
+
+	ginkgo.Context("validate that expensive routes exhaust the bucket with a 429", func() {
+
+		ginkgo.It("do", func() {
+			gomega.Expect(serve("/api/v1/ratings/channels", "orders").Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(serve("/api/v1/ratings/channels", "orders").Code).To(gomega.Equal(http.StatusOK))
+			recorder := serve("/api/v1/ratings/channels", "orders")
+			gomega.Expect(recorder.Code).To(gomega.Equal(http.StatusTooManyRequests))
+			gomega.Expect(recorder.Header().Get(RetryAfterHeader)).To(gomega.Equal("2"))
+			gomega.Expect(serve("/api/v1/persons", "orders").Code).To(gomega.Equal(http.StatusOK))
+		})
+	})
+
+	ginkgo.Context("validate that the bucket refills and clients are limited separately", func() {
+
+		ginkgo.It("do", func() {
+			for i := 0; i < 10; i++ {
+				serve("/api/v1/persons", "orders")
+			}
+			gomega.Expect(serve("/api/v1/persons", "orders").Code).To(gomega.Equal(http.StatusTooManyRequests))
+			gomega.Expect(serve("/api/v1/persons", "backoffice").Code).To(gomega.Equal(http.StatusOK))
+			gomega.Expect(serve("/api/v1/persons", "").Code).To(gomega.Equal(http.StatusOK))
+
+			now = now.Add(time.Second)
+			gomega.Expect(serve("/api/v1/persons", "orders").Code).To(gomega.Equal(http.StatusOK))
+		})
+	})
+
+	ginkgo.Context("validate that reloaded limits apply to the following requests", func() {
+
+		ginkgo.It("do", func() {
+			settings := config.Defaults()
+			settings.Person.RateLimit = config.RateLimit{Enabled: true, Capacity: 20, RefillPerSecond: 1, DefaultCost: 1, Costs: map[string]int64{}}
+			limiter.Reload(settings)
+
+			recorder := serve("/api/v1/ratings/channels", "orders")
+			gomega.Expect(recorder.Header().Get(LimitHeader)).To(gomega.Equal("20"))
+			gomega.Expect(recorder.Header().Get(RemainingHeader)).To(gomega.Equal("19"))
+
+			settings.Person.RateLimit.Enabled = false
+			limiter.Reload(settings)
+			gomega.Expect(serve("/api/v1/persons", "orders").Header().Get(LimitHeader)).To(gomega.BeEmpty())
+		})
+	})
+
+	ginkgo.Context("validate that the gRPC calls are priced by method and refused with ResourceExhausted", func() {
+
+		ginkgo.It("do", func() {
+			limiter.Update(true, Limit{Capacity: 10, RefillPerSecond: 1}, 1, map[string]int64{"PersonService/GetRatings": 6})
+			ctx := auth.WithIdentity(context.Background(), auth.Identity{Subject: "orders", Method: auth.MethodJWT})
+			call := func(method string) error {
+				_, err := limiter.UnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method},
+					func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+				return err
+			}
+
+			gomega.Expect(call("/person.v1.PersonService/GetRatings")).To(gomega.Succeed())
+			gomega.Expect(status.Code(call("/person.v1.PersonService/GetRatings"))).To(gomega.Equal(codes.ResourceExhausted))
+			gomega.Expect(call("/person.v1.PersonService/GetPerson")).To(gomega.Succeed())
+
+			// the http requests of the caller share its bucket
+			gomega.Expect(serve("/api/v1/persons", "orders").Header().Get(RemainingHeader)).To(gomega.Equal("2"))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package statuswriter
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Writer - records the status and the length of the response written through it
+type Writer struct {
+	http.ResponseWriter
+	status int
+	length int
+}
+
+// flushingWriter - a writer with the http.Flusher of the writer it wraps, so the streaming handlers keep flushing
+type flushingWriter struct {
+	http.ResponseWriter
+	http.Flusher
+}
+
+// New - the recording writer of w, and the writer to serve the handler with, which flushes when w does
+func New(w http.ResponseWriter) (*Writer, http.ResponseWriter) {
+	recorder := &Writer{ResponseWriter: w}
+
+	return recorder, WithFlusher(recorder, w)
+}
+
+// WithFlusher - w with the http.Flusher of the writer it wraps, for the wrappers that only record the response.
+// w is returned as is when it flushes already or the wrapped writer doesn't
+func WithFlusher(w http.ResponseWriter, wrapped http.ResponseWriter) http.ResponseWriter {
+	if _, ok := w.(http.Flusher); ok {
+		return w
+	}
+	flusher, ok := wrapped.(http.Flusher)
+	if !ok {
+		return w
+	}
+
+	return flushingWriter{ResponseWriter: w, Flusher: flusher}
+}
+
+// Unwrap - the innermost writer reached through the embedded ResponseWriter fields of w, e.g. the connection writer
+// under the status writer of the skeleton app, which records the response but hides the http.Flusher
+func Unwrap(w http.ResponseWriter) http.ResponseWriter {
+	for {
+		v := reflect.ValueOf(w)
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return w
+		}
+		field := v.FieldByName("ResponseWriter")
+		if !field.IsValid() || !field.CanInterface() {
+			return w
+		}
+		inner, ok := field.Interface().(http.ResponseWriter)
+		if !ok || inner == nil {
+			return w
+		}
+		w = inner
+	}
+}
+
+// Middleware - serve the handlers with the http.Flusher of the connection writer when a wrapper above the router hides
+// it, so the streaming handlers keep flushing
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(WithFlusher(w, Unwrap(w)), req)
+	})
+}
+
+func (w *Writer) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *Writer) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.length += n
+	return n, err
+}
+
+// Status - the status sent to the client, 200 when the handler wrote nothing
+func (w *Writer) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+
+	return w.status
+}
+
+// Length - the bytes of the body written
+func (w *Writer) Length() int {
+	return w.length
+}
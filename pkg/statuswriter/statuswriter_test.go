@@ -0,0 +1,68 @@
+package statuswriter
+
+import (
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusWriter(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "status writer test")
+}
+
+// plainWriter - a writer that can't flush
+type plainWriter struct {
+	http.ResponseWriter
+}
+
+var _ = ginkgo.Describe("status writer", func() {
+
+	ginkgo.Context("validate that the status and the length of the response are recorded", func() {
+
+		ginkgo.It("do", func() {
+			recorder, writer := New(httptest.NewRecorder())
+			gomega.Expect(recorder.Status()).To(gomega.Equal(http.StatusOK))
+
+			writer.WriteHeader(http.StatusNotFound)
+			writer.WriteHeader(http.StatusInternalServerError)
+			writer.Write([]byte("not found"))
+
+			gomega.Expect(recorder.Status()).To(gomega.Equal(http.StatusNotFound))
+			gomega.Expect(recorder.Length()).To(gomega.Equal(9))
+		})
+	})
+
+	ginkgo.Context("validate that the writer flushes only when the wrapped one does", func() {
+
+		ginkgo.It("do", func() {
+			flushed := httptest.NewRecorder()
+			_, writer := New(flushed)
+			writer.(http.Flusher).Flush()
+			gomega.Expect(flushed.Flushed).To(gomega.BeTrue())
+
+			_, writer = New(plainWriter{httptest.NewRecorder()})
+			_, flushes := writer.(http.Flusher)
+			gomega.Expect(flushes).To(gomega.BeFalse())
+
+			gomega.Expect(WithFlusher(plainWriter{flushed}, flushed)).To(gomega.BeAssignableToTypeOf(flushingWriter{}))
+		})
+	})
+
+	ginkgo.Context("validate that the middleware restores the flusher a wrapper above it hides", func() {
+
+		ginkgo.It("do", func() {
+			flushed := httptest.NewRecorder()
+			gomega.Expect(Unwrap(&plainWriter{plainWriter{flushed}})).To(gomega.Equal(flushed))
+			gomega.Expect(Unwrap(flushed)).To(gomega.Equal(flushed))
+
+			var flushes bool
+			Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, flushes = w.(http.Flusher)
+			})).ServeHTTP(&plainWriter{flushed}, httptest.NewRequest(http.MethodGet, "/", nil))
+			gomega.Expect(flushes).To(gomega.BeTrue())
+		})
+	})
+})
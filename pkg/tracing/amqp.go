@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	"github.com/sirupsen/logrus"
+)
+
+// AMQPHeaders - the headers table of an amqp message as an opentracing carrier
+type AMQPHeaders map[string]interface{}
+
+func (h AMQPHeaders) Set(key, value string) {
+	h[key] = value
+}
+
+func (h AMQPHeaders) ForeachKey(handler func(key, value string) error) error {
+	for key, value := range h {
+		var stringValue string
+		switch v := value.(type) {
+		case string:
+			stringValue = v
+		case []byte:
+			stringValue = string(v)
+		default:
+			continue
+		}
+		if err := handler(key, stringValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InjectAMQP - the headers of a published message carrying the trace context of the goroutine span
+func InjectAMQP(headers map[string]interface{}) map[string]interface{} {
+	if headers == nil {
+		headers = map[string]interface{}{}
+	}
+	span := Current()
+	if err := span.Tracer().Inject(span.Context(), opentracing.TextMap, AMQPHeaders(headers)); err != nil {
+		logrus.Debug("unable to inject the trace context ", err)
+	}
+
+	return headers
+}
+
+// StartConsumerSpan - the span of a consumed message, continuing the trace of the publisher headers. like StartSpan it
+// is the goroutine span until it is finished
+func StartConsumerSpan(routingKey string, headers map[string]interface{}) (opentracing.Span, func()) {
+	parent, err := opentracing.GlobalTracer().Extract(opentracing.TextMap, AMQPHeaders(headers))
+	if err != nil && err != opentracing.ErrSpanContextNotFound {
+		logrus.Debug("unable to extract the trace context ", err)
+	}
+
+	span, finish := start("amqp.consume "+routingKey, parent, otext.SpanKindConsumer)
+	otext.Component.Set(span, "amqp")
+	otext.MessageBusDestination.Set(span, routingKey)
+
+	return span, finish
+}
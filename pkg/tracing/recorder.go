@@ -0,0 +1,330 @@
+package tracing
+
+import (
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/log"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	traceIDKey    = "trace-id"
+	spanIDKey     = "span-id"
+	baggagePrefix = "baggage-"
+)
+
+// RecordedSpanContext - the ids of a recorded span, propagated as trace-id and span-id headers
+type RecordedSpanContext struct {
+	TraceID uint64
+	SpanID  uint64
+	Baggage map[string]string
+}
+
+func (c RecordedSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.Baggage {
+		if !handler(k, v) {
+			return
+		}
+	}
+}
+
+func (c RecordedSpanContext) withBaggage(key, value string) RecordedSpanContext {
+	baggage := make(map[string]string, len(c.Baggage)+1)
+	for k, v := range c.Baggage {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	c.Baggage = baggage
+	return c
+}
+
+// Recorder - a tracer keeping the finished spans in memory, the exporter of the tests and of the log exporter
+type Recorder struct {
+	lastID   uint64
+	onFinish func(span *RecordedSpan)
+
+	mu       sync.Mutex
+	finished []*RecordedSpan
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// OnFinish - called with every finished span
+func (r *Recorder) OnFinish(onFinish func(span *RecordedSpan)) *Recorder {
+	r.onFinish = onFinish
+	return r
+}
+
+// FinishedSpans - the finished spans in their finish order
+func (r *Recorder) FinishedSpans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*RecordedSpan{}, r.finished...)
+}
+
+// FinishedSpan - the last finished span of the operation, nil when there is none
+func (r *Recorder) FinishedSpan(operation string) *RecordedSpan {
+	spans := r.FinishedSpans()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Operation() == operation {
+			return spans[i]
+		}
+	}
+
+	return nil
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = nil
+}
+
+func (r *Recorder) nextID() uint64 {
+	return atomic.AddUint64(&r.lastID, 1)
+}
+
+func (r *Recorder) StartSpan(operationName string, opts ...opentracing.StartSpanOption) opentracing.Span {
+	options := opentracing.StartSpanOptions{}
+	for _, opt := range opts {
+		opt.Apply(&options)
+	}
+	if options.StartTime.IsZero() {
+		options.StartTime = time.Now()
+	}
+
+	span := &RecordedSpan{
+		tracer:    r,
+		operation: operationName,
+		tags:      map[string]interface{}{},
+		start:     options.StartTime,
+	}
+	for _, reference := range options.References {
+		if parent, ok := reference.ReferencedContext.(RecordedSpanContext); ok {
+			span.context = RecordedSpanContext{TraceID: parent.TraceID, Baggage: parent.Baggage}
+			span.parentID = parent.SpanID
+			break
+		}
+	}
+	if span.context.TraceID == 0 {
+		span.context.TraceID = r.nextID()
+	}
+	span.context.SpanID = r.nextID()
+	for k, v := range options.Tags {
+		span.tags[k] = v
+	}
+
+	return span
+}
+
+func (r *Recorder) Inject(sc opentracing.SpanContext, format interface{}, carrier interface{}) error {
+	context, ok := sc.(RecordedSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return opentracing.ErrUnsupportedFormat
+	}
+	writer, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+
+	writer.Set(traceIDKey, strconv.FormatUint(context.TraceID, 10))
+	writer.Set(spanIDKey, strconv.FormatUint(context.SpanID, 10))
+	for k, v := range context.Baggage {
+		writer.Set(baggagePrefix+k, v)
+	}
+
+	return nil
+}
+
+func (r *Recorder) Extract(format interface{}, carrier interface{}) (opentracing.SpanContext, error) {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	reader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+
+	context := RecordedSpanContext{}
+	err := reader.ForeachKey(func(key, value string) error {
+		var err error
+		switch key = strings.ToLower(key); {
+		case key == traceIDKey:
+			context.TraceID, err = strconv.ParseUint(value, 10, 64)
+		case key == spanIDKey:
+			context.SpanID, err = strconv.ParseUint(value, 10, 64)
+		case strings.HasPrefix(key, baggagePrefix):
+			context = context.withBaggage(strings.TrimPrefix(key, baggagePrefix), value)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, opentracing.ErrSpanContextCorrupted
+	}
+	if context.TraceID == 0 || context.SpanID == 0 {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+
+	return context, nil
+}
+
+func (r *Recorder) finish(span *RecordedSpan) {
+	r.mu.Lock()
+	r.finished = append(r.finished, span)
+	r.mu.Unlock()
+
+	if r.onFinish != nil {
+		r.onFinish(span)
+	}
+}
+
+// RecordedSpan - a span of the Recorder
+type RecordedSpan struct {
+	tracer   *Recorder
+	parentID uint64
+	start    time.Time
+
+	mu        sync.Mutex
+	operation string
+	context   RecordedSpanContext
+	tags      map[string]interface{}
+	logs      []opentracing.LogRecord
+	end       time.Time
+}
+
+func (s *RecordedSpan) Operation() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.operation
+}
+
+func (s *RecordedSpan) TraceID() uint64 {
+	return s.SpanContext().TraceID
+}
+
+func (s *RecordedSpan) SpanID() uint64 {
+	return s.SpanContext().SpanID
+}
+
+// ParentID - the span id of the parent, 0 for the root of a trace
+func (s *RecordedSpan) ParentID() uint64 {
+	return s.parentID
+}
+
+func (s *RecordedSpan) SpanContext() RecordedSpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.context
+}
+
+func (s *RecordedSpan) Tags() map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tags := make(map[string]interface{}, len(s.tags))
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+func (s *RecordedSpan) Tag(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tags[key]
+}
+
+func (s *RecordedSpan) Logs() []opentracing.LogRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]opentracing.LogRecord{}, s.logs...)
+}
+
+func (s *RecordedSpan) Duration() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.end.Sub(s.start)
+}
+
+func (s *RecordedSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+func (s *RecordedSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	s.mu.Lock()
+	s.end = opts.FinishTime
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+	s.logs = append(s.logs, opts.LogRecords...)
+	s.mu.Unlock()
+
+	s.tracer.finish(s)
+}
+
+func (s *RecordedSpan) Context() opentracing.SpanContext {
+	return s.SpanContext()
+}
+
+func (s *RecordedSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operation = operationName
+	return s
+}
+
+func (s *RecordedSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tags[key] = value
+	return s
+}
+
+func (s *RecordedSpan) LogFields(fields ...log.Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, opentracing.LogRecord{Timestamp: time.Now(), Fields: fields})
+}
+
+func (s *RecordedSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := log.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(log.Error(err))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+func (s *RecordedSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.context = s.context.withBaggage(restrictedKey, value)
+	return s
+}
+
+func (s *RecordedSpan) BaggageItem(restrictedKey string) string {
+	return s.SpanContext().Baggage[restrictedKey]
+}
+
+func (s *RecordedSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+func (s *RecordedSpan) LogEvent(event string) {
+	s.LogFields(log.String("event", event))
+}
+
+func (s *RecordedSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(log.String("event", event), log.Object("payload", payload))
+}
+
+func (s *RecordedSpan) Log(data opentracing.LogData) {
+	s.LogFields(data.ToLogRecord().Fields...)
+}
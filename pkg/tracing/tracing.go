@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	tracingHelper "github.com/gtforge/global_services_common_go/gett-ops/opentracing"
+	"github.com/gtforge/gls"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/statuswriter"
+	"github.com/opentracing/opentracing-go"
+	otext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/sirupsen/logrus"
+	"net/http"
+)
+
+const ExporterLog = "log"
+
+// InitFromConfig - person.tracing.exporter: log records the spans in the service log, otherwise the spans go to the
+// opentracing global tracer set up by the platform (a noop tracer when there is none)
+func InitFromConfig() {
+	if config.Current().Person.Tracing.Exporter != ExporterLog {
+		return
+	}
+
+	opentracing.SetGlobalTracer(NewRecorder().OnFinish(logSpan))
+}
+
+func logSpan(span *RecordedSpan) {
+	// the recorder is only a log exporter here, the spans are not kept
+	span.tracer.Reset()
+	logrus.WithFields(logrus.Fields{
+		"trace_id":    span.TraceID(),
+		"span_id":     span.SpanID(),
+		"parent_id":   span.ParentID(),
+		"duration_ms": span.Duration().Seconds() * 1000,
+		"tags":        span.Tags(),
+	}).Debug("span ", span.Operation())
+}
+
+// Current - the span of the goroutine (the http request or the consumed message), a noop span when there is none
+func Current() opentracing.Span {
+	return tracingHelper.SpanFromGLS()
+}
+
+// StartSpan - a child of the goroutine span, which becomes the parent of the redis and sql spans until it is finished
+func StartSpan(operation string, opts ...opentracing.StartSpanOption) (opentracing.Span, func()) {
+	return start(operation, Current().Context(), opts...)
+}
+
+func start(operation string, parent opentracing.SpanContext, opts ...opentracing.StartSpanOption) (opentracing.Span, func()) {
+	previous := Current()
+	span := opentracing.GlobalTracer().StartSpan(operation, append([]opentracing.StartSpanOption{opentracing.ChildOf(parent)}, opts...)...)
+	tracingHelper.SpanToGLS(span)
+
+	return span, func() {
+		span.Finish()
+		if _, noop := previous.Tracer().(opentracing.NoopTracer); noop {
+			gls.Cleanup()
+			return
+		}
+		tracingHelper.SpanToGLS(previous)
+	}
+}
+
+// Error - mark the span as failed, nil errors are ignored
+func Error(span opentracing.Span, err error) {
+	if err == nil {
+		return
+	}
+	otext.Error.Set(span, true)
+	span.LogFields(otlog.String("event", "error"), otlog.Error(err))
+}
+
+// Go - run f in a goroutine whose spans are children of the span of the caller
+func Go(f func()) {
+	parent := Current()
+	go func() {
+		defer gls.Cleanup()
+		tracingHelper.SpanToGLS(parent)
+		f()
+	}()
+}
+
+// Middleware - a server span per request, continuing the trace of the caller headers. the span is the goroutine span
+// of the handler, so the spans started while serving the request are its children
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer gls.Cleanup()
+		tracingHelper.OpentracingMiddleware(opentracing.GlobalTracer())(keepFlusher(w, next)).ServeHTTP(w, req)
+	})
+}
+
+// keepFlusher - the tracing middleware wraps the writer without http.Flusher, the streaming handlers get it back from
+// the server writer. the wrapper only records the status, so flushing around it loses nothing
+func keepFlusher(w http.ResponseWriter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(traced http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(statuswriter.WithFlusher(traced, w), req)
+	})
+}
+
+// Do - send the request in a client span, with the trace context injected in its headers
+func Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	span, finish := StartSpan("http.client "+req.Method+" "+req.URL.Host, otext.SpanKindRPCClient)
+	defer finish()
+	otext.Component.Set(span, "http")
+	otext.HTTPMethod.Set(span, req.Method)
+	otext.HTTPUrl.Set(span, req.URL.String())
+	otext.PeerHostname.Set(span, req.URL.Hostname())
+
+	if err := span.Tracer().Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		logrus.Debug("unable to inject the trace context ", err)
+	}
+
+	response, err := client.Do(req.WithContext(opentracing.ContextWithSpan(req.Context(), span)))
+	if err != nil {
+		Error(span, err)
+		return nil, err
+	}
+	otext.HTTPStatusCode.Set(span, uint16(response.StatusCode))
+	if response.StatusCode >= http.StatusInternalServerError {
+		otext.Error.Set(span, true)
+	}
+
+	return response, nil
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"github.com/opentracing/opentracing-go"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "tracing test")
+}
+
+var _ = ginkgo.Describe("tracing", func() {
+
+	var recorder *Recorder
+
+	ginkgo.BeforeEach(func() {
+		recorder = NewRecorder()
+		opentracing.SetGlobalTracer(recorder)
+	})
+
+	ginkgo.AfterEach(func() {
+		opentracing.SetGlobalTracer(opentracing.NoopTracer{})
+	})
+
+	ginkgo.Context("validate that spans are children of the goroutine span until they finish", func() {
+
+		ginkgo.It("do", func() {
+			_, finishParent := StartSpan("parent")
+			_, finishChild := StartSpan("child")
+			finishChild()
+			_, finishSibling := StartSpan("sibling")
+			finishSibling()
+			finishParent()
+
+			parent := recorder.FinishedSpan("parent")
+			gomega.Expect(parent.ParentID()).To(gomega.BeZero())
+			gomega.Expect(recorder.FinishedSpan("child").ParentID()).To(gomega.Equal(parent.SpanID()))
+			gomega.Expect(recorder.FinishedSpan("sibling").ParentID()).To(gomega.Equal(parent.SpanID()))
+			gomega.Expect(recorder.FinishedSpan("sibling").TraceID()).To(gomega.Equal(parent.TraceID()))
+			gomega.Expect(Current().Tracer()).To(gomega.Equal(opentracing.NoopTracer{}))
+		})
+	})
+
+	ginkgo.Context("validate that goroutines started with Go keep the parent span", func() {
+
+		ginkgo.It("do", func() {
+			span, finish := StartSpan("batch")
+			var wg sync.WaitGroup
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				Go(func() {
+					defer wg.Done()
+					_, finishLookup := StartSpan("lookup")
+					finishLookup()
+				})
+			}
+			wg.Wait()
+			finish()
+
+			lookups := 0
+			for _, finished := range recorder.FinishedSpans() {
+				if finished.Operation() == "lookup" {
+					lookups++
+					gomega.Expect(finished.ParentID()).To(gomega.Equal(span.(*RecordedSpan).SpanID()))
+				}
+			}
+			gomega.Expect(lookups).To(gomega.Equal(3))
+		})
+	})
+
+	ginkgo.Context("validate that outbound requests carry the trace to the server", func() {
+
+		ginkgo.It("do", func() {
+			server := httptest.NewServer(Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, finish := StartSpan("handler")
+				finish()
+				w.WriteHeader(http.StatusOK)
+			})))
+			defer server.Close()
+
+			_, finish := StartSpan("caller")
+			req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v1/order_by_person/1", nil)
+			response, err := Do(http.DefaultClient, req)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			response.Body.Close()
+			finish()
+
+			caller := recorder.FinishedSpan("caller")
+			client := recorder.FinishedSpan("http.client GET " + req.URL.Host)
+			gomega.Expect(client.ParentID()).To(gomega.Equal(caller.SpanID()))
+			gomega.Expect(client.Tag("http.status_code")).To(gomega.Equal(uint16(http.StatusOK)))
+
+			// the server span finishes after the response is written
+			gomega.Eventually(func() *RecordedSpan {
+				return recorder.FinishedSpan("http.server GET " + req.URL.Host)
+			}).ShouldNot(gomega.BeNil())
+			serverSpan := recorder.FinishedSpan("http.server GET " + req.URL.Host)
+			gomega.Expect(serverSpan.TraceID()).To(gomega.Equal(caller.TraceID()))
+			gomega.Expect(serverSpan.ParentID()).To(gomega.Equal(client.SpanID()))
+			gomega.Expect(recorder.FinishedSpan("handler").ParentID()).To(gomega.Equal(serverSpan.SpanID()))
+		})
+	})
+
+	ginkgo.Context("validate that consumed messages continue the trace of the publisher", func() {
+
+		ginkgo.It("do", func() {
+			_, finishPublisher := StartSpan("publisher")
+			headers := InjectAMQP(nil)
+			finishPublisher()
+
+			_, finishConsumer := StartConsumerSpan("orders.update_rating", headers)
+			_, finishChild := StartSpan("person_store.get_persons")
+			finishChild()
+			finishConsumer()
+
+			publisher := recorder.FinishedSpan("publisher")
+			consumer := recorder.FinishedSpan("amqp.consume orders.update_rating")
+			gomega.Expect(consumer.TraceID()).To(gomega.Equal(publisher.TraceID()))
+			gomega.Expect(consumer.ParentID()).To(gomega.Equal(publisher.SpanID()))
+			gomega.Expect(consumer.Tag("message_bus.destination")).To(gomega.Equal("orders.update_rating"))
+			gomega.Expect(recorder.FinishedSpan("person_store.get_persons").ParentID()).To(gomega.Equal(consumer.SpanID()))
+		})
+	})
+
+	ginkgo.Context("validate that messages without a trace start a new one", func() {
+
+		ginkgo.It("do", func() {
+			_, finish := StartConsumerSpan("orders.update_rating", map[string]interface{}{"x-retry": int64(1)})
+			finish()
+
+			consumer := recorder.FinishedSpan("amqp.consume orders.update_rating")
+			gomega.Expect(consumer.ParentID()).To(gomega.BeZero())
+			gomega.Expect(consumer.TraceID()).NotTo(gomega.BeZero())
+		})
+	})
+})
@@ -0,0 +1,284 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/sirupsen/logrus"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+	SignatureHeader = "X-Webhook-Signature"
+
+	maxErrorLength = 500
+)
+
+// Publisher - queues an event for the subscribers of its type
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
+
+// Options - person.webhooks settings
+type Options struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	BatchSize    int
+	// MaxAttempts - a delivery is failed after this many attempts
+	MaxAttempts int64
+	// the wait before the nth retry is BaseBackoff * 2^(n-1), up to MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// DisableAfter - consecutive failed attempts that disable a subscription
+	DisableAfter int64
+	// AllowedHosts - the subscriber hosts that may resolve to private addresses
+	AllowedHosts []string
+}
+
+func OptionsFromConfig() Options {
+	webhooks := config.Current().Person.Webhooks
+	return Options{
+		PollInterval: webhooks.PollInterval,
+		Timeout:      webhooks.Timeout,
+		BatchSize:    webhooks.BatchSize,
+		MaxAttempts:  webhooks.MaxAttempts,
+		BaseBackoff:  webhooks.BaseBackoff,
+		MaxBackoff:   webhooks.MaxBackoff,
+		DisableAfter: webhooks.DisableAfter,
+		AllowedHosts: webhooks.AllowedHosts,
+	}
+}
+
+// Dispatcher - stores the deliveries of the published events and sends them from Run, so the events survive restarts
+// and the retries are shared by the replicas
+type Dispatcher struct {
+	repository Repository
+	options    Options
+	client     *http.Client
+	now        func() time.Time
+}
+
+func NewDispatcher(repository Repository, options Options) *Dispatcher {
+	// the subscribers are dialed directly, a proxy would dial the checked hosts on its own
+	transport := &http.Transport{
+		DialContext:         NewTargets(options.AllowedHosts).DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+
+	return &Dispatcher{
+		repository: repository,
+		options:    options,
+		client:     &http.Client{Timeout: options.Timeout, Transport: transport},
+		now:        time.Now,
+	}
+}
+
+func (d *Dispatcher) Publish(eventType string, data interface{}) {
+	subscriptions, err := d.repository.GetEnabledSubscriptions()
+	if err != nil {
+		logrus.Error("couldn't publish webhook event ", eventType, " ", err)
+		return
+	}
+
+	event := Event{ID: randomHex(16), Type: eventType, CreatedAt: d.now(), Data: data}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		logrus.Error("unable marshal webhook event ", err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(eventType) {
+			continue
+		}
+
+		delivery := Delivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      eventType,
+			Payload:        string(payload),
+			Status:         DeliveryPending,
+			NextAttemptAt:  event.CreatedAt,
+		}
+		if err := d.repository.CreateDelivery(&delivery); err != nil {
+			logrus.Error("couldn't queue webhook delivery for subscription ", subscription.ID, " ", err)
+		}
+	}
+}
+
+// Run - send the due deliveries every poll interval until stop is closed
+func (d *Dispatcher) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(d.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			d.DeliverDue()
+		}
+	}
+}
+
+// DeliverDue - attempt the due deliveries claimed by this replica, returns the number of attempts
+func (d *Dispatcher) DeliverDue() int {
+	now := d.now()
+	deliveries, err := d.repository.GetDueDeliveries(now, d.options.BatchSize)
+	if err != nil {
+		return 0
+	}
+
+	attempts := 0
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		// the claim holds the delivery while it is attempted, a crashed attempt is retried once it runs out. it starts
+		// from the time of the claim, the earlier attempts of the batch took part of a claim from the poll time
+		claimed, err := d.repository.ClaimDelivery(delivery, d.now().Add(d.options.Timeout*2))
+		if err != nil {
+			logrus.Error("couldn't claim webhook delivery ", delivery.ID, " ", err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		d.attempt(delivery)
+		attempts++
+	}
+
+	return attempts
+}
+
+func (d *Dispatcher) attempt(delivery *Delivery) {
+	subscription, err := d.repository.GetSubscription(delivery.SubscriptionID)
+	if err != nil || !subscription.Enabled {
+		delivery.Status = DeliveryFailed
+		delivery.LastError = "the subscription was deleted or disabled"
+		d.saveDelivery(delivery)
+		return
+	}
+
+	delivery.Attempts++
+	statusCode, err := d.send(subscription, delivery)
+	delivery.ResponseStatus = statusCode
+
+	if err == nil {
+		now := d.now()
+		delivery.Status = DeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		d.saveDelivery(delivery)
+
+		if subscription.ConsecutiveFailures > 0 {
+			subscription.ConsecutiveFailures = 0
+			d.saveSubscription(subscription)
+		}
+		return
+	}
+
+	delivery.LastError = truncate(err.Error(), maxErrorLength)
+	if delivery.Attempts >= d.options.MaxAttempts {
+		delivery.Status = DeliveryFailed
+	} else {
+		delivery.NextAttemptAt = d.now().Add(d.backoff(delivery.Attempts))
+	}
+	d.saveDelivery(delivery)
+
+	subscription.ConsecutiveFailures++
+	if subscription.ConsecutiveFailures >= d.options.DisableAfter {
+		now := d.now()
+		subscription.Enabled = false
+		subscription.DisabledAt = &now
+		logrus.WithFields(logrus.Fields{"subscription_id": subscription.ID, "url": subscription.URL}).Warn("webhook subscription disabled after repeated failures")
+	}
+	d.saveSubscription(subscription)
+}
+
+func (d *Dispatcher) send(subscription *Subscription, delivery *Delivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.EventID)
+	req.Header.Set(SignatureHeader, "t="+timestamp+",v1="+Sign([]byte(subscription.Secret), timestamp, []byte(delivery.Payload)))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("subscriber responded %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) backoff(attempts int64) time.Duration {
+	backoff := d.options.BaseBackoff
+	for i := int64(1); i < attempts && backoff < d.options.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > d.options.MaxBackoff {
+		return d.options.MaxBackoff
+	}
+
+	return backoff
+}
+
+func (d *Dispatcher) saveDelivery(delivery *Delivery) {
+	if err := d.repository.SaveDelivery(delivery); err != nil {
+		logrus.Error("couldn't save webhook delivery ", delivery.ID, " ", err)
+	}
+}
+
+func (d *Dispatcher) saveSubscription(subscription *Subscription) {
+	if err := d.repository.SaveSubscription(subscription); err != nil {
+		logrus.Error("couldn't save webhook subscription ", subscription.ID, " ", err)
+	}
+}
+
+// Sign - the hex HMAC-SHA256 of "<timestamp>.<body>", subscribers verify the v1 part of X-Webhook-Signature with it
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomHex(n int) string {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		logrus.Error("unable to read random bytes ", err)
+	}
+
+	return hex.EncodeToString(bytes)
+}
+
+func truncate(value string, length int) string {
+	if len(value) <= length {
+		return value
+	}
+
+	return value[:length]
+}
@@ -0,0 +1,252 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhooks(t *testing.T) {
+	gomega.RegisterFailHandler(ginkgo.Fail)
+	ginkgo.RunSpecs(t, "webhooks test")
+}
+
+type memoryRepository struct {
+	subscriptions map[int64]*Subscription
+	deliveries    []*Delivery
+	claims        []time.Time
+}
+
+func (r *memoryRepository) CreateSubscription(subscription *Subscription) error {
+	subscription.ID = int64(len(r.subscriptions) + 1)
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *memoryRepository) GetSubscriptions() ([]Subscription, error) {
+	subscriptions := make([]Subscription, 0)
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, nil
+}
+
+func (r *memoryRepository) GetEnabledSubscriptions() ([]Subscription, error) {
+	subscriptions := make([]Subscription, 0)
+	for _, subscription := range r.subscriptions {
+		if subscription.Enabled {
+			subscriptions = append(subscriptions, *subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (r *memoryRepository) GetSubscription(id int64) (*Subscription, error) {
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	copied := *subscription
+	return &copied, nil
+}
+
+func (r *memoryRepository) SaveSubscription(subscription *Subscription) error {
+	copied := *subscription
+	r.subscriptions[subscription.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepository) DeleteSubscription(id int64) error {
+	delete(r.subscriptions, id)
+	return nil
+}
+
+func (r *memoryRepository) CreateDelivery(delivery *Delivery) error {
+	delivery.ID = int64(len(r.deliveries) + 1)
+	copied := *delivery
+	r.deliveries = append(r.deliveries, &copied)
+	return nil
+}
+
+func (r *memoryRepository) GetDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *memoryRepository) ClaimDelivery(delivery *Delivery, until time.Time) (bool, error) {
+	stored := r.deliveries[delivery.ID-1]
+	if !stored.NextAttemptAt.Equal(delivery.NextAttemptAt) {
+		return false, nil
+	}
+	stored.NextAttemptAt = until
+	r.claims = append(r.claims, until)
+	return true, nil
+}
+
+func (r *memoryRepository) SaveDelivery(delivery *Delivery) error {
+	copied := *delivery
+	r.deliveries[delivery.ID-1] = &copied
+	return nil
+}
+
+func (r *memoryRepository) GetDeliveries(subscriptionID int64, limit int) ([]Delivery, error) {
+	return nil, nil
+}
+
+var _ = ginkgo.Describe("webhook dispatcher", func() {
+
+	var (
+		now        time.Time
+		repository *memoryRepository
+		dispatcher *Dispatcher
+		server     *httptest.Server
+		status     int
+		handling   time.Duration
+		received   []*http.Request
+		bodies     []string
+	)
+
+	ginkgo.BeforeEach(func() {
+		now = time.Unix(1600000000, 0)
+		status = http.StatusOK
+		handling = 0
+		received, bodies = nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ := ioutil.ReadAll(req.Body)
+			received, bodies = append(received, req), append(bodies, string(body))
+			now = now.Add(handling)
+			w.WriteHeader(status)
+		}))
+
+		repository = &memoryRepository{subscriptions: map[int64]*Subscription{}}
+		repository.CreateSubscription(&Subscription{URL: server.URL, Secret: "secret", Events: []string{EventPersonUpdated}, Enabled: true})
+		repository.CreateSubscription(&Subscription{URL: server.URL, Secret: "other", Events: []string{EventPersonDeleted}, Enabled: true})
+
+		dispatcher = NewDispatcher(repository, Options{
+			PollInterval: time.Second, Timeout: time.Second, BatchSize: 10,
+			MaxAttempts: 3, BaseBackoff: 10 * time.Second, MaxBackoff: time.Minute, DisableAfter: 3,
+			AllowedHosts: []string{"127.0.0.1"},
+		})
+		dispatcher.now = func() time.Time { return now }
+	})
+
+	ginkgo.AfterEach(func() {
+		server.Close()
+	})
+
+	ginkgo.Context("validate that events are signed and sent to their subscribers only", func() {
+
+		ginkgo.It("do", func() {
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 1})
+			gomega.Expect(dispatcher.DeliverDue()).To(gomega.Equal(1))
+
+			gomega.Expect(received).To(gomega.HaveLen(1))
+			gomega.Expect(received[0].Header.Get(EventHeader)).To(gomega.Equal(EventPersonUpdated))
+			gomega.Expect(bodies[0]).To(gomega.ContainSubstring(`"data":{"id":1}`))
+			signature := "t=1600000000,v1=" + Sign([]byte("secret"), "1600000000", []byte(bodies[0]))
+			gomega.Expect(received[0].Header.Get(SignatureHeader)).To(gomega.Equal(signature))
+			gomega.Expect(repository.deliveries[0].Status).To(gomega.Equal(DeliverySucceeded))
+		})
+	})
+
+	ginkgo.Context("validate that failed deliveries are retried with backoff and disable the subscription", func() {
+
+		ginkgo.It("do", func() {
+			status = http.StatusInternalServerError
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 1})
+
+			dispatcher.DeliverDue()
+			gomega.Expect(repository.deliveries[0].Attempts).To(gomega.Equal(int64(1)))
+			gomega.Expect(repository.deliveries[0].NextAttemptAt).To(gomega.Equal(now.Add(10 * time.Second)))
+			gomega.Expect(dispatcher.DeliverDue()).To(gomega.Equal(0))
+
+			now = now.Add(10 * time.Second)
+			dispatcher.DeliverDue()
+			gomega.Expect(repository.deliveries[0].NextAttemptAt).To(gomega.Equal(now.Add(20 * time.Second)))
+
+			now = now.Add(20 * time.Second)
+			dispatcher.DeliverDue()
+			gomega.Expect(repository.deliveries[0].Status).To(gomega.Equal(DeliveryFailed))
+			gomega.Expect(repository.deliveries[0].LastError).To(gomega.ContainSubstring("500"))
+			gomega.Expect(repository.subscriptions[1].Enabled).To(gomega.BeFalse())
+			gomega.Expect(strings.Count(strings.Join(bodies, "\n"), EventPersonUpdated)).To(gomega.Equal(3))
+		})
+	})
+
+	ginkgo.Context("validate that the memory repository keeps the subscriptions and deliveries of the dispatcher", func() {
+
+		ginkgo.It("do", func() {
+			memory := NewMemoryRepository()
+			memory.CreateSubscription(&Subscription{URL: server.URL, Secret: "secret", Events: []string{EventPersonUpdated}, Enabled: true})
+			dispatcher = NewDispatcher(memory, dispatcher.options)
+			dispatcher.now = func() time.Time { return now }
+
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 1})
+			dispatcher.Publish(EventPersonDeleted, map[string]interface{}{"id": 1})
+			gomega.Expect(dispatcher.DeliverDue()).To(gomega.Equal(1))
+			gomega.Expect(dispatcher.DeliverDue()).To(gomega.Equal(0))
+
+			deliveries, err := memory.GetDeliveries(1, 10)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(deliveries).To(gomega.HaveLen(1))
+			gomega.Expect(deliveries[0].Status).To(gomega.Equal(DeliverySucceeded))
+
+			subscription, err := memory.GetSubscription(1)
+			gomega.Expect(err).NotTo(gomega.HaveOccurred())
+			gomega.Expect(subscription.EventTypes).To(gomega.Equal(EventPersonUpdated))
+			_, err = memory.GetSubscription(2)
+			gomega.Expect(err).To(gomega.HaveOccurred())
+		})
+	})
+
+	ginkgo.Context("validate that every delivery is claimed from the time it is attempted", func() {
+
+		ginkgo.It("do", func() {
+			handling = 5 * time.Second
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 1})
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 2})
+			start := now
+
+			gomega.Expect(dispatcher.DeliverDue()).To(gomega.Equal(2))
+			gomega.Expect(repository.claims).To(gomega.Equal([]time.Time{start.Add(2 * time.Second), start.Add(7 * time.Second)}))
+		})
+	})
+
+	ginkgo.Context("validate that the subscribers on private addresses are refused unless their host is allowed", func() {
+
+		ginkgo.It("do", func() {
+			targets := NewTargets([]string{"Orders.Internal"})
+			for _, private := range []string{"http://127.0.0.1:8080", "http://10.1.2.3", "https://192.168.1.1/hook",
+				"http://172.20.0.1", "http://169.254.169.254/latest", "http://[::1]/hook", "http://0.0.0.0"} {
+				gomega.Expect(targets.Check(context.Background(), private)).To(gomega.Equal(ErrPrivateTarget), private)
+			}
+			gomega.Expect(targets.Check(context.Background(), "https://93.184.216.34/hook")).To(gomega.Succeed())
+			gomega.Expect(targets.Check(context.Background(), "http://orders.internal/hook")).To(gomega.Succeed())
+
+			request := SubscriptionRequest{URL: server.URL, EventTypes: []string{EventPersonUpdated}}
+			gomega.Expect(request.validate(context.Background(), targets)).To(gomega.Equal(ErrPrivateTarget))
+			gomega.Expect(request.validate(context.Background(), NewTargets([]string{"127.0.0.1"}))).To(gomega.Succeed())
+
+			// a subscription stored before its host resolved to a private address isn't called either
+			dispatcher = NewDispatcher(repository, Options{Timeout: time.Second, BatchSize: 10, MaxAttempts: 3,
+				BaseBackoff: time.Second, MaxBackoff: time.Second, DisableAfter: 3})
+			dispatcher.now = func() time.Time { return now }
+			dispatcher.Publish(EventPersonUpdated, map[string]interface{}{"id": 1})
+			dispatcher.DeliverDue()
+			gomega.Expect(received).To(gomega.BeEmpty())
+			gomega.Expect(repository.deliveries[0].LastError).To(gomega.ContainSubstring(ErrPrivateTarget.Error()))
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"github.com/gorilla/mux"
+	"github.com/gtforge/go-skeleton-draft/core"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/auth"
+	"github.com/gtforge/gorm"
+	"github.com/sirupsen/logrus"
+	"github.com/unrolled/render"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDeliveries = 50
+	maxDeliveries     = 500
+)
+
+type handler struct {
+	repository Repository
+	targets    Targets
+	render     *render.Render
+}
+
+func NewHandler(repository Repository, targets Targets) *handler {
+	return &handler{
+		repository: repository,
+		targets:    targets,
+		render:     render.New(),
+	}
+}
+
+// RegisterRoutes - managing subscriptions needs the admin role, the secret is only returned when it is created
+func (h handler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/webhooks", auth.Require(auth.RoleAdmin, h.CreateSubscription)).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks", auth.Require(auth.RoleAdmin, h.GetSubscriptions)).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id:[0-9]+}", auth.Require(auth.RoleAdmin, h.GetSubscription)).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id:[0-9]+}", auth.Require(auth.RoleAdmin, h.UpdateSubscription)).Methods(http.MethodPut)
+	router.HandleFunc("/webhooks/{id:[0-9]+}", auth.Require(auth.RoleAdmin, h.DeleteSubscription)).Methods(http.MethodDelete)
+	router.HandleFunc("/webhooks/{id:[0-9]+}/deliveries", auth.Require(auth.RoleAdmin, h.GetDeliveries)).Methods(http.MethodGet)
+}
+
+func (h handler) CreateSubscription(w http.ResponseWriter, req *http.Request) {
+	defer req.Body.Close()
+	request := SubscriptionRequest{}
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := request.validate(req.Context(), h.targets); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subscription := Subscription{
+		URL:     request.URL,
+		Secret:  request.Secret,
+		Events:  request.EventTypes,
+		Enabled: request.Enabled == nil || *request.Enabled,
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = randomHex(32)
+	}
+
+	if err := h.repository.CreateSubscription(&subscription); err != nil {
+		logrus.Error("couldn't create webhook subscription ", err)
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	auth.Logger(req.Context()).WithFields(logrus.Fields{"subscription_id": subscription.ID, "url": subscription.URL}).Info("webhook subscription created")
+
+	h.render.JSON(w, http.StatusCreated, subscription)
+}
+
+func (h handler) GetSubscriptions(w http.ResponseWriter, req *http.Request) {
+	subscriptions, err := h.repository.GetSubscriptions()
+	if err != nil {
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+	h.render.JSON(w, http.StatusOK, subscriptions)
+}
+
+func (h handler) GetSubscription(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+
+	subscription.Secret = ""
+	h.render.JSON(w, http.StatusOK, subscription)
+}
+
+// UpdateSubscription - replaces the url and event types, enabling a subscription clears its failures
+func (h handler) UpdateSubscription(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+
+	defer req.Body.Close()
+	request := SubscriptionRequest{}
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := request.validate(req.Context(), h.targets); err != nil {
+		h.renderError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	subscription.URL = request.URL
+	subscription.Events = request.EventTypes
+	if request.Secret != "" {
+		subscription.Secret = request.Secret
+	}
+	if request.Enabled != nil {
+		if *request.Enabled && !subscription.Enabled {
+			subscription.ConsecutiveFailures = 0
+			subscription.DisabledAt = nil
+		}
+		if !*request.Enabled && subscription.Enabled {
+			now := time.Now()
+			subscription.DisabledAt = &now
+		}
+		subscription.Enabled = *request.Enabled
+	}
+
+	if err := h.repository.SaveSubscription(subscription); err != nil {
+		logrus.Error("couldn't update webhook subscription ", err)
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	auth.Logger(req.Context()).WithFields(logrus.Fields{"subscription_id": subscription.ID}).Info("webhook subscription updated")
+
+	subscription.Secret = ""
+	h.render.JSON(w, http.StatusOK, subscription)
+}
+
+func (h handler) DeleteSubscription(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+
+	if err := h.repository.DeleteSubscription(subscription.ID); err != nil {
+		logrus.Error("couldn't delete webhook subscription ", err)
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	auth.Logger(req.Context()).WithFields(logrus.Fields{"subscription_id": subscription.ID}).Info("webhook subscription deleted")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries - the delivery log of a subscription, latest first
+func (h handler) GetDeliveries(w http.ResponseWriter, req *http.Request) {
+	subscription, ok := h.subscription(w, req)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveries
+	if stringLimit := req.URL.Query().Get("limit"); stringLimit != "" {
+		parsed, err := strconv.Atoi(stringLimit)
+		if err != nil || parsed < 1 || parsed > maxDeliveries {
+			http.Error(w, "limit must be a number between 1 and "+strconv.Itoa(maxDeliveries), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.repository.GetDeliveries(subscription.ID, limit)
+	if err != nil {
+		h.renderError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.render.JSON(w, http.StatusOK, deliveries)
+}
+
+func (h handler) subscription(w http.ResponseWriter, req *http.Request) (*Subscription, bool) {
+	id, _ := strconv.ParseInt(mux.Vars(req)["id"], 10, 64)
+
+	subscription, err := h.repository.GetSubscription(id)
+	if gorm.IsRecordNotFoundError(err) {
+		h.renderError(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	if err != nil {
+		h.renderError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return subscription, true
+}
+
+func (h handler) renderError(w http.ResponseWriter, code int, err error) {
+	h.render.JSON(w, code, skeleton.NewAPIError(http.StatusText(code), err))
+}
@@ -0,0 +1,306 @@
+package webhooks
+
+import (
+	"github.com/gtforge/gorm"
+	"github.com/sirupsen/logrus"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Repository interface {
+	CreateSubscription(subscription *Subscription) error
+	GetSubscriptions() ([]Subscription, error)
+	GetEnabledSubscriptions() ([]Subscription, error)
+	GetSubscription(id int64) (*Subscription, error)
+	SaveSubscription(subscription *Subscription) error
+	DeleteSubscription(id int64) error
+	CreateDelivery(delivery *Delivery) error
+	GetDueDeliveries(now time.Time, limit int) ([]Delivery, error)
+	ClaimDelivery(delivery *Delivery, until time.Time) (bool, error)
+	SaveDelivery(delivery *Delivery) error
+	GetDeliveries(subscriptionID int64, limit int) ([]Delivery, error)
+}
+
+type Repo struct {
+	db *gorm.DB
+}
+
+func NewRepo(db *gorm.DB) Repository {
+	return &Repo{
+		db: db,
+	}
+}
+
+func (r *Repo) CreateSubscription(subscription *Subscription) error {
+	return r.db.Create(subscription).Error
+}
+
+func (r *Repo) GetSubscriptions() ([]Subscription, error) {
+	subscriptions := make([]Subscription, 0)
+
+	if err := r.db.Order("id").Find(&subscriptions).Error; err != nil {
+		logrus.Error("can't get webhook subscriptions ", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Repo) GetEnabledSubscriptions() ([]Subscription, error) {
+	subscriptions := make([]Subscription, 0)
+
+	if err := r.db.Where("enabled = ?", true).Find(&subscriptions).Error; err != nil {
+		logrus.Error("can't get enabled webhook subscriptions ", err)
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *Repo) GetSubscription(id int64) (*Subscription, error) {
+	subscription := Subscription{}
+
+	if err := r.db.First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &subscription, nil
+}
+
+func (r *Repo) SaveSubscription(subscription *Subscription) error {
+	return r.db.Save(subscription).Error
+}
+
+func (r *Repo) DeleteSubscription(id int64) error {
+	subscription, err := r.GetSubscription(id)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Delete(subscription).Error
+}
+
+func (r *Repo) CreateDelivery(delivery *Delivery) error {
+	return r.db.Create(delivery).Error
+}
+
+func (r *Repo) GetDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", DeliveryPending, now).Order("next_attempt_at").Limit(limit).Find(&deliveries).Error; err != nil {
+		logrus.Error("can't get due webhook deliveries ", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery - push the next attempt of the delivery to until, unless another replica claimed it first
+func (r *Repo) ClaimDelivery(delivery *Delivery, until time.Time) (bool, error) {
+	result := r.db.Model(&Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", delivery.ID, DeliveryPending, delivery.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *Repo) SaveDelivery(delivery *Delivery) error {
+	return r.db.Save(delivery).Error
+}
+
+func (r *Repo) GetDeliveries(subscriptionID int64, limit int) ([]Delivery, error) {
+	deliveries := make([]Delivery, 0)
+
+	if err := r.db.Where("subscription_id = ?", subscriptionID).Order("created_at desc").Limit(limit).Find(&deliveries).Error; err != nil {
+		logrus.Error("can't get webhook deliveries ", err)
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// MemoryRepository - the subscriptions and deliveries kept by the process, the deliveries are lost on restart
+type MemoryRepository struct {
+	mu                 sync.Mutex
+	subscriptions      map[int64]Subscription
+	deliveries         map[int64]Delivery
+	lastSubscriptionID int64
+	lastDeliveryID     int64
+}
+
+func NewMemoryRepository() Repository {
+	return &MemoryRepository{
+		subscriptions: map[int64]Subscription{},
+		deliveries:    map[int64]Delivery{},
+	}
+}
+
+func (r *MemoryRepository) CreateSubscription(subscription *Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastSubscriptionID++
+	subscription.ID = r.lastSubscriptionID
+	subscription.BeforeSave()
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+	r.subscriptions[subscription.ID] = copySubscription(*subscription)
+
+	return nil
+}
+
+func (r *MemoryRepository) GetSubscriptions() ([]Subscription, error) {
+	return r.findSubscriptions(func(Subscription) bool { return true }), nil
+}
+
+func (r *MemoryRepository) GetEnabledSubscriptions() ([]Subscription, error) {
+	return r.findSubscriptions(func(s Subscription) bool { return s.Enabled }), nil
+}
+
+func (r *MemoryRepository) findSubscriptions(filter func(Subscription) bool) []Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptions := make([]Subscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		if filter(subscription) {
+			subscriptions = append(subscriptions, copySubscription(subscription))
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].ID < subscriptions[j].ID })
+
+	return subscriptions
+}
+
+func (r *MemoryRepository) GetSubscription(id int64) (*Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	subscription = copySubscription(subscription)
+
+	return &subscription, nil
+}
+
+// SaveSubscription - like gorm Save, a subscription without an id is created
+func (r *MemoryRepository) SaveSubscription(subscription *Subscription) error {
+	if subscription.ID == 0 {
+		return r.CreateSubscription(subscription)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscription.BeforeSave()
+	subscription.UpdatedAt = time.Now()
+	r.subscriptions[subscription.ID] = copySubscription(*subscription)
+
+	return nil
+}
+
+func (r *MemoryRepository) DeleteSubscription(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.subscriptions, id)
+
+	return nil
+}
+
+func (r *MemoryRepository) CreateDelivery(delivery *Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastDeliveryID++
+	delivery.ID = r.lastDeliveryID
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = delivery.CreatedAt
+	r.deliveries[delivery.ID] = *delivery
+
+	return nil
+}
+
+func (r *MemoryRepository) GetDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := make([]Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.Status == DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].NextAttemptAt.Before(deliveries[j].NextAttemptAt) })
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// ClaimDelivery - push the next attempt of the delivery to until, unless it was claimed since it was read
+func (r *MemoryRepository) ClaimDelivery(delivery *Delivery, until time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok || stored.Status != DeliveryPending || !stored.NextAttemptAt.Equal(delivery.NextAttemptAt) {
+		return false, nil
+	}
+	stored.NextAttemptAt = until
+	stored.UpdatedAt = time.Now()
+	r.deliveries[delivery.ID] = stored
+
+	return true, nil
+}
+
+func (r *MemoryRepository) SaveDelivery(delivery *Delivery) error {
+	if delivery.ID == 0 {
+		return r.CreateDelivery(delivery)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery.UpdatedAt = time.Now()
+	r.deliveries[delivery.ID] = *delivery
+
+	return nil
+}
+
+func (r *MemoryRepository) GetDeliveries(subscriptionID int64, limit int) ([]Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := make([]Delivery, 0)
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// copySubscription - the subscription without sharing its events with the caller
+func copySubscription(subscription Subscription) Subscription {
+	subscription.Events = append([]string{}, subscription.Events...)
+	return subscription
+}
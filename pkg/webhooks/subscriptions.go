@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	EventPersonCreated = "person.created"
+	EventPersonUpdated = "person.updated"
+	EventPersonDeleted = "person.deleted"
+	EventRatingChanged = "rating.changed"
+
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+var (
+	EventTypes = []string{EventPersonCreated, EventPersonUpdated, EventPersonDeleted, EventRatingChanged}
+
+	ErrInvalidURL       = errors.New("url must be an absolute http or https url")
+	ErrInvalidEventType = errors.New("event_types must be a non empty list of person.created, person.updated, person.deleted, rating.changed")
+)
+
+type Subscription struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+	// EventTypes - the comma separated column behind Events
+	EventTypes          string     `json:"-"`
+	Events              []string   `json:"event_types" gorm:"-"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+func (s *Subscription) BeforeSave() error {
+	s.EventTypes = strings.Join(s.Events, ",")
+	return nil
+}
+
+func (s *Subscription) AfterFind() error {
+	s.Events = strings.Split(s.EventTypes, ",")
+	return nil
+}
+
+// Subscribes - whether the subscription receives the events of the type
+func (s Subscription) Subscribes(eventType string) bool {
+	for _, subscribed := range s.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Delivery - an event sent to a subscription, pending until it succeeds or runs out of attempts
+type Delivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        string     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int64      `json:"attempts"`
+	ResponseStatus int        `json:"response_status"`
+	LastError      string     `json:"last_error"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Event - the JSON body posted to the subscribers
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+type SubscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	// Secret - signs the payloads, generated when empty
+	Secret  string `json:"secret"`
+	Enabled *bool  `json:"enabled"`
+}
+
+func (r SubscriptionRequest) validate(ctx context.Context, targets Targets) error {
+	parsed, err := url.Parse(r.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidURL
+	}
+	if err := targets.Check(ctx, r.URL); err != nil {
+		return err
+	}
+
+	if len(r.EventTypes) == 0 {
+		return ErrInvalidEventType
+	}
+	for _, eventType := range r.EventTypes {
+		known := false
+		for _, candidate := range EventTypes {
+			known = known || candidate == eventType
+		}
+		if !known {
+			return ErrInvalidEventType
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrPrivateTarget = errors.New("url must not point to a private, loopback or link-local address")
+
+// privateNetworks - the ranges net.IP has no predicate for in go 1.12
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"fc00::/7",
+)
+
+// Targets - the addresses the subscriber urls may point to. the private, loopback and link-local addresses would let
+// an admin reach the internal services through the dispatcher, so they are refused unless the host is allowed
+type Targets struct {
+	allowedHosts map[string]bool
+	resolver     *net.Resolver
+	dialer       *net.Dialer
+}
+
+func NewTargets(allowedHosts []string) Targets {
+	allowed := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		allowed[strings.ToLower(host)] = true
+	}
+
+	return Targets{
+		allowedHosts: allowed,
+		resolver:     net.DefaultResolver,
+		dialer:       &net.Dialer{Timeout: 30 * time.Second},
+	}
+}
+
+// Check - ErrPrivateTarget when the host of the url resolves to an address that isn't public
+func (t Targets) Check(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidURL
+	}
+
+	_, err = t.resolve(ctx, parsed.Hostname())
+	return err
+}
+
+// DialContext - dial the checked address, a host resolving to a public address when subscribed and to a private one
+// when called is refused as well
+func (t Targets) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	ip, err := t.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if ip == nil {
+		return t.dialer.DialContext(ctx, network, address)
+	}
+
+	return t.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+}
+
+// resolve - the first address of the host, nil for an allowed host
+func (t Targets) resolve(ctx context.Context, host string) (net.IP, error) {
+	if t.allowedHosts[strings.ToLower(host)] {
+		return nil, nil
+	}
+
+	addresses, err := t.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, address := range addresses {
+		if !public(address.IP) {
+			return nil, ErrPrivateTarget
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, ErrInvalidURL
+	}
+
+	return addresses[0].IP, nil
+}
+
+func public(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range privateNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+
+	return networks
+}
@@ -1,50 +1,100 @@
 package workers
 
 import (
-	"github.com/gtforge/global_services_common_go/gett-storages"
+	"context"
 	"github.com/gtforge/global_services_common_go/gett-workers"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/config"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/lifecycle"
+	"github.com/gtforge/go-skeleton-draft/structure/pkg/metrics"
 	"github.com/gtforge/go-skeleton-draft/structure/pkg/person"
 	"github.com/sirupsen/logrus"
+	"time"
 )
 import "github.com/gtforge/go-workers"
 
 var CacheWorker = InMemoryWorker{}
 
+const workerName = "InMemoryWorker"
+
 type InMemoryWorker struct {
 	gettWorkers.BaseWorker
 	personService person.Service
-	cache         person.Cache
+	cache         person.InMemoryProvider
 }
 
-func GetWorker() gettWorkers.Worker {
+func GetWorker(personService person.Service, cache person.InMemoryProvider) gettWorkers.Worker {
 	return &InMemoryWorker{
 		BaseWorker:    gettWorkers.BaseWorker{},
-		personService: person.NewPersonService(person.NewRepo(gettStorages.DB)),
-		cache:         *person.CacheInstance,
+		personService: personService,
+		cache:         cache,
+	}
+}
+
+// Stop - stop fetching jobs and wait for the ones in process
+func Stop(ctx context.Context) error {
+	return lifecycle.WaitFor(ctx, workers.Quit)
+}
+
+// RunLocally - perform the worker right away and then every interval until stop is closed, for the runs without the
+// redis of the jobs manager. the cron line isn't interpreted
+func RunLocally(worker gettWorkers.Worker, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		worker.Perform(&workers.Msg{})
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
 func (imw InMemoryWorker) GetWorkerOptions() gettWorkers.WorkerOptions {
+	options := config.Current().Person.Worker
 	return gettWorkers.WorkerOptions{
-		Name:        "InMemoryWorker",
+		Name:        workerName,
 		Params:      map[string]interface{}{},
-		CronLine:    "*/1 * * * *",
+		CronLine:    options.Cron,
 		Unique:      true,
-		UniqueKey:   "InMemoryWorker",
-		Concurrency: 200,
+		UniqueKey:   workerName,
+		Concurrency: options.Concurrency,
 	}
 }
 
 func (imw InMemoryWorker) Perform(params *workers.Msg) {
 	logrus.Info("start to perform... ")
-	ratings, err := imw.personService.AsyncRun()
-	if err != nil {
-		logrus.Error("fail in perform ")
-		return
+	start := time.Now()
+	defer func() {
+		metrics.WorkerRunDuration.Observe(time.Since(start).Seconds(), workerName)
+	}()
+
+	ctx := context.Background()
+	failed := false
+	all := make([]person.Rating, 0)
+	for _, market := range person.Markets() {
+		ratings, err := imw.personService.ForMarket(market).AsyncRun(ctx)
+		if err != nil {
+			logrus.WithField("market", market).Error("fail in perform ")
+			failed = true
+			continue
+		}
+		err = imw.cache.ForMarket(market).SetInMemoryRatings(ctx, ratings)
+		if err != nil {
+			logrus.WithField("market", market).Error("unable to set the file ")
+			failed = true
+		}
+		all = append(all, ratings...)
 	}
-	err = imw.cache.SetInMemoryRatings(ratings)
+
+	err := imw.cache.SetInMemoryRatings(ctx, all)
 	if err != nil {
 		logrus.Error("unable to set the file ")
 		return
 	}
+
+	if !failed {
+		metrics.WorkerLastSuccess.Set(float64(time.Now().Unix()), workerName)
+	}
 }